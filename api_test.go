@@ -0,0 +1,189 @@
+package errcode_test
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var updateAPI = flag.Bool("update-api", false, "update the API surface report in testdata/api.txt")
+
+// apiPackages are the packages of this module whose exported API is tracked.
+var apiPackages = []string{".", "errcodetest", "eventing", "httpclient", "httperr", "interop", "jsonrpc", "oautherr", "openapi", "sqlerr"}
+
+// TestAPI compares the exported API to the report in testdata/api.txt.
+// This catches accidental breaking changes.
+// After an intentional change run: go test -run TestAPI -update-api
+func TestAPI(t *testing.T) {
+	var report strings.Builder
+	for _, dir := range apiPackages {
+		lines, err := apiSurface(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report.WriteString("# package " + dir + "\n")
+		for _, line := range lines {
+			report.WriteString(line + "\n")
+		}
+	}
+	golden := filepath.Join("testdata", "api.txt")
+	if *updateAPI {
+		if err := os.WriteFile(golden, []byte(report.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(expected) != report.String() {
+		expectedLines := strings.Split(string(expected), "\n")
+		gotLines := strings.Split(report.String(), "\n")
+		t.Errorf("the exported API changed, if this is intended run: go test -run TestAPI -update-api\n"+
+			"removed:\n%s\nadded:\n%s",
+			strings.Join(difference(expectedLines, gotLines), "\n"),
+			strings.Join(difference(gotLines, expectedLines), "\n"))
+	}
+}
+
+func difference(lines []string, others []string) []string {
+	set := make(map[string]bool, len(others))
+	for _, other := range others {
+		set[other] = true
+	}
+	var diff []string
+	for _, line := range lines {
+		if !set[line] {
+			diff = append(diff, line)
+		}
+	}
+	return diff
+}
+
+// apiSurface gives one line for each exported declaration of the package in dir.
+// Deprecated declarations are marked.
+func apiSurface(dir string) ([]string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	add := func(node interface{}, doc *ast.CommentGroup) {
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, token.NewFileSet(), node); err != nil {
+			panic(err)
+		}
+		line := strings.Join(strings.Fields(buf.String()), " ")
+		if doc != nil && strings.Contains(doc.Text(), "Deprecated:") {
+			line += " // Deprecated"
+		}
+		lines = append(lines, line)
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					if !decl.Name.IsExported() || (decl.Recv != nil && !ast.IsExported(receiverName(decl.Recv))) {
+						continue
+					}
+					var recv *ast.FieldList
+					if decl.Recv != nil {
+						// receiver names are not part of the API
+						recv = &ast.FieldList{List: []*ast.Field{{Type: decl.Recv.List[0].Type}}}
+					}
+					add(&ast.FuncDecl{Recv: recv, Name: decl.Name, Type: decl.Type}, decl.Doc)
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						switch spec := spec.(type) {
+						case *ast.TypeSpec:
+							if !spec.Name.IsExported() {
+								continue
+							}
+							doc := spec.Doc
+							if doc == nil {
+								doc = decl.Doc
+							}
+							add(&ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{exportedType(spec)}}, doc)
+						case *ast.ValueSpec:
+							doc := spec.Doc
+							if doc == nil {
+								doc = decl.Doc
+							}
+							for _, name := range spec.Names {
+								if name.IsExported() {
+									add(&ast.GenDecl{Tok: decl.Tok, Specs: []ast.Spec{
+										&ast.ValueSpec{Names: []*ast.Ident{name}, Type: spec.Type},
+									}}, doc)
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(lines)
+	return lines, nil
+}
+
+func receiverName(recv *ast.FieldList) string {
+	expr := recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// exportedType removes comments and unexported fields and methods.
+func exportedType(spec *ast.TypeSpec) *ast.TypeSpec {
+	filter := func(fields *ast.FieldList) *ast.FieldList {
+		if fields == nil {
+			return nil
+		}
+		filtered := &ast.FieldList{}
+		for _, field := range fields.List {
+			var names []*ast.Ident
+			for _, name := range field.Names {
+				if name.IsExported() {
+					names = append(names, name)
+				}
+			}
+			// embedded fields have no names
+			if len(field.Names) > 0 && len(names) == 0 {
+				continue
+			}
+			filtered.List = append(filtered.List, &ast.Field{Names: names, Type: field.Type, Tag: field.Tag})
+		}
+		return filtered
+	}
+	typ := spec.Type
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		typ = &ast.StructType{Fields: filter(t.Fields)}
+	case *ast.InterfaceType:
+		typ = &ast.InterfaceType{Methods: filter(t.Methods)}
+	}
+	return &ast.TypeSpec{Name: spec.Name, TypeParams: spec.TypeParams, Assign: spec.Assign, Type: typ}
+}
@@ -0,0 +1,172 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gregwebs/errors"
+)
+
+// BatchError collects the errors of the items of a batch, each identified by a key.
+// The code is the code of one of the items chosen by the Policy.
+// The client data gives the JSONFormat of each item by its key.
+// It is safe for concurrent use.
+//
+//	batch := errcode.NewBatchError(nil)
+//	for i, item := range items {
+//		batch.AddIndex(i, save(item))
+//	}
+//	return batch.ErrorOrNil()
+type BatchError struct {
+	policy CodePolicy
+	mu     sync.Mutex
+	keys   []string
+	errs   map[string]ErrorCode
+}
+
+// NewBatchError constructs a BatchError.
+// A nil policy is WorstHTTPStatus.
+func NewBatchError(policy CodePolicy) *BatchError {
+	if policy == nil {
+		policy = WorstHTTPStatus
+	}
+	return &BatchError{policy: policy, errs: make(map[string]ErrorCode)}
+}
+
+// Add the error of an item.
+// A nil error is skipped.
+// An error without an ErrorCode (as found by CodeChain) is converted with NewInternalErr.
+// Adding an error for a key that already has an error replaces it.
+func (b *BatchError) Add(key string, err error) {
+	if err == nil {
+		return
+	}
+	errCode := CodeChain(err)
+	if errCode == nil {
+		errCode = NewInternalErr(err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.errs[key]; !ok {
+		b.keys = append(b.keys, key)
+	}
+	b.errs[key] = errCode
+}
+
+// AddIndex adds the error of an item identified by its index in the batch.
+func (b *BatchError) AddIndex(index int, err error) {
+	b.Add(strconv.Itoa(index), err)
+}
+
+// Len gives the number of items with an error.
+func (b *BatchError) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.keys)
+}
+
+// ErrorOrNil returns nil if there are no errors.
+// Otherwise it returns the BatchError.
+func (b *BatchError) ErrorOrNil() ErrorCode {
+	if b == nil || b.Len() == 0 {
+		return nil
+	}
+	return b
+}
+
+// members gives the errors in the order that they were added.
+func (b *BatchError) members() ([]string, []ErrorCode) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys := append([]string(nil), b.keys...)
+	errCodes := make([]ErrorCode, len(keys))
+	for i, key := range keys {
+		errCodes[i] = b.errs[key]
+	}
+	return keys, errCodes
+}
+
+// Error gives the errors prefixed by their key and separated by a semi-colon.
+func (b *BatchError) Error() string {
+	keys, errCodes := b.members()
+	msgs := make([]string, len(keys))
+	for i, key := range keys {
+		msgs[i] = key + ": " + errCodes[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Code gives the code chosen by the policy.
+// If there are no errors, it gives InternalCode.
+func (b *BatchError) Code() Code {
+	_, errCodes := b.members()
+	if len(errCodes) == 0 {
+		return InternalCode
+	}
+	codes := make([]Code, len(errCodes))
+	for i, errCode := range errCodes {
+		codes[i] = errCode.Code()
+	}
+	return codes[b.policy(codes)]
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs) or a sentinel ErrorCode.
+// Every item is checked.
+func (b *BatchError) Is(target error) bool {
+	switch target.(type) {
+	case CodeTarget, ErrorCode:
+	default:
+		return false
+	}
+	_, errCodes := b.members()
+	for _, errCode := range errCodes {
+		if errors.Is(errCode, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap gives the errors as a LabeledErrCode with their key as the label.
+func (b *BatchError) Unwrap() []error {
+	keys, errCodes := b.members()
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		errs[i] = LabeledErrCode{Label: key, Err: errCodes[i]}
+	}
+	return errs
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+// It gives a map[string]JSONFormat of the errors by their key.
+// NewJSONFormat and WriteJSON apply their options to the JSONFormat of each error.
+func (b *BatchError) GetClientData() interface{} {
+	return b.formatClientData(nil)
+}
+
+func (b *BatchError) formatClientData(opts []FormatOption) interface{} {
+	keys, errCodes := b.members()
+	data := make(map[string]JSONFormat, len(keys))
+	for i, key := range keys {
+		data[key] = NewJSONFormat(errCodes[i], opts...)
+	}
+	return data
+}
+
+var _ ErrorCode = (*BatchError)(nil)           // assert implements interface
+var _ HasClientData = (*BatchError)(nil)       // assert implements interface
+var _ formattedClientData = (*BatchError)(nil) // assert implements interface
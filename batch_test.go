@@ -0,0 +1,128 @@
+package errcode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestBatchError(t *testing.T) {
+	batch := errcode.NewBatchError(nil)
+	if batch.ErrorOrNil() != nil {
+		t.Error("expected nil without errors")
+	}
+	var wg sync.WaitGroup
+	for i, err := range []error{
+		errcode.NewNotFoundErr(errors.New("no item")),
+		nil,
+		errcode.NewUnavailableErr(errors.New("db down")),
+		errcode.NewInvalidInputErr(errors.New("bad")),
+	} {
+		wg.Add(1)
+		go func(i int, err error) {
+			defer wg.Done()
+			batch.AddIndex(i, err)
+		}(i, err)
+	}
+	wg.Wait()
+
+	errCode := batch.ErrorOrNil()
+	if errCode == nil || batch.Len() != 3 {
+		t.Fatalf("expected 3 errors, got %d", batch.Len())
+	}
+	if errCode.Code().CodeStr() != errcode.UnavailableCode.CodeStr() {
+		t.Errorf("expected the worst code, got %s", errCode.Code().CodeStr())
+	}
+	if !errors.Is(errCode, errcode.CodeIs(errcode.NotFoundCode)) {
+		t.Error("expected an item to match")
+	}
+	data, ok := errcode.ClientData(errCode).(map[string]errcode.JSONFormat)
+	if !ok || data["0"].Code != errcode.NotFoundCode.CodeStr() || data["2"].Msg != "db down" || len(data) != 3 {
+		t.Errorf("unexpected client data %v", errcode.ClientData(errCode))
+	}
+
+	first := errcode.NewBatchError(func([]errcode.Code) int { return 0 })
+	first.Add("a", errcode.NewNotFoundErr(errors.New("no a")))
+	first.Add("b", errors.New("uncoded"))
+	first.Add("a", errcode.NewGoneErr(errors.New("a deleted")))
+	if first.Code().CodeStr() != errcode.GoneCode.CodeStr() || first.Error() != "a: a deleted; b: uncoded" {
+		t.Errorf("unexpected batch %s %s", first.Code().CodeStr(), first.Error())
+	}
+	if labels := errcode.Label(errcode.ErrorCodes(first)[1]); labels != "a" {
+		t.Errorf("expected the items to be labeled, got %q", labels)
+	}
+}
+
+func TestBatchErrorJSON(t *testing.T) {
+	batch := errcode.NewBatchError(nil)
+	batch.Add("a", errcode.NewNotFoundErr(errors.New("no item a")))
+	batch.Add("b", errcode.NewInvalidInputErr(errors.New("bad item b")))
+	for _, errCode := range []errcode.ErrorCode{
+		batch,
+		errcode.Op("items.save")(batch),
+	} {
+		for _, opts := range [][]errcode.FormatOption{nil, {errcode.NestOthers()}} {
+			var buf bytes.Buffer
+			if err := errcode.WriteJSON(&buf, errCode, opts...); err != nil {
+				t.Fatal(err)
+			}
+			var format errcode.JSONFormat
+			if err := json.Unmarshal(buf.Bytes(), &format); err != nil {
+				t.Fatal(err)
+			}
+			counts := make(map[string]int)
+			countItems(format, counts)
+			if counts["no item a"] != 1 || counts["bad item b"] != 1 || len(counts) != 2 {
+				t.Errorf("expected each item once, got %v in %s", counts, buf.String())
+			}
+		}
+	}
+}
+
+// countItems counts the messages of the items of batches in the data and the others.
+func countItems(format errcode.JSONFormat, counts map[string]int) {
+	if items, ok := format.Data.(map[string]interface{}); ok {
+		for _, item := range items {
+			if msg, ok := item.(map[string]interface{})["msg"].(string); ok {
+				counts[msg]++
+			}
+		}
+	}
+	for _, other := range format.Others {
+		if strings.Contains(other.Msg, "item") {
+			counts[other.Msg]++
+		}
+		countItems(other, counts)
+	}
+}
+
+func TestBatchErrorRequireUserMsg(t *testing.T) {
+	batch := errcode.NewBatchError(nil)
+	batch.Add("a", errors.New("pq: password authentication failed for user admin at 10.0.0.3"))
+	batch.Add("b", errcode.WithUserMsg("The item was not found", errcode.NewNotFoundErr(errors.New("no item b"))))
+
+	format := errcode.NewJSONFormat(batch, errcode.RequireUserMsg())
+	items := format.Data.(map[string]errcode.JSONFormat)
+	if msg := items["a"].Msg; msg != "Something went wrong" {
+		t.Errorf("expected a generic user message for the internal item, got %q", msg)
+	}
+	if msg := items["b"].Msg; msg != "The item was not found" {
+		t.Errorf("expected the user message of the item, got %q", msg)
+	}
+
+	var buf bytes.Buffer
+	if err := errcode.WriteJSON(&buf, batch, errcode.RequireUserMsg()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "password") {
+		t.Errorf("expected the internal message to be hidden, got %s", buf.String())
+	}
+	if msg := errcode.NewJSONFormat(batch).Data.(map[string]errcode.JSONFormat)["a"].Msg; !strings.Contains(msg, "password") {
+		t.Errorf("expected the message without the option, got %q", msg)
+	}
+}
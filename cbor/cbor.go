@@ -0,0 +1,57 @@
+// Package cbor encodes ErrorCodes as CBOR (RFC 8949) for binary transports.
+//
+// The payload is the JSONFormat of the error with the same field names as its JSON.
+// Unmarshal decodes a payload back into an ErrorCode (an errcode.RemoteErr).
+package cbor
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gregwebs/errcode"
+)
+
+// ContentType is the media type of a CBOR payload.
+const ContentType = "application/cbor"
+
+var decMode = mustDecMode()
+
+// Maps in the Data are decoded as map[string]interface{}, as with JSON.
+func mustDecMode() cbor.DecMode {
+	mode, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}
+
+// Marshal encodes the JSONFormat of the ErrorCode as CBOR.
+// The options are given to NewJSONFormat.
+func Marshal(errCode errcode.ErrorCode, opts ...errcode.FormatOption) ([]byte, error) {
+	return MarshalFormat(errcode.NewJSONFormat(errCode, opts...))
+}
+
+// MarshalFormat encodes a JSONFormat as CBOR.
+// The fields are named by their json tags.
+func MarshalFormat(format errcode.JSONFormat) ([]byte, error) {
+	return cbor.Marshal(format)
+}
+
+// UnmarshalFormat decodes a JSONFormat from CBOR.
+func UnmarshalFormat(data []byte) (errcode.JSONFormat, error) {
+	var format errcode.JSONFormat
+	err := decMode.Unmarshal(data, &format)
+	return format, err
+}
+
+// Unmarshal decodes a payload from Marshal into an ErrorCode.
+// The ErrorCode is an errcode.RemoteErr so that the code, message, and data are propagated.
+func Unmarshal(data []byte) (errcode.ErrorCode, error) {
+	format, err := UnmarshalFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	return errcode.NewRemoteErr(format, nil), nil
+}
@@ -0,0 +1,43 @@
+package cbor_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/cbor"
+)
+
+type itemErr struct {
+	errcode.CodedError
+}
+
+func (e itemErr) GetClientData() interface{} {
+	return map[string]interface{}{"id": "item-1", "tags": []interface{}{"a", "b"}}
+}
+
+func TestRoundTrip(t *testing.T) {
+	notFound := itemErr{CodedError: errcode.NewCodedError(errors.New("no item"), errcode.NotFoundCode)}
+	err := errcode.Combine(
+		errcode.Op("items.get")(notFound),
+		errcode.WithRequestID("req-1", errcode.NewGoneErr(errors.New("deleted"))),
+	)
+	payload, marshalErr := cbor.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	decoded, unmarshalErr := cbor.Unmarshal(payload)
+	if unmarshalErr != nil {
+		t.Fatal(unmarshalErr)
+	}
+	if !reflect.DeepEqual(errcode.NewJSONFormat(decoded), errcode.NewJSONFormat(err)) {
+		t.Errorf("expected %#v, got %#v", errcode.NewJSONFormat(err), errcode.NewJSONFormat(decoded))
+	}
+	if !errors.Is(decoded, errcode.CodeIs(errcode.NotFoundCode)) || errcode.Operation(decoded) != "items.get" {
+		t.Errorf("expected the code and operation to be propagated, got %v", decoded)
+	}
+	if _, err := cbor.Unmarshal([]byte{0xc1}); err == nil {
+		t.Error("expected an error for an invalid payload")
+	}
+}
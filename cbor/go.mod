@@ -0,0 +1,15 @@
+module github.com/gregwebs/errcode/cbor
+
+go 1.21.9
+
+replace github.com/gregwebs/errcode => ../
+
+require (
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/gregwebs/errcode v0.11.0
+)
+
+require (
+	github.com/gregwebs/errors v1.5.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
@@ -0,0 +1,66 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"slices"
+	"sync"
+)
+
+// Classifier converts an error that does not have an ErrorCode to an ErrorCode.
+// It should return false if it does not know how to classify the error.
+// This is used to give codes to errors from libraries (for example a database driver)
+// without wrapping them at every call site.
+type Classifier func(error) (ErrorCode, bool)
+
+var (
+	classifiersMu sync.RWMutex
+	classifiers   []*Classifier
+)
+
+// RegisterClassifier adds a Classifier that is consulted by CodeChain
+// when an error does not have an ErrorCode.
+// Classifiers are consulted in the order they are registered.
+// This is normally done once during program initialization.
+//
+// The returned function removes the Classifier, for example at the end of a test.
+func RegisterClassifier(classifier Classifier) (unregister func()) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+	registered := &classifier
+	classifiers = append(classifiers, registered)
+	return func() {
+		classifiersMu.Lock()
+		defer classifiersMu.Unlock()
+		classifiers = slices.DeleteFunc(classifiers, func(c *Classifier) bool { return c == registered })
+	}
+}
+
+// Classify gives the ErrorCode from the first registered Classifier that recognizes the error.
+// If no Classifier recognizes the error, context errors are classified with FromContextError.
+// Otherwise it returns nil.
+// Normally CodeChain should be used, which will use Classify when needed.
+func Classify(err error) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	classifiersMu.RLock()
+	defer classifiersMu.RUnlock()
+	for _, classifier := range classifiers {
+		if errCode, ok := (*classifier)(err); ok && errCode != nil {
+			return errCode
+		}
+	}
+	return FromContextError(err)
+}
@@ -0,0 +1,75 @@
+package errcode_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+type libraryError struct{ key string }
+
+func (e libraryError) Error() string { return "no such key " + e.key }
+
+func TestRegisterClassifier(t *testing.T) {
+	unclassified := errors.New("unclassified")
+	AssertCodeChain(t, unclassified, nil)
+	libErr := errors.Wrap(libraryError{key: "k"}, "get")
+	AssertCodeChain(t, libErr, nil)
+
+	t.Cleanup(errcode.RegisterClassifier(func(err error) (errcode.ErrorCode, bool) {
+		var libErr libraryError
+		if errors.As(err, &libErr) {
+			return errcode.NewNotFoundErr(libErr), true
+		}
+		return nil, false
+	}))
+
+	AssertCodeChain(t, unclassified, nil)
+	classified := errcode.CodeChain(libErr)
+	AssertCode(t, classified, errcode.NotFoundCode.CodeStr())
+	ErrorEquals(t, classified, "get: no such key k")
+
+	// group members are classified
+	group := errcode.CodeChain(MultiErrors{Multi: []error{unclassified, libErr}})
+	AssertCode(t, group, errcode.NotFoundCode.CodeStr())
+}
+
+func TestUnregisterClassifier(t *testing.T) {
+	libErr := libraryError{key: "k"}
+	unregister := errcode.RegisterClassifier(func(err error) (errcode.ErrorCode, bool) {
+		if errors.As(err, &libraryError{}) {
+			return errcode.NewGoneErr(err), true
+		}
+		return nil, false
+	})
+	AssertCode(t, errcode.CodeChain(libErr), errcode.GoneCode.CodeStr())
+	unregister()
+	AssertCodeChain(t, libErr, nil)
+}
+
+func TestFromContextError(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		code   errcode.Code
+		status int
+	}{
+		{context.Canceled, errcode.CanceledCode, 499},
+		{errors.Wrap(context.DeadlineExceeded, "query"), errcode.DeadlineExceededCode, 504},
+	} {
+		errCode := errcode.FromContextError(test.err)
+		if errCode == nil || errCode.Code().CodeStr() != test.code.CodeStr() {
+			t.Fatalf("expected %v, got %v", test.code, errCode)
+		}
+		if status := errCode.Code().HTTPCode(); status != test.status {
+			t.Errorf("expected %d, got %d", test.status, status)
+		}
+		if !errcode.HasCode(test.err, test.code) {
+			t.Errorf("expected CodeChain to classify %v", test.err)
+		}
+	}
+	if errCode := errcode.FromContextError(errors.New("other")); errCode != nil {
+		t.Errorf("expected nil, got %v", errCode)
+	}
+}
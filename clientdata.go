@@ -0,0 +1,226 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import "encoding/json"
+
+// ClientDataErrCode is an ErrorCode with client data attached.
+// This can be conveniently constructed with WithClientData or Data and AddTo.
+// It avoids defining a struct that implements HasClientData for each kind of data.
+type ClientDataErrCode struct {
+	Data interface{}
+	Err  ErrorCode
+}
+
+// WithClientData attaches client data to an ErrorCode.
+// The data is given by ClientData, which makes it the Data of the JSONFormat.
+// Returns nil if err is nil.
+func WithClientData(data interface{}, err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	return ClientDataErrCode{Data: data, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e ClientDataErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e ClientDataErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e ClientDataErrCode) GetClientData() interface{} {
+	return e.Data
+}
+
+// Code returns the underlying Code of Err.
+func (e ClientDataErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e ClientDataErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*ClientDataErrCode)(nil)     // assert implements interface
+var _ HasClientData = (*ClientDataErrCode)(nil) // assert implements interface
+var _ unwrapError = (*ClientDataErrCode)(nil)   // assert implements interface
+
+// AddData is constructed by Data. It allows method chaining with AddTo.
+type AddData func(ErrorCode) ClientDataErrCode
+
+// AddTo adds the client data from Data to the ErrorCode
+func (add AddData) AddTo(err ErrorCode) ClientDataErrCode {
+	return add(err)
+}
+
+// Data adds client data to an ErrorCode with AddTo.
+// This converts the error to the type ClientDataErrCode.
+// The returned AddData does not copy the data:
+// it can be reused concurrently if the data is not modified.
+//
+//	data := errcode.Data(map[string]string{"item": id})
+//	return data.AddTo(errcode.NewNotFoundErr(err))
+func Data(data interface{}) AddData {
+	return func(err ErrorCode) ClientDataErrCode {
+		if err == nil {
+			panic("Data error is nil")
+		}
+		return ClientDataErrCode{Data: data, Err: err}
+	}
+}
+
+// ClientDataAs finds the first client data of type T by unwrapping the error and traversing groups as ClientData does.
+// Unlike ClientData, it does not stop at client data of another type.
+// As with ClientData, the data is given to the Redactor set with SetRedactor.
+//
+//	if item, ok := errcode.ClientDataAs[ItemData](err); ok {
+func ClientDataAs[T any](err ErrorCode) (T, bool) {
+	var found T
+	ok := walkDeep(err, func(err error) bool {
+		if hasData, isData := err.(HasClientData); isData {
+			var matches bool
+			found, matches = redact(hasData.GetClientData()).(T)
+			return matches
+		}
+		return false
+	})
+	return found, ok
+}
+
+// ClientDataAll gives the client data of every layer of the error, outermost first,
+// followed by the client data of the members of its groups.
+// ClientData only gives the first of these.
+// Nil data is left out.
+// As with ClientData, the data is given to the Redactor set with SetRedactor.
+func ClientDataAll(err ErrorCode) []interface{} {
+	return formatConfig{}.clientDataAll(err)
+}
+
+func (c formatConfig) clientDataAll(err ErrorCode) []interface{} {
+	var all []interface{}
+	walkDeep(err, func(err error) bool {
+		if hasData, ok := err.(HasClientData); ok {
+			if data := c.clientData(hasData); data != nil {
+				all = append(all, data)
+			}
+		}
+		return false
+	})
+	return all
+}
+
+// MergeStrategy decides how the MergeClientData option combines the client data of layers.
+type MergeStrategy int
+
+const (
+	// MergeOuterWins merges the top-level fields: the field of an outer layer replaces the same field of an inner layer.
+	MergeOuterWins MergeStrategy = iota
+	// MergeDeep also merges nested objects. For other values the outer layer wins.
+	MergeDeep
+)
+
+// MergeClientData fills the Data of a JSONFormat by merging the client data of every layer (see ClientDataAll).
+// This is for middleware layers that each contribute data.
+// Maps and structs are merged as the JSON objects they serialize to.
+// If any of the data is not a JSON object, the Data is the outermost data as without this option.
+func MergeClientData(strategy MergeStrategy) FormatOption {
+	return func(c *formatConfig) {
+		c.mergeClientData = true
+		c.mergeStrategy = strategy
+	}
+}
+
+// formattedClientData is client data made of JSONFormats, such as that of a BatchError.
+// NewJSONFormat and WriteJSON give it their options in place of calling GetClientData.
+type formattedClientData interface {
+	formatClientData(opts []FormatOption) interface{}
+}
+
+// clientData gives the client data of a layer with the options applied to a formattedClientData.
+func (c formatConfig) clientData(hasData HasClientData) interface{} {
+	if formatted, ok := hasData.(formattedClientData); ok {
+		return redact(formatted.formatClientData(c.opts))
+	}
+	return redact(hasData.GetClientData())
+}
+
+// operationClientData is OperationClientData with the MergeClientData option applied.
+// The options are also applied to a formattedClientData.
+func (c formatConfig) operationClientData(errCode ErrorCode) (string, interface{}) {
+	op := Operation(errCode)
+	var data interface{}
+	if hasData, ok := findDeep[HasClientData](errCode); ok {
+		data = c.clientData(hasData)
+	}
+	if op == "" && data != nil {
+		op = Operation(data)
+	}
+	if !c.mergeClientData {
+		return op, data
+	}
+	all := c.clientDataAll(errCode)
+	if len(all) < 2 {
+		return op, data
+	}
+	merged := make(map[string]interface{})
+	for i := len(all) - 1; i >= 0; i-- {
+		object, ok := toJSONObject(all[i])
+		if !ok {
+			return op, data
+		}
+		mergeObjects(merged, object, c.mergeStrategy == MergeDeep)
+	}
+	return op, merged
+}
+
+// toJSONObject gives the data as the JSON object it serializes to.
+func toJSONObject(data interface{}) (map[string]interface{}, bool) {
+	if object, ok := data.(map[string]interface{}); ok {
+		return object, true
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, false
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(b, &object); err != nil || object == nil {
+		return nil, false
+	}
+	return object, true
+}
+
+// mergeObjects merges the outer object into the inner object.
+// Nested objects of the inner object are copied before they are modified.
+func mergeObjects(inner map[string]interface{}, outer map[string]interface{}, deep bool) {
+	for key, outerValue := range outer {
+		if deep {
+			innerObject, innerOK := inner[key].(map[string]interface{})
+			outerObject, outerOK := outerValue.(map[string]interface{})
+			if innerOK && outerOK {
+				merged := make(map[string]interface{}, len(innerObject)+len(outerObject))
+				mergeObjects(merged, innerObject, true)
+				mergeObjects(merged, outerObject, true)
+				inner[key] = merged
+				continue
+			}
+		}
+		inner[key] = outerValue
+	}
+}
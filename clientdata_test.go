@@ -0,0 +1,87 @@
+package errcode_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestWithClientData(t *testing.T) {
+	if errcode.WithClientData("x", nil) != nil {
+		t.Error("expected nil")
+	}
+	data := map[string]string{"item": "item-1"}
+	err := errcode.WithClientData(data, errcode.NewNotFoundErr(errors.New("no item")))
+	ErrorEquals(t, err, "no item")
+	ClientDataEquals(t, err, data, errcode.NotFoundCode.CodeStr())
+
+	withOp := errcode.Op("items.get").AddTo(errcode.Data(data).AddTo(errcode.NewGoneErr(errors.New("deleted"))))
+	ClientDataResult(t, withOp, clientDataResult{
+		data:      data,
+		operation: "items.get",
+		codeStr:   errcode.GoneCode.CodeStr(),
+	})
+	if !errors.Is(withOp, errcode.CodeIs(errcode.NotFoundCode)) {
+		t.Error("expected the code to match")
+	}
+}
+
+type itemData struct {
+	ID string
+}
+
+func TestClientDataAs(t *testing.T) {
+	err := errcode.WithClientData("label", errcode.WithClientData(itemData{ID: "item-1"}, errcode.NewNotFoundErr(errors.New("no item"))))
+	if item, ok := errcode.ClientDataAs[itemData](err); !ok || item.ID != "item-1" {
+		t.Errorf("expected the item data, got %v", item)
+	}
+	if label, ok := errcode.ClientDataAs[string](err); !ok || label != "label" {
+		t.Errorf("expected the label, got %v", label)
+	}
+	if _, ok := errcode.ClientDataAs[int](err); ok {
+		t.Error("expected no data of another type")
+	}
+
+	group := errcode.Combine(errcode.NewInvalidInputErr(errors.New("bad")), err)
+	if item, ok := errcode.ClientDataAs[itemData](group); !ok || item.ID != "item-1" {
+		t.Errorf("expected the item data of a group member, got %v", item)
+	}
+	if all := errcode.ClientDataAll(group); len(all) != 2 || all[0] != "label" {
+		t.Errorf("expected the data of the group member, got %v", all)
+	}
+}
+
+func TestMergeClientData(t *testing.T) {
+	type requestData struct {
+		Request string                 `json:"request"`
+		Detail  map[string]interface{} `json:"detail"`
+	}
+	inner := errcode.WithClientData(requestData{Request: "inner", Detail: map[string]interface{}{"item": "item-1", "shelf": "a"}}, errcode.NewNotFoundErr(errors.New("no item")))
+	err := errcode.WithClientData(map[string]interface{}{"request": "outer", "detail": map[string]interface{}{"shelf": "b"}}, inner)
+
+	if all := errcode.ClientDataAll(err); len(all) != 2 {
+		t.Fatalf("expected the data of both layers, got %v", all)
+	}
+	if data := errcode.NewJSONFormat(err).Data; !reflect.DeepEqual(data, errcode.ClientData(err)) {
+		t.Errorf("expected only the outer data without the option, got %v", data)
+	}
+
+	outerWins := errcode.NewJSONFormat(err, errcode.MergeClientData(errcode.MergeOuterWins)).Data
+	expected := map[string]interface{}{"request": "outer", "detail": map[string]interface{}{"shelf": "b"}}
+	if !reflect.DeepEqual(outerWins, expected) {
+		t.Errorf("expected %v, got %v", expected, outerWins)
+	}
+
+	deep := errcode.NewJSONFormat(err, errcode.MergeClientData(errcode.MergeDeep)).Data
+	expected = map[string]interface{}{"request": "outer", "detail": map[string]interface{}{"item": "item-1", "shelf": "b"}}
+	if !reflect.DeepEqual(deep, expected) {
+		t.Errorf("expected %v, got %v", expected, deep)
+	}
+
+	notObject := errcode.WithClientData("label", inner)
+	if data := errcode.NewJSONFormat(notObject, errcode.MergeClientData(errcode.MergeDeep)).Data; data != "label" {
+		t.Errorf("expected the outer data when it is not an object, got %v", data)
+	}
+}
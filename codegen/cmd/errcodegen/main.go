@@ -0,0 +1,55 @@
+// Command errcodegen generates the Go definitions and the documentation of codes from a YAML or JSON catalog.
+// See the codegen package for the catalog format.
+//
+//	errcodegen -catalog codes.yaml -out codes_gen.go -docs CODES.md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gregwebs/errcode/codegen"
+)
+
+func main() {
+	catalogPath := flag.String("catalog", "", "the YAML or JSON catalog of codes")
+	out := flag.String("out", "", "the Go file to generate: standard output if not given")
+	docs := flag.String("docs", "", "a Markdown file to generate with the documentation of the codes")
+	flag.Parse()
+	if err := run(*catalogPath, *out, *docs); err != nil {
+		fmt.Fprintln(os.Stderr, "errcodegen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(catalogPath string, out string, docs string) error {
+	if catalogPath == "" {
+		return fmt.Errorf("the -catalog flag is required")
+	}
+	data, err := os.ReadFile(catalogPath)
+	if err != nil {
+		return err
+	}
+	catalog, err := codegen.ParseCatalog(data)
+	if err != nil {
+		return err
+	}
+	source, err := codegen.GenerateGo(catalog, filepath.Base(catalogPath))
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(source)
+	} else {
+		err = os.WriteFile(out, source, 0o644)
+	}
+	if err != nil {
+		return err
+	}
+	if docs != "" {
+		return os.WriteFile(docs, codegen.GenerateMarkdown(catalog), 0o644)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package codegen generates the Go definitions and the documentation of codes from a declarative catalog.
+// The errcodegen command runs it, for example from a go:generate directive:
+//
+//	//go:generate errcodegen -catalog codes.yaml -out codes_gen.go -docs CODES.md
+//
+// A catalog is YAML or JSON.
+// Codes are given by their full dotted path and a parent must come before its children.
+// A code whose parent is not in the catalog, such as a builtin code, gives the parent as a Go expression.
+//
+//	package: codes
+//	codes:
+//	  - code: plan
+//	    http: 429
+//	    description: The account plan limit is reached
+//	  - code: plan.storage
+//	    http: 507
+//	    grpc: ResourceExhausted
+//	    user_msg: Your storage is full
+//	  - code: missing.invoice
+//	    parent: errcode.NotFoundCode
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog declares codes for a Go package.
+type Catalog struct {
+	Package string      `yaml:"package"`
+	Codes   []CodeEntry `yaml:"codes"`
+}
+
+// CodeEntry declares a code.
+// Code is the full dotted path of the code.
+// Name is the Go variable name: it defaults to the camel case of the path followed by Code, for example PlanStorageCode.
+// Parent is a Go expression for a parent that is not in the catalog, for example errcode.NotFoundCode.
+// GRPC is the name of a GRPC code, for example NotFound.
+// Zero values are not set on the code.
+type CodeEntry struct {
+	Code        string `yaml:"code"`
+	Name        string `yaml:"name,omitempty"`
+	Parent      string `yaml:"parent,omitempty"`
+	HTTP        int    `yaml:"http,omitempty"`
+	GRPC        string `yaml:"grpc,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	UserMsg     string `yaml:"user_msg,omitempty"`
+	Remediation string `yaml:"remediation,omitempty"`
+	Severity    string `yaml:"severity,omitempty"`
+}
+
+// ParseCatalog parses a YAML or JSON catalog and validates it.
+func ParseCatalog(data []byte) (Catalog, error) {
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return catalog, fmt.Errorf("parse catalog: %w", err)
+	}
+	return catalog, catalog.Validate()
+}
+
+var grpcCodes = map[string]bool{
+	"OK": true, "Canceled": true, "Unknown": true, "InvalidArgument": true, "DeadlineExceeded": true,
+	"NotFound": true, "AlreadyExists": true, "PermissionDenied": true, "ResourceExhausted": true,
+	"FailedPrecondition": true, "Aborted": true, "OutOfRange": true, "Unimplemented": true,
+	"Internal": true, "Unavailable": true, "DataLoss": true, "Unauthenticated": true,
+}
+
+var severities = map[string]string{
+	"debug": "SeverityDebug", "info": "SeverityInfo", "warning": "SeverityWarning",
+	"error": "SeverityError", "fatal": "SeverityFatal",
+}
+
+// Validate checks the rules that errcode enforces when the codes are created, so that generated code does not panic.
+// It also checks the names, the GRPC codes, and the severities.
+func (c Catalog) Validate() error {
+	if !token.IsIdentifier(c.Package) {
+		return fmt.Errorf("invalid package name %q", c.Package)
+	}
+	codes := make(map[string]bool, len(c.Codes))
+	names := make(map[string]string, len(c.Codes))
+	for _, entry := range c.Codes {
+		if entry.Code == "" {
+			return fmt.Errorf("a code is missing its path")
+		}
+		if codes[entry.Code] {
+			return fmt.Errorf("duplicate code %s", entry.Code)
+		}
+		parent, _ := splitParent(entry.Code)
+		switch {
+		case parent == "" && entry.Parent != "":
+			return fmt.Errorf("code %s has a parent expression but no parent in its path", entry.Code)
+		case parent != "" && entry.Parent == "" && !codes[parent]:
+			return fmt.Errorf("code %s: the parent %s must come before it in the catalog or be given as a parent expression", entry.Code, parent)
+		}
+		codes[entry.Code] = true
+
+		name := entry.varName()
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return fmt.Errorf("code %s: invalid variable name %q", entry.Code, name)
+		}
+		if existing, ok := names[name]; ok {
+			return fmt.Errorf("codes %s and %s have the same variable name %s", existing, entry.Code, name)
+		}
+		names[name] = entry.Code
+
+		if entry.GRPC != "" && !grpcCodes[entry.GRPC] {
+			return fmt.Errorf("code %s: unknown GRPC code %q", entry.Code, entry.GRPC)
+		}
+		if _, ok := severities[entry.Severity]; entry.Severity != "" && !ok {
+			return fmt.Errorf("code %s: unknown severity %q", entry.Code, entry.Severity)
+		}
+	}
+	return nil
+}
+
+// splitParent gives the path of the parent and the last segment of a code path.
+func splitParent(code string) (string, string) {
+	i := strings.LastIndex(code, ".")
+	if i < 0 {
+		return "", code
+	}
+	return code[:i], code[i+1:]
+}
+
+func (entry CodeEntry) varName() string {
+	if entry.Name != "" {
+		return entry.Name
+	}
+	var name strings.Builder
+	upper := true
+	for _, r := range entry.Code {
+		if r == '.' || r == '_' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	return name.String() + "Code"
+}
+
+// GenerateGo generates the Go variable definitions of the codes, formatted with gofmt.
+// The source is the name of the catalog file for the generated header.
+func GenerateGo(c Catalog, source string) ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	usesGRPC := false
+	for _, entry := range c.Codes {
+		usesGRPC = usesGRPC || entry.GRPC != ""
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by errcodegen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", c.Package)
+	b.WriteString("import (\n\t\"github.com/gregwebs/errcode\"\n")
+	if usesGRPC {
+		b.WriteString("\terrgrpc \"github.com/gregwebs/errcode/grpc\"\n\t\"google.golang.org/grpc/codes\"\n")
+	}
+	b.WriteString(")\n\n")
+
+	varNames := make(map[string]string, len(c.Codes))
+	b.WriteString("var (\n")
+	for _, entry := range c.Codes {
+		name := entry.varName()
+		varNames[entry.Code] = name
+		if entry.Description != "" {
+			fmt.Fprintf(&b, "\t// %s: %s\n", name, entry.Description)
+		}
+		var expr string
+		if parent, _ := splitParent(entry.Code); parent == "" {
+			expr = fmt.Sprintf("errcode.NewCode(%q)", entry.Code)
+		} else if entry.Parent != "" {
+			expr = fmt.Sprintf("%s.Child(%q)", entry.Parent, entry.Code)
+		} else {
+			expr = fmt.Sprintf("%s.Child(%q)", varNames[parent], entry.Code)
+		}
+		if entry.HTTP != 0 {
+			expr += fmt.Sprintf(".SetHTTP(%d)", entry.HTTP)
+		}
+		if entry.Description != "" {
+			expr += fmt.Sprintf(".SetDescription(%q)", entry.Description)
+		}
+		if entry.Remediation != "" {
+			expr += fmt.Sprintf(".SetRemediation(%q)", entry.Remediation)
+		}
+		if entry.UserMsg != "" {
+			expr += fmt.Sprintf(".SetDefaultUserMsg(%q)", entry.UserMsg)
+		}
+		if entry.Severity != "" {
+			expr += fmt.Sprintf(".SetSeverity(errcode.%s)", severities[entry.Severity])
+		}
+		if entry.GRPC != "" {
+			expr = fmt.Sprintf("errgrpc.SetCode(%s, codes.%s)", expr, entry.GRPC)
+		}
+		fmt.Fprintf(&b, "\t%s = %s\n", name, expr)
+	}
+	b.WriteString(")\n")
+	return format.Source(b.Bytes())
+}
+
+// GenerateMarkdown generates a Markdown table that documents the codes, ordered by code.
+func GenerateMarkdown(c Catalog) []byte {
+	entries := append([]CodeEntry(nil), c.Codes...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+
+	var b bytes.Buffer
+	b.WriteString("# Error codes\n\n")
+	b.WriteString("| Code | HTTP | GRPC | Description | User message | Remediation |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, entry := range entries {
+		http := ""
+		if entry.HTTP != 0 {
+			http = fmt.Sprint(entry.HTTP)
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n", entry.Code, http, entry.GRPC,
+			markdownCell(entry.Description), markdownCell(entry.UserMsg), markdownCell(entry.Remediation))
+	}
+	return b.Bytes()
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
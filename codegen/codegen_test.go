@@ -0,0 +1,116 @@
+package codegen_test
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode/codegen"
+)
+
+var update = flag.Bool("update", false, "update the generated files in testdata")
+
+func TestGenerate(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "codes.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := codegen.ParseCatalog(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := codegen.GenerateGo(catalog, "codes.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "codes_gen.go.golden", source)
+	assertGolden(t, "CODES.md.golden", codegen.GenerateMarkdown(catalog))
+}
+
+func assertGolden(t *testing.T, name string, generated []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(golden, generated, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(expected) != string(generated) {
+		t.Errorf("%s changed, if this is intended run: go test -update\n%s", name, generated)
+	}
+}
+
+func TestParseCatalogErrors(t *testing.T) {
+	for catalog, expected := range map[string]string{
+		`{"package": "codes", "codes": [{"code": "plan.storage"}]}`:                                         "the parent plan must come before it",
+		`{"package": "codes", "codes": [{"code": "plan"}, {"code": "plan"}]}`:                               "duplicate code plan",
+		`{"package": "codes", "codes": [{"code": "plan", "grpc": "Missing"}]}`:                              `unknown GRPC code "Missing"`,
+		`{"package": "codes", "codes": [{"code": "plan", "severity": "bad"}]}`:                              `unknown severity "bad"`,
+		`{"package": "codes", "codes": [{"code": "plan", "parent": "errcode.NotFoundCode"}]}`:               "no parent in its path",
+		`{"package": "codes", "codes": [{"code": "a.b", "parent": "P"}, {"code": "ab", "name": "ABCode"}]}`: "the same variable name ABCode",
+		`{"package": "my-codes", "codes": []}`:                                                              "invalid package name",
+	} {
+		if _, err := codegen.ParseCatalog([]byte(catalog)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error with %q for %s, got %v", expected, catalog, err)
+		}
+	}
+}
+
+// TestGeneratedRuns compiles the generated code against the errcode packages of this repository and runs its init,
+// which panics if a generated code collides with a code of the errcode package.
+func TestGeneratedRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command")
+	}
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	source, err := os.ReadFile(filepath.Join("testdata", "codes_gen.go.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod": "module example\n\ngo 1.21.9\n\n" +
+			"require (\n\tgithub.com/gregwebs/errcode v0.0.0\n\tgithub.com/gregwebs/errcode/grpc v0.0.0\n)\n\n" +
+			"replace github.com/gregwebs/errcode => " + root + "\n\n" +
+			"replace github.com/gregwebs/errcode/grpc => " + filepath.Join(root, "grpc") + "\n",
+		"codes/codes_gen.go": string(source),
+		"main.go":            "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example/codes\"\n)\n\nfunc main() {\n\tfmt.Print(codes.PlanStorageCode.HTTPCode())\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) string {
+		cmd := exec.Command(goCmd, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+		return string(output)
+	}
+	run("mod", "tidy")
+	if output := run("run", "."); output != "507" {
+		t.Errorf("expected the HTTP code of the generated code, got %s", output)
+	}
+}
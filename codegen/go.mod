@@ -0,0 +1,5 @@
+module github.com/gregwebs/errcode/codegen
+
+go 1.21.9
+
+require gopkg.in/yaml.v3 v3.0.1
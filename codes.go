@@ -14,8 +14,14 @@
 package errcode
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gregwebs/errors"
 )
 
 var (
@@ -25,12 +31,20 @@ var (
 	// NotFoundCode is equivalent to HTTP 404 Not Found.
 	NotFoundCode = NewCode("missing").SetHTTP(http.StatusNotFound)
 
+	// GoneCode indicates an entity existed but was permanently removed.
+	// This is mapped to HTTP 410.
+	GoneCode = NotFoundCode.Child("missing.gone").SetHTTP(http.StatusGone)
+
 	// UnimplementedCode is mapped to HTTP 501.
 	UnimplementedCode = InternalCode.Child("internal.unimplemented").SetHTTP(http.StatusNotImplemented)
 
 	// Unavailable is mapped to HTTP 503.
 	UnavailableCode = InternalCode.Child("internal.unavailable").SetHTTP(http.StatusServiceUnavailable)
 
+	// NotImplementedRouteCode indicates a route that is declared but not implemented yet.
+	// This is mapped to HTTP 501.
+	NotImplementedRouteCode = UnimplementedCode.Child("internal.unimplemented.route")
+
 	// StateCode is an error that is invalid due to the current system state.
 	// This operatiom could become valid if the system state changes
 	// This is mapped to HTTP 400.
@@ -41,6 +55,21 @@ var (
 	// It would also be possible to use a 400
 	AlreadyExistsCode = StateCode.Child("state.exists").SetHTTP(http.StatusUnprocessableEntity)
 
+	// PaymentRequiredCode indicates the operation requires a payment or a paid plan.
+	// This is mapped to HTTP 402.
+	PaymentRequiredCode = StateCode.Child("state.payment").SetHTTP(http.StatusPaymentRequired)
+
+	// ConflictCode indicates the request conflicts with the current state of an entity,
+	// for example a concurrent modification.
+	// The request could succeed if it is re-submitted after the conflict is resolved.
+	// This is mapped to HTTP 409.
+	ConflictCode = StateCode.Child("state.conflict").SetHTTP(http.StatusConflict)
+
+	// PreconditionFailedCode indicates a precondition given in the request did not hold,
+	// for example an If-Match header with an outdated ETag.
+	// This is mapped to HTTP 412.
+	PreconditionFailedCode = StateCode.Child("state.precondition").SetHTTP(http.StatusPreconditionFailed)
+
 	// OutOfRangeCode indicates an operation was attempted past a valid range.
 	// This is mapped to HTTP 400.
 	OutOfRangeCode = StateCode.Child("state.range")
@@ -50,6 +79,41 @@ var (
 
 	NotAcceptableCode = InvalidInputCode.Child("input.notacceptable").SetHTTP(http.StatusNotAcceptable)
 
+	// PayloadTooLargeCode indicates the request body or an uploaded file is larger than allowed.
+	// This is mapped to HTTP 413.
+	PayloadTooLargeCode = InvalidInputCode.Child("input.toolarge").SetHTTP(http.StatusRequestEntityTooLarge)
+
+	// UnsupportedMediaTypeCode indicates the content type of the request is not supported.
+	// This is mapped to HTTP 415.
+	UnsupportedMediaTypeCode = InvalidInputCode.Child("input.mediatype").SetHTTP(http.StatusUnsupportedMediaType)
+
+	// MethodNotAllowedCode indicates the route does not support the method of the request.
+	// This is mapped to HTTP 405. See NewMethodNotAllowedErr, which gives the Allow header.
+	MethodNotAllowedCode = InvalidInputCode.Child("input.method").SetHTTP(http.StatusMethodNotAllowed)
+
+	// TooManyRequestsCode indicates the client has sent too many requests and is being rate limited.
+	// This is mapped to HTTP 429.
+	// See NewRateLimitErr.
+	TooManyRequestsCode = NewCode("ratelimit").SetHTTP(http.StatusTooManyRequests)
+
+	// QuotaCode indicates a quota of the account or the client is exhausted.
+	// This is mapped to HTTP 429.
+	// See NewQuotaErr.
+	QuotaCode = NewCode("quota").SetHTTP(http.StatusTooManyRequests)
+
+	// QuotaExceededCode indicates an allowance, such as a number of projects, is used up.
+	// This is mapped to HTTP 429.
+	QuotaExceededCode = QuotaCode.Child("quota.exceeded")
+
+	// QuotaStorageCode indicates there is not enough storage left in the quota.
+	// This is mapped to HTTP 507.
+	QuotaStorageCode = QuotaCode.Child("quota.storage").SetHTTP(http.StatusInsufficientStorage)
+
+	// QuotaRateCode indicates the requests of a period, such as a day, are used up.
+	// Unlike TooManyRequestsCode, retrying soon will normally not succeed.
+	// This is mapped to HTTP 429.
+	QuotaRateCode = QuotaCode.Child("quota.rate")
+
 	// AuthCode represents an authentication or authorization issue.
 	AuthCode = NewCode("auth")
 
@@ -68,17 +132,37 @@ var (
 	TimeoutCode        = NewCode("timeout")
 	TimeoutGatewayCode = TimeoutCode.Child("timeout.gateway").SetHTTP(http.StatusGatewayTimeout)
 	TimeoutRequestCode = TimeoutCode.Child("timeout.request").SetHTTP(http.StatusRequestTimeout)
+
+	// DeadlineExceededCode indicates a context deadline expired before the operation completed.
+	// This is mapped to HTTP 504.
+	DeadlineExceededCode = TimeoutCode.Child("timeout.deadline").SetHTTP(http.StatusGatewayTimeout)
+
+	// CanceledCode indicates the operation was canceled, normally by the caller.
+	// This is mapped to HTTP 499, the non-standard "Client Closed Request" status used by nginx.
+	CanceledCode = NewCode("canceled").SetHTTP(StatusClientClosedRequest)
+
+	// ClientCanceledCode indicates the client disconnected before the response was written.
+	// It is excluded from error rates because it is not a failure of the service.
+	ClientCanceledCode = CanceledCode.Child("canceled.client").SetSeverity(SeverityInfo).SetExcludeFromErrorRate()
 )
 
+// StatusClientClosedRequest is the non-standard HTTP status for a request canceled by the client.
+const StatusClientClosedRequest = 499
+
 // CodedError is a convenience to attach a code to an error and already satisfy the ErrorCode interface.
 // If the error is a struct, that struct will get preseneted as data to the client.
 //
 // To override the http code or the data representation or just for clearer documentation,
 // you are encouraged to wrap CodeError with your own struct that inherits it.
 // Look at the implementation of invalidInput, InternalErr, and notFound.
+//
+// Err is the error that was given.
+// A stack trace or creation time recorded by a constructor is kept on the CodedError rather than wrapped around Err.
 type CodedError struct {
 	GetCode Code
 	Err     error
+	stack   *pcStack
+	time    time.Time
 }
 
 // NewCodedError is for constructing broad error kinds (e.g. those representing HTTP codes)
@@ -88,6 +172,10 @@ type CodedError struct {
 //
 // If the error given is already an ErrorCode,
 // that will be used as the code instead of the second argument.
+//
+// If the StackPolicy captures a stack trace for the code, it is recorded on the CodedError.
+// With the default policy this only happens for internal codes.
+// Err is always the given error.
 func NewCodedError(err error, code Code) CodedError {
 	if err == nil {
 		panic("NewCodedError error is nil")
@@ -95,11 +183,45 @@ func NewCodedError(err error, code Code) CodedError {
 	if errcode, ok := err.(ErrorCode); ok {
 		code = errcode.Code()
 	}
-	return CodedError{GetCode: code, Err: err}
+	return newCodedError(err, code, 1)
+}
+
+// Err creates a new error with the code from a message.
+// This avoids creating an error and then wrapping it with NewCodedError.
+// If the StackPolicy captures a stack trace for the code, it is added to the error.
+//
+//	return ItemLockedCode.Err("the item is locked")
+func (code Code) Err(msg string) CodedError {
+	return code.newErr(stderrors.New(msg))
 }
 
-var _ ErrorCode = (*CodedError)(nil)   // assert implements interface
-var _ unwrapError = (*CodedError)(nil) // assert implements interface
+// Errf creates a new error with the code from a format string as with fmt.Errorf.
+// A %w verb wraps an error as with fmt.Errorf.
+// If the StackPolicy captures a stack trace for the code, it is added to the error.
+func (code Code) Errf(format string, args ...interface{}) CodedError {
+	return code.newErr(fmt.Errorf(format, args...))
+}
+
+// newErr must be called directly by an exported function so that the stack starts at its caller.
+func (code Code) newErr(err error) CodedError {
+	return newCodedError(err, code, 2)
+}
+
+// newCodedError records the creation time and a stack trace according to the StackPolicy.
+// A skip of 0 starts the stack trace at the caller of newCodedError.
+// Neither is recorded when err already has one.
+func newCodedError(err error, code Code, skip int) CodedError {
+	codedErr := CodedError{GetCode: code, Err: err, time: newTimestamp(err)}
+	if captureStack(code) && !HasStack(err) {
+		codedErr.stack = newPCStack(skip + 1)
+	}
+	return codedErr
+}
+
+var _ ErrorCode = (*CodedError)(nil)              // assert implements interface
+var _ unwrapError = (*CodedError)(nil)            // assert implements interface
+var _ errors.StackTraceAware = (*CodedError)(nil) // assert implements interface
+var _ HasTimestamp = (*CodedError)(nil)           // assert implements interface
 
 func (e CodedError) Error() string {
 	return e.Err.Error()
@@ -115,6 +237,31 @@ func (e CodedError) Code() Code {
 	return e.GetCode
 }
 
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e CodedError) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+// StackTrace fulfills the StackTracer interface.
+// When the StackPolicy did not capture a stack trace, the stack trace of Err is given, if there is one.
+func (e CodedError) StackTrace() errors.StackTrace {
+	if e.stack == nil {
+		return StackTrace(e.Err)
+	}
+	return e.stack.StackTrace()
+}
+
+// HasStack satisfies the errors package StackTraceAware interface
+func (e CodedError) HasStack() bool {
+	return e.stack != nil || HasStack(e.Err)
+}
+
+// GetTimestamp satisfies the [HasTimestamp] interface.
+// It is the zero time unless SetCaptureTimestamps was enabled when the CodedError was created.
+func (e CodedError) GetTimestamp() time.Time {
+	return e.time
+}
+
 // invalidInputErr gives the code InvalidInputCode.
 type invalidInputErr struct{ CodedError }
 
@@ -125,6 +272,16 @@ func NewInvalidInputErr(err error) ErrorCode {
 	return invalidInputErr{NewCodedError(err, InvalidInputCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e invalidInputErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "invalidInputErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 var _ ErrorCode = (*invalidInputErr)(nil)   // assert implements interface
 var _ unwrapError = (*invalidInputErr)(nil) // assert implements interface
 
@@ -138,6 +295,16 @@ func NewBadRequestErr(err error) BadRequestErr {
 	return BadRequestErr{NewCodedError(err, InvalidInputCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e BadRequestErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "BadRequestErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 // InternalErr gives the code InternalCode
 type InternalErr struct{ StackCode }
 
@@ -147,11 +314,21 @@ var internalStackCode = makeInternalStackCode(InternalCode)
 // If the given err is an ErrorCode that is a descendant of InternalCode,
 // its code will be used.
 // This ensures the intention of sending an HTTP 50x.
-// This function also records a stack trace.
+// This function also records a stack trace according to the StackPolicy.
 func NewInternalErr(err error) InternalErr {
 	return InternalErr{internalStackCode(err)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e InternalErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "InternalErr{StackCode: %#v}", e.StackCode)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 var _ ErrorCode = (*InternalErr)(nil)   // assert implements interface
 var _ unwrapError = (*InternalErr)(nil) // assert implements interface
 
@@ -171,7 +348,11 @@ func makeInternalStackCode(defaultCode Code) func(error) StackCode {
 				code = errCode
 			}
 		}
-		return NewStackCode(CodedError{GetCode: code, Err: err}, 3)
+		codedErr := CodedError{GetCode: code, Err: err, time: newTimestamp(err)}
+		if !captureStack(code) {
+			return StackCode{Err: codedErr}
+		}
+		return NewStackCode(codedErr, 3)
 	}
 }
 
@@ -188,6 +369,16 @@ func NewUnimplementedErr(err error) UnimplementedErr {
 	return UnimplementedErr{unimplementedStackCode(err)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e UnimplementedErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "UnimplementedErr{StackCode: %#v}", e.StackCode)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 type UnavailableErr struct{ StackCode }
 
 var unavailableStackCode = makeInternalStackCode(UnavailableCode)
@@ -201,6 +392,16 @@ func NewUnavailableErr(err error) UnavailableErr {
 	return UnavailableErr{unavailableStackCode(err)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e UnavailableErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "UnavailableErr{StackCode: %#v}", e.StackCode)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 // notFound gives the code NotFoundCode.
 type NotFoundErr struct{ CodedError }
 
@@ -211,9 +412,42 @@ func NewNotFoundErr(err error) NotFoundErr {
 	return NotFoundErr{NewCodedError(err, NotFoundCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e NotFoundErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "NotFoundErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 var _ ErrorCode = (*NotFoundErr)(nil)   // assert implements interface
 var _ unwrapError = (*NotFoundErr)(nil) // assert implements interface
 
+// GoneErr gives the code GoneCode.
+type GoneErr struct{ CodedError }
+
+// NewGoneErr creates a GoneErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use GoneCode which gives HTTP 410.
+func NewGoneErr(err error) GoneErr {
+	return GoneErr{NewCodedError(err, GoneCode)}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e GoneErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "GoneErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+var _ ErrorCode = (*GoneErr)(nil)   // assert implements interface
+var _ unwrapError = (*GoneErr)(nil) // assert implements interface
+
 // NotAuthenticatedErr gives the code NotAuthenticatedCode.
 type NotAuthenticatedErr struct{ CodedError }
 
@@ -224,6 +458,16 @@ func NewNotAuthenticatedErr(err error) NotAuthenticatedErr {
 	return NotAuthenticatedErr{NewCodedError(err, NotAuthenticatedCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e NotAuthenticatedErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "NotAuthenticatedErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 var _ ErrorCode = (*NotAuthenticatedErr)(nil)   // assert implements interface
 var _ unwrapError = (*NotAuthenticatedErr)(nil) // assert implements interface
 
@@ -237,6 +481,16 @@ func NewForbiddenErr(err error) ForbiddenErr {
 	return ForbiddenErr{NewCodedError(err, ForbiddenCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e ForbiddenErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "ForbiddenErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 var _ ErrorCode = (*ForbiddenErr)(nil)   // assert implements interface
 var _ unwrapError = (*ForbiddenErr)(nil) // assert implements interface
 
@@ -250,6 +504,16 @@ func NewUnprocessableErr(err error) UnprocessableErr {
 	return UnprocessableErr{NewCodedError(err, UnprocessableEntityCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e UnprocessableErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "UnprocessableErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 // NotAcceptableErr gives the code NotAcceptableCode.
 type NotAcceptableErr struct{ CodedError }
 
@@ -260,6 +524,16 @@ func NewNotAcceptableErr(err error) NotAcceptableErr {
 	return NotAcceptableErr{NewCodedError(err, NotAcceptableCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e NotAcceptableErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "NotAcceptableErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 type AlreadyExistsErr struct{ CodedError }
 
 // NewAlreadyExistsErr creates an AlreadyExistsErr from an err.
@@ -269,6 +543,85 @@ func NewAlreadyExistsErr(err error) AlreadyExistsErr {
 	return AlreadyExistsErr{NewCodedError(err, AlreadyExistsCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e AlreadyExistsErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "AlreadyExistsErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// PaymentRequiredErr gives the code PaymentRequiredCode.
+type PaymentRequiredErr struct{ CodedError }
+
+// NewPaymentRequiredErr creates a PaymentRequiredErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use PaymentRequiredCode which gives HTTP 402.
+func NewPaymentRequiredErr(err error) PaymentRequiredErr {
+	return PaymentRequiredErr{NewCodedError(err, PaymentRequiredCode)}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e PaymentRequiredErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "PaymentRequiredErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+var _ ErrorCode = (*PaymentRequiredErr)(nil)   // assert implements interface
+var _ unwrapError = (*PaymentRequiredErr)(nil) // assert implements interface
+
+// ConflictErr gives the code ConflictCode.
+type ConflictErr struct{ CodedError }
+
+// NewConflictErr creates a ConflictErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use ConflictCode which gives HTTP 409.
+func NewConflictErr(err error) ConflictErr {
+	return ConflictErr{NewCodedError(err, ConflictCode)}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e ConflictErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "ConflictErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+var _ ErrorCode = (*ConflictErr)(nil)   // assert implements interface
+var _ unwrapError = (*ConflictErr)(nil) // assert implements interface
+
+// PreconditionFailedErr gives the code PreconditionFailedCode.
+type PreconditionFailedErr struct{ CodedError }
+
+// NewPreconditionFailedErr creates a PreconditionFailedErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use PreconditionFailedCode which gives HTTP 412.
+func NewPreconditionFailedErr(err error) PreconditionFailedErr {
+	return PreconditionFailedErr{NewCodedError(err, PreconditionFailedCode)}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e PreconditionFailedErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "PreconditionFailedErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+var _ ErrorCode = (*PreconditionFailedErr)(nil)   // assert implements interface
+var _ unwrapError = (*PreconditionFailedErr)(nil) // assert implements interface
+
 // TimeoutGatewayErr gives the code TimeoutGatewayCode
 type TimeoutGatewayErr struct{ CodedError }
 
@@ -279,6 +632,16 @@ func NewTimeoutGatewayErr(err error) TimeoutGatewayErr {
 	return TimeoutGatewayErr{NewCodedError(err, TimeoutGatewayCode)}
 }
 
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e TimeoutGatewayErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "TimeoutGatewayErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
 // TimeoutRequestErr gives the code TimeoutRequestCode
 type TimeoutRequestErr struct{ CodedError }
 
@@ -288,3 +651,286 @@ type TimeoutRequestErr struct{ CodedError }
 func NewTimeoutRequestErr(err error) TimeoutRequestErr {
 	return TimeoutRequestErr{NewCodedError(err, TimeoutRequestCode)}
 }
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e TimeoutRequestErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "TimeoutRequestErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// PayloadTooLargeErr gives the code PayloadTooLargeCode.
+// The Limit and the Received size, when known, are given as client data.
+type PayloadTooLargeErr struct {
+	CodedError
+	Limit    int64
+	Received int64
+}
+
+// PayloadTooLargeData is the client data of a PayloadTooLargeErr.
+// Received is zero when the size is not known.
+type PayloadTooLargeData struct {
+	Limit    int64 `json:"limit"`
+	Received int64 `json:"received,omitempty"`
+}
+
+// NewPayloadTooLargeErr creates a PayloadTooLargeErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use PayloadTooLargeCode which gives HTTP 413.
+// Use zero for the received size when it is not known.
+func NewPayloadTooLargeErr(err error, limit int64, received int64) PayloadTooLargeErr {
+	return PayloadTooLargeErr{
+		CodedError: NewCodedError(err, PayloadTooLargeCode),
+		Limit:      limit,
+		Received:   received,
+	}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e PayloadTooLargeErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "PayloadTooLargeErr{CodedError: %#v, Limit: %#v, Received: %#v}", e.CodedError, e.Limit, e.Received)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e PayloadTooLargeErr) GetClientData() interface{} {
+	return PayloadTooLargeData{Limit: e.Limit, Received: e.Received}
+}
+
+var _ ErrorCode = (*PayloadTooLargeErr)(nil)     // assert implements interface
+var _ HasClientData = (*PayloadTooLargeErr)(nil) // assert implements interface
+
+// UnsupportedMediaTypeErr gives the code UnsupportedMediaTypeCode.
+// The ContentType that was received and the Supported media types are given as client data.
+type UnsupportedMediaTypeErr struct {
+	CodedError
+	ContentType string
+	Supported   []string
+}
+
+// UnsupportedMediaTypeData is the client data of an UnsupportedMediaTypeErr.
+type UnsupportedMediaTypeData struct {
+	ContentType string   `json:"content_type"`
+	Supported   []string `json:"supported,omitempty"`
+}
+
+// NewUnsupportedMediaTypeErr creates an UnsupportedMediaTypeErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use UnsupportedMediaTypeCode which gives HTTP 415.
+func NewUnsupportedMediaTypeErr(err error, contentType string, supported ...string) UnsupportedMediaTypeErr {
+	return UnsupportedMediaTypeErr{
+		CodedError:  NewCodedError(err, UnsupportedMediaTypeCode),
+		ContentType: contentType,
+		Supported:   supported,
+	}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e UnsupportedMediaTypeErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "UnsupportedMediaTypeErr{CodedError: %#v, ContentType: %#v, Supported: %#v}", e.CodedError, e.ContentType, e.Supported)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e UnsupportedMediaTypeErr) GetClientData() interface{} {
+	return UnsupportedMediaTypeData{ContentType: e.ContentType, Supported: e.Supported}
+}
+
+var _ ErrorCode = (*UnsupportedMediaTypeErr)(nil)     // assert implements interface
+var _ HasClientData = (*UnsupportedMediaTypeErr)(nil) // assert implements interface
+
+// MethodNotAllowedErr gives the code MethodNotAllowedCode.
+// The Method of the request and the Allow methods of the route are given as client data
+// and the Allow methods are given as the Allow header (see HTTPHeaders).
+type MethodNotAllowedErr struct {
+	CodedError
+	Method string
+	Allow  []string
+}
+
+// MethodNotAllowedData is the client data of a MethodNotAllowedErr.
+type MethodNotAllowedData struct {
+	Method string   `json:"method"`
+	Allow  []string `json:"allow"`
+}
+
+// NewMethodNotAllowedErr creates a MethodNotAllowedErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use MethodNotAllowedCode which gives HTTP 405.
+func NewMethodNotAllowedErr(err error, method string, allow ...string) MethodNotAllowedErr {
+	return MethodNotAllowedErr{
+		CodedError: NewCodedError(err, MethodNotAllowedCode),
+		Method:     method,
+		Allow:      allow,
+	}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e MethodNotAllowedErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "MethodNotAllowedErr{CodedError: %#v, Method: %#v, Allow: %#v}", e.CodedError, e.Method, e.Allow)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e MethodNotAllowedErr) GetClientData() interface{} {
+	return MethodNotAllowedData{Method: e.Method, Allow: e.Allow}
+}
+
+// GetHTTPHeaders satisfies the [HasHTTPHeaders] interface with the Allow header.
+// HTTP requires the Allow header in a 405 response, even when it is empty.
+func (e MethodNotAllowedErr) GetHTTPHeaders() http.Header {
+	return http.Header{"Allow": {strings.Join(e.Allow, ", ")}}
+}
+
+var _ ErrorCode = (*MethodNotAllowedErr)(nil)      // assert implements interface
+var _ HasClientData = (*MethodNotAllowedErr)(nil)  // assert implements interface
+var _ HasHTTPHeaders = (*MethodNotAllowedErr)(nil) // assert implements interface
+
+// NotImplementedRouteErr gives the code NotImplementedRouteCode.
+type NotImplementedRouteErr struct{ CodedError }
+
+// NewNotImplementedRouteErr creates a NotImplementedRouteErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use NotImplementedRouteCode which gives HTTP 501.
+func NewNotImplementedRouteErr(err error) NotImplementedRouteErr {
+	return NotImplementedRouteErr{NewCodedError(err, NotImplementedRouteCode)}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e NotImplementedRouteErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "NotImplementedRouteErr{CodedError: %#v}", e.CodedError)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+var _ ErrorCode = (*NotImplementedRouteErr)(nil)   // assert implements interface
+var _ unwrapError = (*NotImplementedRouteErr)(nil) // assert implements interface
+
+// QuotaErr gives a code of the QuotaCode family.
+// The Current usage and the Limit of the quota are given as client data.
+type QuotaErr struct {
+	CodedError
+	Current int64
+	Limit   int64
+}
+
+// QuotaData is the client data of a QuotaErr.
+type QuotaData struct {
+	Current int64 `json:"current"`
+	Limit   int64 `json:"limit"`
+}
+
+// NewQuotaErr creates a QuotaErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use the code, which should be QuotaCode or one of its descendants,
+// such as QuotaStorageCode which gives HTTP 507.
+func NewQuotaErr(err error, code Code, current int64, limit int64) QuotaErr {
+	return QuotaErr{
+		CodedError: NewCodedError(err, code),
+		Current:    current,
+		Limit:      limit,
+	}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e QuotaErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "QuotaErr{CodedError: %#v, Current: %#v, Limit: %#v}", e.CodedError, e.Current, e.Limit)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e QuotaErr) GetClientData() interface{} {
+	return QuotaData{Current: e.Current, Limit: e.Limit}
+}
+
+var _ ErrorCode = (*QuotaErr)(nil)     // assert implements interface
+var _ HasClientData = (*QuotaErr)(nil) // assert implements interface
+
+// FromContextError gives an ErrorCode for a context error.
+// context.Canceled is given CanceledCode
+// and context.DeadlineExceeded is given DeadlineExceededCode.
+// The context error may be wrapped.
+// Returns nil for any other error.
+//
+// This is used by Classify, so CodeChain gives these codes to context errors rather than treating them as unknown.
+func FromContextError(err error) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) {
+		return NewCodedError(err, CanceledCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewCodedError(err, DeadlineExceededCode)
+	}
+	return nil
+}
+
+// httpStatusCodes gives the most specific builtin code for an HTTP status.
+var httpStatusCodes = map[int]Code{
+	http.StatusBadRequest:            InvalidInputCode,
+	http.StatusUnauthorized:          NotAuthenticatedCode,
+	http.StatusPaymentRequired:       PaymentRequiredCode,
+	http.StatusForbidden:             ForbiddenCode,
+	http.StatusNotFound:              NotFoundCode,
+	http.StatusMethodNotAllowed:      MethodNotAllowedCode,
+	http.StatusNotAcceptable:         NotAcceptableCode,
+	http.StatusRequestTimeout:        TimeoutRequestCode,
+	http.StatusConflict:              ConflictCode,
+	http.StatusGone:                  GoneCode,
+	http.StatusPreconditionFailed:    PreconditionFailedCode,
+	http.StatusRequestEntityTooLarge: PayloadTooLargeCode,
+	http.StatusUnsupportedMediaType:  UnsupportedMediaTypeCode,
+	http.StatusUnprocessableEntity:   UnprocessableEntityCode,
+	http.StatusTooManyRequests:       TooManyRequestsCode,
+	StatusClientClosedRequest:        CanceledCode,
+	http.StatusInternalServerError:   InternalCode,
+	http.StatusNotImplemented:        UnimplementedCode,
+	http.StatusServiceUnavailable:    UnavailableCode,
+	http.StatusInsufficientStorage:   QuotaStorageCode,
+	http.StatusGatewayTimeout:        TimeoutGatewayCode,
+}
+
+// FromHTTPStatus gives an ErrorCode with the most specific builtin code for an HTTP status,
+// for example NotFoundCode for 404 and UnavailableCode for 503.
+// This is used for wrapping errors from a downstream HTTP service.
+// A 4xx status without a builtin code is given InvalidInputCode,
+// and any other status is given InternalCode.
+// If the error is already an ErrorCode it will use that code.
+// If the error is nil, an error is created from the status text.
+func FromHTTPStatus(status int, err error) ErrorCode {
+	if err == nil {
+		err = fmt.Errorf("HTTP %d %s", status, http.StatusText(status))
+	}
+	code, ok := httpStatusCodes[status]
+	if !ok {
+		if status >= 400 && status < 500 {
+			code = InvalidInputCode
+		} else {
+			code = InternalCode
+		}
+	}
+	return NewCodedError(err, code)
+}
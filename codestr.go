@@ -0,0 +1,97 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gregwebs/errors"
+)
+
+// MaxCodeStrDepth is the maximum number of dot separated segments of a valid CodeStr.
+const MaxCodeStrDepth = 8
+
+var strictCodeStrs atomic.Bool
+
+// SetStrictCodeStrs makes NewCode and Child panic when the full CodeStr of the code is not valid according to CodeStr.Validate.
+// Codes end up in URLs, metrics labels, and log queries where odd characters cause trouble.
+// It applies to the codes created after it is called, so it must be called before the codes of the application are created.
+// The package variables of a package are initialized before its init functions run,
+// so an init function of the package that defines the codes is too late.
+// Call it from a package that is initialized earlier, such as a package imported by the package that defines the codes,
+// or create the codes lazily, for example with sync.OnceValue.
+func SetStrictCodeStrs(strict bool) {
+	strictCodeStrs.Store(strict)
+}
+
+// Validate checks that the CodeStr is safe to use as an identifier outside of Go.
+// Each dot separated segment must be non-empty and contain only lowercase ASCII letters, digits, '_' and '-',
+// starting with a letter.
+// There can be at most MaxCodeStrDepth segments.
+// Normalize fixes the case and surrounding space of a CodeStr.
+func (str CodeStr) Validate() error {
+	if str == "" {
+		return fmt.Errorf("invalid code: the code is empty")
+	}
+	segments := strings.Split(string(str), ".")
+	if len(segments) > MaxCodeStrDepth {
+		return fmt.Errorf("invalid code %q: %d segments is more than the maximum of %d", str, len(segments), MaxCodeStrDepth)
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("invalid code %q: empty segment", str)
+		}
+		if c := segment[0]; c < 'a' || c > 'z' {
+			return fmt.Errorf("invalid code %q: the segment %q must start with a lowercase letter", str, segment)
+		}
+		for _, c := range segment {
+			if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && c != '_' && c != '-' {
+				return fmt.Errorf("invalid code %q: the character %q is not allowed", str, c)
+			}
+		}
+	}
+	return nil
+}
+
+// Normalize lowercases the CodeStr and removes surrounding space, including around the dot separators.
+// This is useful for a CodeStr from outside the program, for example a query parameter, before looking it up.
+func (str CodeStr) Normalize() CodeStr {
+	segments := strings.Split(string(str), ".")
+	for i, segment := range segments {
+		segments[i] = strings.ToLower(strings.TrimSpace(segment))
+	}
+	return CodeStr(strings.Join(segments, "."))
+}
+
+// checkStrict panics if strict CodeStrs are enabled with SetStrictCodeStrs and the full CodeStr is not valid.
+func (code Code) checkStrict() {
+	if strictCodeStrs.Load() {
+		if err := code.CodeStr().Validate(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// AddAlias adds an alias for the code to the DefaultRegistry (see Registry.AddAlias).
+// Find then resolves the alias, for example "not_found" from another system or the old name of a renamed code.
+// Panic if the alias is already used for a different code.
+// Returns itself.
+func (code Code) AddAlias(alias CodeStr) Code {
+	if err := defaultRegistry.AddAlias(alias, code); err != nil {
+		panic(errors.Wrap(err, "AddAlias"))
+	}
+	return code
+}
@@ -0,0 +1,123 @@
+package errcode_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestCodeStrValidate(t *testing.T) {
+	for _, valid := range []errcode.CodeStr{"missing", "missing.gone", "quota.api_calls", "v2-input.x1"} {
+		if err := valid.Validate(); err != nil {
+			t.Errorf("expected %s to be valid: %v", valid, err)
+		}
+	}
+	for invalid, expected := range map[errcode.CodeStr]string{
+		"":                  "empty",
+		"missing..gone":     "empty segment",
+		"Missing":           "must start with a lowercase letter",
+		"1missing":          "must start with a lowercase letter",
+		"missing.gone!":     "not allowed",
+		"missing/gone":      "not allowed",
+		"a.b.c.d.e.f.g.h.i": "more than the maximum",
+	} {
+		if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error with %q for %q, got %v", expected, invalid, err)
+		}
+	}
+	if normalized := errcode.CodeStr(" Missing . Gone ").Normalize(); normalized != "missing.gone" {
+		t.Errorf("unexpected normalized code %q", normalized)
+	}
+	for _, code := range errcode.DefaultRegistry().Codes() {
+		if err := code.CodeStr().Validate(); err != nil {
+			t.Errorf("expected the registered code to be valid: %v", err)
+		}
+	}
+}
+
+func TestStrictCodeStrs(t *testing.T) {
+	errcode.SetStrictCodeStrs(true)
+	defer errcode.SetStrictCodeStrs(false)
+	assertPanics(t, func() { errcode.NewCode("Strict") })
+	assertPanics(t, func() { errcode.NotFoundCode.Child("strict space") })
+	if code := errcode.NotFoundCode.Child("missing.strict"); code.CodeStr() != "missing.strict" {
+		t.Errorf("unexpected code %s", code.CodeStr())
+	}
+}
+
+// initOrder records that the package variables, including codes, are initialized before the init functions.
+var initOrder []string
+
+var codeBeforeInit = func() errcode.Code {
+	initOrder = append(initOrder, "code")
+	return errcode.NotFoundCode.Child("missing.before_init")
+}()
+
+func init() {
+	initOrder = append(initOrder, "init")
+}
+
+// lazyStrictCode is created on first use, so strict CodeStrs enabled before then apply to it.
+var lazyStrictCode = sync.OnceValue(func() errcode.Code {
+	return errcode.NewCode("Lazy_Strict")
+})
+
+func TestStrictCodeStrsOrder(t *testing.T) {
+	if order := strings.Join(initOrder, ","); order != "code,init" {
+		t.Errorf("expected the code %s to be created before init, got %s", codeBeforeInit.CodeStr(), order)
+	}
+	errcode.SetStrictCodeStrs(true)
+	defer errcode.SetStrictCodeStrs(false)
+	assertPanics(t, func() { lazyStrictCode() })
+}
+
+func assertPanics(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	f()
+}
+
+func FuzzCodeStrNormalize(f *testing.F) {
+	for _, seed := range []string{"missing.gone", " Missing . Gone ", "", "a..b", "ÄÖ.x"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		normalized := errcode.CodeStr(s).Normalize()
+		if normalized.Normalize() != normalized {
+			t.Errorf("Normalize is not idempotent for %q", s)
+		}
+		if errcode.CodeStr(s).Validate() == nil && normalized != errcode.CodeStr(s) {
+			t.Errorf("a valid code %q was changed by Normalize to %q", s, normalized)
+		}
+	})
+}
+
+func TestFindAlias(t *testing.T) {
+	legacyCode := errcode.NotFoundCode.Child("missing.aliased").AddAlias("not_found_legacy").AddAlias("OldMissing")
+	registry := errcode.DefaultRegistry()
+	for _, codeStr := range []errcode.CodeStr{"missing.aliased", " Missing.Aliased", "not_found_legacy", "NOT_FOUND_LEGACY", "oldmissing"} {
+		if code, ok := registry.Find(codeStr); !ok || code.CodeStr() != legacyCode.CodeStr() {
+			t.Errorf("expected %q to find the code, got %v", codeStr, code.CodeStr())
+		}
+	}
+	if _, ok := registry.Lookup("not_found_legacy"); ok {
+		t.Error("expected Lookup not to resolve aliases")
+	}
+	if _, ok := registry.Find("not_found_unknown"); ok {
+		t.Error("expected no code")
+	}
+	legacyCode.AddAlias("not_found_legacy")
+	assertPanics(t, func() { errcode.GoneCode.AddAlias("not_found_legacy") })
+	assertPanics(t, func() { errcode.GoneCode.AddAlias("Missing") })
+
+	remote := errcode.NewRemoteErr(errcode.JSONFormat{Code: "not_found_legacy"}, nil)
+	if remote.Code().CodeStr() != legacyCode.CodeStr() || remote.Code().HTTPCode() != 404 {
+		t.Errorf("expected the remote code to resolve the alias, got %s", remote.Code().CodeStr())
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gregwebs/errors"
+)
+
+// Annotation is a message added by wrapping an error.
+// Count is the number of consecutive times the message was added.
+// The message of a repeated sequence of annotations joins them with ": ".
+type Annotation struct {
+	Msg   string
+	Count int
+}
+
+// maxRepeatedAnnotations is the longest sequence of annotations that Compact looks for repeats of.
+const maxRepeatedAnnotations = 8
+
+// CompactErr is a flattened representation of a chain of annotations on an error.
+// It is constructed by Compact.
+// Err is the first error in the original chain that could not be compacted:
+// normally this is the ErrorCode.
+// Operations are the operations of the compacted OpErrCode layers as OperationChain gives them,
+// and UserMsg is the outermost message of the compacted UserMsgErrCode layers.
+type CompactErr struct {
+	Annotations []Annotation
+	Err         error
+	Operations  []string
+	UserMsg     string
+	stack       errors.StackTracer
+}
+
+// Compact flattens the annotations (wrapping with a message) of an error into a CompactErr.
+// Consecutive repeats of an annotation, or of a sequence of annotations, are collapsed into one Annotation with a count.
+// This is useful when the same error is wrapped many times, for example in a retry loop.
+// Compaction makes the chain faster to traverse and shortens the Error() output.
+//
+// The wrappers of this package are compacted as well: Wrap, Wrapf, Wraps, Op, and WithUserMsg.
+// Their messages are annotations, and the operations and user message are kept by the CompactErr.
+// An existing CompactErr in the chain is merged.
+//
+// Compaction stops at the first other error that is an ErrorCode, an error group,
+// or has data that would be lost (HasClientData, HasOperation, HasUserMsg).
+// The deepest stack trace of the compacted annotations is retained.
+// If nothing is repeated, the error is returned unchanged.
+//
+// The optional threshold only compacts when the wrapped chain has more than that many errors.
+// This allows compacting on every wrap, with compaction only doing work once the chain is deep:
+//
+//	err = errcode.Compact(errors.Wrap(err, "retry"), 16)
+func Compact(err error, threshold ...int) error {
+	if err == nil {
+		return nil
+	}
+	if len(threshold) > 0 && chainDepth(err, threshold[0]+1) <= threshold[0] {
+		return err
+	}
+	var annotations []Annotation
+	var ops []string
+	var userMsg string
+	var stack errors.StackTracer
+	merged := false
+	addOp := func(op string) {
+		if last := len(ops) - 1; last < 0 || ops[last] != op {
+			ops = append(ops, op)
+		}
+	}
+	current := err
+	for depth := 0; ; depth++ {
+		if compactErr, ok := current.(CompactErr); ok {
+			annotations = append(annotations, compactErr.Annotations...)
+			for _, op := range compactErr.Operations {
+				addOp(op)
+			}
+			if userMsg == "" {
+				userMsg = compactErr.UserMsg
+			}
+			if compactErr.stack != nil {
+				stack = compactErr.stack
+			}
+			// flatten a CompactErr that has been wrapped again
+			merged = merged || depth > 0
+			current = compactErr.Err
+			continue
+		}
+		inner := errors.Unwrap(current)
+		if inner == nil || !isCompactable(current) {
+			break
+		}
+		msg, ok := annotationMsg(current, inner)
+		if !ok {
+			break
+		}
+		switch layer := current.(type) {
+		case OpErrCode:
+			addOp(layer.Operation)
+		case UserMsgErrCode:
+			if userMsg == "" {
+				userMsg = layer.Msg
+			}
+		}
+		if tracer := stackTracerOf(current); tracer != nil {
+			stack = tracer
+		}
+		if msg != "" {
+			annotations = append(annotations, Annotation{Msg: msg, Count: 1})
+		}
+		current = inner
+	}
+	collapsed := collapseAnnotations(annotations)
+	if !merged && len(collapsed) == len(annotations) {
+		return err
+	}
+	if HasStack(current) {
+		stack = nil
+	}
+	return CompactErr{Annotations: collapsed, Err: current, Operations: ops, UserMsg: userMsg, stack: stack}
+}
+
+// chainDepth counts the errors of the wrapped chain, stopping at max.
+func chainDepth(err error, max int) int {
+	depth := 0
+	for unwrapped := err; unwrapped != nil && depth < max; unwrapped = errors.Unwrap(unwrapped) {
+		depth++
+	}
+	return depth
+}
+
+func isCompactable(err error) bool {
+	switch err.(type) {
+	case messageWrapper, OpErrCode, UserMsgErrCode:
+		return true
+	case ErrorCode, HasClientData, HasOperation, HasUserMsg, errors.ErrorGroup:
+		return false
+	}
+	return errors.Errors(err) == nil
+}
+
+// collapseAnnotations collapses consecutive repeats of an annotation or a sequence of annotations.
+// At each position, the sequence whose repeats cover the most annotations is collapsed.
+func collapseAnnotations(annotations []Annotation) []Annotation {
+	annotations = mergeAnnotations(annotations)
+	var collapsed []Annotation
+	for i := 0; i < len(annotations); {
+		size, count := 1, 1
+		for n := 2; n <= maxRepeatedAnnotations && i+2*n <= len(annotations); n++ {
+			if repeats := countRepeats(annotations[i:], n); repeats > 1 && repeats*n > size*count {
+				size, count = n, repeats
+			}
+		}
+		if count == 1 {
+			collapsed = append(collapsed, annotations[i])
+			i++
+			continue
+		}
+		msgs := make([]string, size)
+		for j, annotation := range annotations[i : i+size] {
+			msgs[j] = annotation.String()
+		}
+		collapsed = append(collapsed, Annotation{Msg: strings.Join(msgs, ": "), Count: count})
+		i += size * count
+	}
+	return mergeAnnotations(collapsed)
+}
+
+// mergeAnnotations adds the counts of consecutive annotations with the same message.
+func mergeAnnotations(annotations []Annotation) []Annotation {
+	var merged []Annotation
+	for _, annotation := range annotations {
+		if last := len(merged) - 1; last >= 0 && merged[last].Msg == annotation.Msg {
+			merged[last].Count += annotation.Count
+		} else {
+			merged = append(merged, annotation)
+		}
+	}
+	return merged
+}
+
+// countRepeats counts the consecutive repeats of the first size annotations.
+func countRepeats(annotations []Annotation, size int) int {
+	repeats := 1
+	for start := size; start+size <= len(annotations); start += size {
+		for j := 0; j < size; j++ {
+			if annotations[start+j] != annotations[j] {
+				return repeats
+			}
+		}
+		repeats++
+	}
+	return repeats
+}
+
+// annotationMsg gives the message that a layer adds to the inner error.
+func annotationMsg(layer error, inner error) (string, bool) {
+	if noUnwrap, ok := layer.(interface{ ErrorNoUnwrap() string }); ok {
+		return noUnwrap.ErrorNoUnwrap(), true
+	}
+	msg := layer.Error()
+	innerMsg := inner.Error()
+	if msg == innerMsg {
+		return "", true
+	}
+	if prefix, found := strings.CutSuffix(msg, ": "+innerMsg); found {
+		return prefix, true
+	}
+	return "", false
+}
+
+// String gives the message, followed by the count if it is repeated.
+func (a Annotation) String() string {
+	if a.Count > 1 {
+		return fmt.Sprintf("%s (x%d)", a.Msg, a.Count)
+	}
+	return a.Msg
+}
+
+// Error gives the annotations followed by the underlying Err Error.
+// A repeated annotation shows its count.
+func (e CompactErr) Error() string {
+	var b strings.Builder
+	for _, annotation := range e.Annotations {
+		b.WriteString(annotation.String())
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// GetOperation satisfies the HasOperation interface.
+// Without compacted operations it gives the operation of Err.
+func (e CompactErr) GetOperation() string {
+	if len(e.Operations) > 0 {
+		return e.Operations[0]
+	}
+	return Operation(e.Err)
+}
+
+// GetUserMsg satisfies the [HasUserMsg] interface.
+// Without a compacted user message it gives the user message attached to Err.
+func (e CompactErr) GetUserMsg() string {
+	if e.UserMsg != "" {
+		return e.UserMsg
+	}
+	return attachedUserMsg(e.Err)
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e CompactErr) Unwrap() error {
+	return e.Err
+}
+
+// StackTrace gives the stack trace of the compacted annotations or of Err.
+func (e CompactErr) StackTrace() errors.StackTrace {
+	if e.stack != nil {
+		return e.stack.StackTrace()
+	}
+	return StackTrace(e.Err)
+}
+
+// HasStack satisfies the errors package StackTraceAware interface
+func (e CompactErr) HasStack() bool {
+	return e.stack != nil || HasStack(e.Err)
+}
+
+var _ unwrapError = (*CompactErr)(nil)        // assert implements interface
+var _ errors.StackTracer = (*CompactErr)(nil) // assert implements interface
+var _ HasOperation = (*CompactErr)(nil)       // assert implements interface
+var _ HasUserMsg = (*CompactErr)(nil)         // assert implements interface
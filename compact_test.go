@@ -0,0 +1,111 @@
+package errcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestCompact(t *testing.T) {
+	if errcode.Compact(nil) != nil {
+		t.Errorf("expected nil")
+	}
+	notFound := errcode.NewNotFoundErr(errors.New("no item"))
+	if errcode.Compact(notFound) != notFound {
+		t.Errorf("expected no change")
+	}
+	once := errors.Wrap(notFound, "retry")
+	if errcode.Compact(once) != once {
+		t.Errorf("expected no change")
+	}
+
+	var err error = notFound
+	for i := 0; i < 30; i++ {
+		err = errors.Wrap(err, "retry")
+	}
+	err = errors.Wrap(err, "fetch")
+	compacted := errcode.Compact(err)
+	ErrorEquals(t, compacted, "fetch: retry (x30): no item")
+	if before, after := chainDepth(err), chainDepth(compacted); before != 2*31+chainDepth(notFound) || after != 1+chainDepth(notFound) {
+		t.Errorf("expected the chain depth to go from 62 to 1 above the ErrorCode, got %d and %d", before, after)
+	}
+	compactErr, ok := compacted.(errcode.CompactErr)
+	if !ok {
+		t.Fatalf("expected CompactErr, got %T", compacted)
+	}
+	if compactErr.Err != notFound {
+		t.Errorf("expected compaction to stop at the ErrorCode")
+	}
+	AssertCode(t, errcode.CodeChain(compacted), errcode.NotFoundCode.CodeStr())
+	if !errors.Is(compacted, notFound) {
+		t.Errorf("expected to unwrap to the ErrorCode")
+	}
+	if errcode.StackTrace(compacted) == nil {
+		t.Errorf("expected the stack trace to be kept")
+	}
+}
+
+func TestCompactErrcodeWrappers(t *testing.T) {
+	notFound := errcode.NewNotFoundErr(errors.New("no item"))
+	var errCode errcode.ErrorCode = notFound
+	for i := 0; i < 30; i++ {
+		errCode = errcode.Op("items.fetch")(errcode.WithUserMsg("Try again", errcode.Wrap(errCode, "retry")))
+	}
+	errCode = errcode.WithUserMsg("The item is gone", errCode)
+	compacted := errcode.Compact(errCode)
+	ErrorEquals(t, errCode, "The item is gone: "+strings.Repeat("items.fetch: Try again: retry: ", 30)+"no item")
+	ErrorEquals(t, compacted, "The item is gone: items.fetch: Try again: retry (x30): no item")
+	if before, after := chainDepth(errCode), chainDepth(compacted); before != 91+chainDepth(notFound) || after != 1+chainDepth(notFound) {
+		t.Errorf("expected the chain depth to go from 91 to 1 above the ErrorCode, got %d and %d", before, after)
+	}
+	AssertCode(t, errcode.CodeChain(compacted), errcode.NotFoundCode.CodeStr())
+	if op := errcode.Operation(compacted); op != "items.fetch" {
+		t.Errorf("expected the operation to be kept, got %q", op)
+	}
+	if ops := errcode.OperationChain(compacted); len(ops) != 1 || ops[0] != "items.fetch" {
+		t.Errorf("expected the operation chain to be kept, got %v", ops)
+	}
+	if msg := errcode.GetUserMsg(compacted); msg != "The item is gone" {
+		t.Errorf("expected the outermost user message, got %q", msg)
+	}
+
+	ops := errcode.Op("items.get")(errcode.Op("items.fetch")(errcode.Op("items.get")(notFound)))
+	if errcode.Compact(ops) != errcode.ErrorCode(ops) {
+		t.Errorf("expected no change for operations that are not repeated")
+	}
+}
+
+func TestCompactThreshold(t *testing.T) {
+	notFound := errcode.NewNotFoundErr(errors.New("no item"))
+	var err error = notFound
+	for i := 0; i < 5; i++ {
+		err = errors.Wrap(err, "retry")
+	}
+	if errcode.Compact(err, 20) != err {
+		t.Errorf("expected no change below the threshold")
+	}
+
+	err = notFound
+	maxDepth := 0
+	for i := 0; i < 50; i++ {
+		err = errcode.Compact(errors.Wrap(err, "retry"), 8)
+		if depth := chainDepth(err); depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	if maxDepth > 8 {
+		t.Errorf("expected the chain depth to stay at the threshold of 8, got %d", maxDepth)
+	}
+	ErrorEquals(t, errcode.Compact(err), "retry (x50): no item")
+}
+
+// chainDepth counts the errors of the wrapped chain.
+func chainDepth(err error) int {
+	depth := 0
+	for ; err != nil; err = errors.Unwrap(err) {
+		depth++
+	}
+	return depth
+}
@@ -0,0 +1,207 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gregwebs/errors"
+)
+
+var (
+	// ConfigCode indicates the configuration of the service is not usable.
+	// These errors normally occur at startup: see ConfigValidator.
+	// This is mapped to HTTP 500.
+	ConfigCode = NewCode("config").SetHTTP(http.StatusInternalServerError)
+
+	// ConfigMissingCode indicates a required setting, such as an environment variable, is not set.
+	ConfigMissingCode = ConfigCode.Child("config.missing").
+				SetRemediation("Set the missing value in the environment or configuration file")
+
+	// ConfigInvalidCode indicates a setting has a value that is not valid.
+	ConfigInvalidCode = ConfigCode.Child("config.invalid").
+				SetRemediation("Correct the value in the environment or configuration file")
+
+	// ConfigUnreachableCode indicates a dependency, such as a database, cannot be reached with the configured settings.
+	ConfigUnreachableCode = ConfigCode.Child("config.unreachable").
+				SetRemediation("Check that the dependency is running and that its address and credentials are correct")
+)
+
+// HasRemediation is used to give a remediation for a specific error.
+// A remediation explains how the error can be resolved.
+type HasRemediation interface {
+	GetRemediation() string
+}
+
+// GetRemediation gives the remediation of an error.
+// It checks recursively for the [HasRemediation] interface.
+// If none is found, the Remediation of the code of an ErrorCode is given.
+func GetRemediation(v interface{}) string {
+	for current := v; current != nil; {
+		if has, ok := current.(HasRemediation); ok {
+			if remediation := has.GetRemediation(); remediation != "" {
+				return remediation
+			}
+		}
+		un, ok := current.(unwrapError)
+		if !ok {
+			break
+		}
+		current = un.Unwrap()
+	}
+	if errCode, ok := v.(ErrorCode); ok {
+		return errCode.Code().Remediation()
+	}
+	return ""
+}
+
+// ConfigErr is an error with a configuration setting.
+// Key is the name of the setting, for example an environment variable or a dependency.
+// Hint is an optional remediation that is specific to this setting.
+type ConfigErr struct {
+	CodedError
+	Key  string
+	Hint string
+}
+
+// NewMissingEnvErr creates a ConfigErr with ConfigMissingCode for an environment variable that is not set.
+func NewMissingEnvErr(name string) ConfigErr {
+	return ConfigErr{
+		CodedError: NewCodedError(fmt.Errorf("environment variable %s is not set", name), ConfigMissingCode),
+		Key:        name,
+	}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e ConfigErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "ConfigErr{CodedError: %#v, Key: %#v, Hint: %#v}", e.CodedError, e.Key, e.Hint)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// NewInvalidConfigErr creates a ConfigErr with ConfigInvalidCode.
+// If the error is already an ErrorCode it will use that code.
+func NewInvalidConfigErr(key string, err error) ConfigErr {
+	return ConfigErr{
+		CodedError: NewCodedError(errors.WithMessage(err, "invalid "+key), ConfigInvalidCode),
+		Key:        key,
+	}
+}
+
+// NewUnreachableErr creates a ConfigErr with ConfigUnreachableCode for a dependency that could not be reached.
+// If the error is already an ErrorCode it will use that code.
+func NewUnreachableErr(dependency string, err error) ConfigErr {
+	return ConfigErr{
+		CodedError: NewCodedError(errors.WithMessage(err, "unreachable "+dependency), ConfigUnreachableCode),
+		Key:        dependency,
+	}
+}
+
+// WithHint gives the ConfigErr a remediation that is specific to its setting.
+func (e ConfigErr) WithHint(hint string) ConfigErr {
+	e.Hint = hint
+	return e
+}
+
+// GetRemediation satisfies the [HasRemediation] interface.
+// The Hint is preferred over the remediation of the code.
+func (e ConfigErr) GetRemediation() string {
+	if e.Hint != "" {
+		return e.Hint
+	}
+	return e.GetCode.Remediation()
+}
+
+var _ ErrorCode = (*ConfigErr)(nil)      // assert implements interface
+var _ HasRemediation = (*ConfigErr)(nil) // assert implements interface
+
+// ConfigValidator collects every configuration problem at startup
+// so that they can be reported and fixed together rather than one per restart.
+//
+//	v := errcode.NewConfigValidator()
+//	dbURL := v.RequireEnv("DATABASE_URL")
+//	port := v.Check("PORT", os.Getenv("PORT"), validatePort)
+//	v.CheckReachable("database", func() error { return ping(dbURL) })
+//	if err := v.Err(); err != nil {
+//		fmt.Fprint(os.Stderr, errcode.RenderText(err))
+//		os.Exit(1)
+//	}
+type ConfigValidator struct {
+	errs []ErrorCode
+}
+
+// NewConfigValidator creates a ConfigValidator without any problems.
+func NewConfigValidator() *ConfigValidator {
+	return &ConfigValidator{}
+}
+
+// Add records a problem.
+// A nil error is ignored and an error without an ErrorCode is given ConfigInvalidCode.
+func (v *ConfigValidator) Add(err error) {
+	if err == nil {
+		return
+	}
+	errCode := CodeChain(err)
+	if errCode == nil {
+		errCode = NewCodedError(err, ConfigInvalidCode)
+	}
+	v.errs = append(v.errs, errCode)
+}
+
+// RequireEnv gives the value of an environment variable and records a problem if it is not set or empty.
+func (v *ConfigValidator) RequireEnv(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		v.Add(NewMissingEnvErr(name))
+	}
+	return value
+}
+
+// Check validates a setting and records a problem if the check fails.
+// An error without an ErrorCode (as found by CodeChain) is converted with NewInvalidConfigErr.
+// The value is returned for convenient assignment.
+func (v *ConfigValidator) Check(key string, value string, check func(string) error) string {
+	if err := check(value); err != nil {
+		if CodeChain(err) == nil {
+			err = NewInvalidConfigErr(key, err)
+		}
+		v.Add(err)
+	}
+	return value
+}
+
+// CheckReachable records a problem if a dependency cannot be reached.
+// An error without an ErrorCode (as found by CodeChain) is converted with NewUnreachableErr.
+func (v *ConfigValidator) CheckReachable(dependency string, check func() error) {
+	if err := check(); err != nil {
+		if CodeChain(err) == nil {
+			err = NewUnreachableErr(dependency, err)
+		}
+		v.Add(err)
+	}
+}
+
+// Err combines all the problems with Combine.
+// It is nil if there are no problems.
+func (v *ConfigValidator) Err() ErrorCode {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return Combine(v.errs[0], v.errs[1:]...)
+}
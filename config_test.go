@@ -0,0 +1,60 @@
+package errcode_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestConfigValidator(t *testing.T) {
+	t.Setenv("ERRCODE_TEST_SET", "value")
+	v := errcode.NewConfigValidator()
+	if v.Err() != nil {
+		t.Fatal("expected no problems")
+	}
+	if value := v.RequireEnv("ERRCODE_TEST_SET"); value != "value" {
+		t.Errorf("unexpected value %s", value)
+	}
+	v.RequireEnv("ERRCODE_TEST_UNSET")
+	v.Check("PORT", "eighty", func(string) error { return errors.New("not a number") })
+	v.Check("HOST", "localhost", func(string) error { return nil })
+	v.CheckReachable("database", func() error {
+		return errcode.NewUnreachableErr("database", errors.New("connection refused")).WithHint("Start the database with docker compose up")
+	})
+
+	err := v.Err()
+	codes := errcode.ErrorCodes(err)
+	if len(codes) != 3 || !errcode.IsServerError(err) {
+		t.Fatalf("unexpected codes %v", codes)
+	}
+	expected := strings.Join([]string{
+		"3 errors:",
+		"  [config.missing] environment variable ERRCODE_TEST_UNSET is not set",
+		"    fix: Set the missing value in the environment or configuration file",
+		"  [config.invalid] invalid PORT: not a number",
+		"    fix: Correct the value in the environment or configuration file",
+		"  [config.unreachable] unreachable database: connection refused",
+		"    fix: Start the database with docker compose up",
+		"",
+	}, "\n")
+	if text := errcode.RenderText(err); text != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, text)
+	}
+}
+
+func TestConfigValidatorWrapped(t *testing.T) {
+	v := errcode.NewConfigValidator()
+	v.Check("REGION", "mars", func(string) error {
+		return fmt.Errorf("lookup: %w", errcode.NewNotFoundErr(errors.New("no region mars")))
+	})
+	v.CheckReachable("cache", func() error {
+		return errors.Wrap(errcode.NewUnavailableErr(errors.New("cache is restarting")), "ping")
+	})
+	codes := errcode.ErrorCodes(v.Err())
+	if len(codes) != 2 || codes[0].Code() != errcode.NotFoundCode || codes[1].Code() != errcode.UnavailableCode {
+		t.Fatalf("expected the codes of the wrapped ErrorCodes, got %v", codes)
+	}
+}
@@ -0,0 +1,246 @@
+// Package connect attaches Connect (connectrpc.com) codes to the standard error codes.
+// It also provides an interceptor that converts between ErrorCodes and *connect.Error.
+//
+// The JSONFormat of an ErrorCode is sent as a google.protobuf.Struct error detail.
+// This allows a client to reconstruct the ErrorCode with its data.
+//
+// The init function performs the mapping and is reproduced here:
+//
+//	SetCode(errcode.InternalCode, connect.CodeInternal)
+//	SetCode(errcode.InvalidInputCode, connect.CodeInvalidArgument)
+//	SetCode(errcode.NotFoundCode, connect.CodeNotFound)
+//	SetCode(errcode.StateCode, connect.CodeFailedPrecondition)
+//	SetCode(errcode.ForbiddenCode, connect.CodePermissionDenied)
+//	SetCode(errcode.NotAuthenticatedCode, connect.CodeUnauthenticated)
+//	SetCode(errcode.AlreadyExistsCode, connect.CodeAlreadyExists)
+//	SetCode(errcode.OutOfRangeCode, connect.CodeOutOfRange)
+//	SetCode(errcode.UnimplementedCode, connect.CodeUnimplemented)
+//	SetCode(errcode.UnavailableCode, connect.CodeUnavailable)
+//	SetCode(errcode.TimeoutCode, connect.CodeDeadlineExceeded)
+//	SetCode(errcode.CanceledCode, connect.CodeCanceled)
+//	SetCode(errcode.TooManyRequestsCode, connect.CodeResourceExhausted)
+//	SetCode(errcode.QuotaCode, connect.CodeResourceExhausted)
+//	SetCode(errcode.ConflictCode, connect.CodeAborted)
+package connect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"connectrpc.com/connect"
+	"github.com/gregwebs/errcode"
+	pkgerrors "github.com/gregwebs/errors"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var connectMetaData = make(errcode.MetaData)
+
+var (
+	fromConnectMu sync.RWMutex
+	fromConnect   = make(map[connect.Code]errcode.Code)
+)
+
+// SetCode adds a Connect code to the meta data of a code.
+// The code can be retrieved with GetCode.
+// The first code set for a Connect code is used when converting a Connect error without details to an ErrorCode.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func SetCode(code errcode.Code, connectCode connect.Code) errcode.Code {
+	if err := code.SetMetaData(connectMetaData, connectCode); err != nil {
+		panic(pkgerrors.Wrap(err, "SetConnect"))
+	}
+	fromConnectMu.Lock()
+	defer fromConnectMu.Unlock()
+	if _, ok := fromConnect[connectCode]; !ok {
+		fromConnect[connectCode] = code
+	}
+	return code
+}
+
+// WithCode gives a function that sets the Connect code with SetCode.
+// This is used in the With field of an errcode.CodeSpec.
+func WithCode(connectCode connect.Code) func(errcode.Code) errcode.Code {
+	return func(code errcode.Code) errcode.Code {
+		return SetCode(code, connectCode)
+	}
+}
+
+// GetCode retrieves the Connect code for a code or its first ancestor with a Connect code.
+// If none are specified, it defaults to Unknown.
+func GetCode(code errcode.Code) connect.Code {
+	connectCode := code.MetaDataFromAncestors(connectMetaData)
+	if connectCode == nil {
+		return connect.CodeUnknown
+	}
+	return connectCode.(connect.Code)
+}
+
+// ToConnectError converts an error with an ErrorCode (found with CodeChain) to a *connect.Error.
+// The JSONFormat is attached as a detail.
+// An error that is already a *connect.Error or has no ErrorCode is returned unchanged.
+func ToConnectError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return err
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		return err
+	}
+	connectErr = connect.NewError(GetCode(errCode.Code()), err)
+	if detail, detailErr := jsonFormatDetail(errcode.NewJSONFormat(errCode)); detailErr == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
+
+// FromConnectError converts a *connect.Error to an ErrorCode.
+// If the error has a JSONFormat detail, an errcode.RemoteErr is returned.
+// Otherwise the Connect code is mapped back to a code set with SetCode and marked with errcode.WithRemote.
+// An error that is not a *connect.Error is returned unchanged.
+func FromConnectError(err error) error {
+	var connectErr *connect.Error
+	if err == nil || !errors.As(err, &connectErr) {
+		return err
+	}
+	if format, ok := DetailJSONFormat(connectErr); ok {
+		return errcode.NewRemoteErr(format, err)
+	}
+	fromConnectMu.RLock()
+	code, ok := fromConnect[connectErr.Code()]
+	fromConnectMu.RUnlock()
+	if !ok {
+		return err
+	}
+	return errcode.WithRemote(errcode.NewCodedError(err, code))
+}
+
+// DetailJSONFormat finds the JSONFormat detail of a *connect.Error.
+func DetailJSONFormat(connectErr *connect.Error) (errcode.JSONFormat, bool) {
+	var format errcode.JSONFormat
+	for _, detail := range connectErr.Details() {
+		value, err := detail.Value()
+		if err != nil {
+			continue
+		}
+		st, ok := value.(*structpb.Struct)
+		if !ok {
+			continue
+		}
+		bytes, err := st.MarshalJSON()
+		if err != nil {
+			continue
+		}
+		if err := json.Unmarshal(bytes, &format); err != nil || format.Code == "" {
+			continue
+		}
+		return format, true
+	}
+	return format, false
+}
+
+func jsonFormatDetail(format errcode.JSONFormat) (*connect.ErrorDetail, error) {
+	bytes, err := json.Marshal(format)
+	if err != nil {
+		return nil, err
+	}
+	var st structpb.Struct
+	if err := st.UnmarshalJSON(bytes); err != nil {
+		return nil, err
+	}
+	return connect.NewErrorDetail(&st)
+}
+
+// Interceptor converts errors returned by handlers to *connect.Error with ToConnectError.
+// On the client side it converts received errors to ErrorCodes with FromConnectError.
+type Interceptor struct{}
+
+var _ connect.Interceptor = (*Interceptor)(nil) // assert implements interface
+
+// NewInterceptor constructs an Interceptor.
+// Use it with connect.WithInterceptors for both handlers and clients.
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+// WrapUnary satisfies the connect.Interceptor interface
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		res, err := next(ctx, req)
+		if err == nil {
+			return res, nil
+		}
+		if req.Spec().IsClient {
+			return res, FromConnectError(err)
+		}
+		return res, ToConnectError(err)
+	}
+}
+
+// WrapStreamingClient satisfies the connect.Interceptor interface
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		return streamingClientConn{StreamingClientConn: next(ctx, spec)}
+	}
+}
+
+// WrapStreamingHandler satisfies the connect.Interceptor interface
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return ToConnectError(next(ctx, conn))
+	}
+}
+
+type streamingClientConn struct {
+	connect.StreamingClientConn
+}
+
+func (conn streamingClientConn) Send(msg any) error {
+	return fromConnectErrorNotEOF(conn.StreamingClientConn.Send(msg))
+}
+
+func (conn streamingClientConn) Receive(msg any) error {
+	return fromConnectErrorNotEOF(conn.StreamingClientConn.Receive(msg))
+}
+
+func (conn streamingClientConn) CloseResponse() error {
+	return fromConnectErrorNotEOF(conn.StreamingClientConn.CloseResponse())
+}
+
+func fromConnectErrorNotEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+	return FromConnectError(err)
+}
+
+func init() {
+	SetCode(errcode.InternalCode, connect.CodeInternal)
+	SetCode(errcode.InvalidInputCode, connect.CodeInvalidArgument)
+	SetCode(errcode.NotFoundCode, connect.CodeNotFound)
+	SetCode(errcode.StateCode, connect.CodeFailedPrecondition)
+	SetCode(errcode.ForbiddenCode, connect.CodePermissionDenied)
+	SetCode(errcode.NotAuthenticatedCode, connect.CodeUnauthenticated)
+	SetCode(errcode.AlreadyExistsCode, connect.CodeAlreadyExists)
+	SetCode(errcode.OutOfRangeCode, connect.CodeOutOfRange)
+	SetCode(errcode.UnimplementedCode, connect.CodeUnimplemented)
+	SetCode(errcode.UnavailableCode, connect.CodeUnavailable)
+	SetCode(errcode.TimeoutCode, connect.CodeDeadlineExceeded)
+	SetCode(errcode.CanceledCode, connect.CodeCanceled)
+	SetCode(errcode.TooManyRequestsCode, connect.CodeResourceExhausted)
+	SetCode(errcode.QuotaCode, connect.CodeResourceExhausted)
+	SetCode(errcode.ConflictCode, connect.CodeAborted)
+	errcode.RegisterDocMapping("connect", func(code errcode.Code) string {
+		// a code without a mapping is left out rather than documented with the default
+		if code.MetaDataFromAncestors(connectMetaData) == nil {
+			return ""
+		}
+		return GetCode(code).String()
+	})
+}
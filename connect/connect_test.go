@@ -0,0 +1,81 @@
+package connect_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/gregwebs/errcode"
+	errconnect "github.com/gregwebs/errcode/connect"
+	"github.com/gregwebs/errors"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const procedure = "/errcode.test.v1.TestService/Call"
+
+func TestInterceptor(t *testing.T) {
+	interceptors := connect.WithInterceptors(errconnect.NewInterceptor())
+	mux := http.NewServeMux()
+	mux.Handle(procedure, connect.NewUnaryHandler(procedure,
+		func(ctx context.Context, req *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+			return nil, errcode.Op("call").AddTo(errcode.NewNotFoundErr(errors.New("no item")))
+		},
+		interceptors,
+	))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+procedure, interceptors)
+	_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if connect.CodeOf(err) != connect.CodeNotFound {
+		t.Errorf("expected not found, got %v", connect.CodeOf(err))
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		t.Fatalf("expected an ErrorCode, got %v", err)
+	}
+	if errCode.Code().CodeStr() != errcode.NotFoundCode.CodeStr() {
+		t.Errorf("expected not found code, got %v", errCode.Code().CodeStr())
+	}
+	if op := errcode.Operation(errCode); op != "call" {
+		t.Errorf("expected operation call, got %v", op)
+	}
+}
+
+func TestFromConnectError(t *testing.T) {
+	err := errconnect.FromConnectError(connect.NewError(connect.CodePermissionDenied, errors.New("denied")))
+	errCode, ok := err.(errcode.ErrorCode)
+	if !ok {
+		t.Fatalf("expected an ErrorCode, got %v", err)
+	}
+	if errCode.Code() != errcode.ForbiddenCode {
+		t.Errorf("expected forbidden code, got %v", errCode.Code().CodeStr())
+	}
+
+	plain := errors.New("plain")
+	if errconnect.FromConnectError(plain) != plain {
+		t.Errorf("expected the same error")
+	}
+	if errconnect.ToConnectError(plain) != plain {
+		t.Errorf("expected the same error")
+	}
+}
+
+func TestGetCode(t *testing.T) {
+	if code := errconnect.GetCode(errcode.TimeoutGatewayCode); code != connect.CodeDeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", code)
+	}
+	for _, quotaCode := range []errcode.Code{errcode.QuotaCode, errcode.QuotaStorageCode, errcode.QuotaRateCode} {
+		if code := errconnect.GetCode(quotaCode); code != connect.CodeResourceExhausted {
+			t.Errorf("expected resource exhausted for %s, got %v", quotaCode.CodeStr(), code)
+		}
+	}
+	if code := errconnect.GetCode(errcode.AuthCode); code != connect.CodeUnknown {
+		t.Errorf("expected unknown, got %v", code)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/gregwebs/errcode/connect
+
+go 1.21.9
+
+require (
+	connectrpc.com/connect v1.16.1
+	github.com/gregwebs/errcode v0.11.0
+	github.com/gregwebs/errors v1.5.0
+	google.golang.org/protobuf v1.33.0
+)
+
+replace github.com/gregwebs/errcode => ../
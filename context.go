@@ -0,0 +1,80 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import "context"
+
+type decoratorsCtxKey struct{}
+
+// CtxWithDecorators stores Decorators in the context to be applied by [WrapCtx].
+// This replaces any Decorators already in the context.
+func CtxWithDecorators(ctx context.Context, d Decorators) context.Context {
+	return context.WithValue(ctx, decoratorsCtxKey{}, d)
+}
+
+// DecoratorsFromCtx gives the Decorators stored in the context.
+// The zero value is returned if there are none.
+func DecoratorsFromCtx(ctx context.Context) Decorators {
+	d, _ := ctx.Value(decoratorsCtxKey{}).(Decorators)
+	return d
+}
+
+// CtxWithOp stores an operation in the context to be applied by [WrapCtx].
+// This avoids passing the operation string through a deep call stack:
+// it is set once, for example by a request handler.
+// The user message and tags already in the context are kept.
+//
+//	ctx = errcode.CtxWithOp(ctx, "orders.create")
+func CtxWithOp(ctx context.Context, op string) context.Context {
+	d := DecoratorsFromCtx(ctx)
+	d.Op = op
+	return CtxWithDecorators(ctx, d)
+}
+
+// OpFromCtx gives the operation stored in the context by [CtxWithOp].
+// Otherwise it will return the zero value (empty) string.
+func OpFromCtx(ctx context.Context) string {
+	return DecoratorsFromCtx(ctx).Op
+}
+
+// CtxWithUserMsg stores a user message in the context to be applied by [WrapCtx].
+// The operation and tags already in the context are kept.
+func CtxWithUserMsg(ctx context.Context, msg string) context.Context {
+	d := DecoratorsFromCtx(ctx)
+	d.UserMsg = msg
+	return CtxWithDecorators(ctx, d)
+}
+
+// WrapCtx applies the Decorators of the context to the ErrorCode.
+// A user message is only applied when the error does not already have one,
+// so that a more specific message given closer to the error is preserved.
+// The request ID of the context (see CtxWithRequestID) is attached in the same way.
+// Returns nil if err is nil.
+//
+//	func loadOrder(ctx context.Context, id string) error {
+//		return errcode.WrapCtx(ctx, errcode.NewNotFoundErr(err))
+//	}
+func WrapCtx(ctx context.Context, err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	d := DecoratorsFromCtx(ctx)
+	if d.UserMsg != "" && attachedUserMsg(err) != "" {
+		d.UserMsg = ""
+	}
+	if id := RequestIDFromCtx(ctx); id != "" && RequestID(err) == "" {
+		err = RequestIDErrCode{RequestID: id, Err: err}
+	}
+	return d.Apply(err)
+}
@@ -0,0 +1,66 @@
+package errcode_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestWrapCtx(t *testing.T) {
+	ctx := context.Background()
+	if errcode.WrapCtx(ctx, nil) != nil {
+		t.Error("expected nil")
+	}
+	notFound := errcode.NewNotFoundErr(errors.New("no row"))
+	if err := errcode.WrapCtx(ctx, notFound); err != notFound {
+		t.Errorf("expected the error unchanged without decorators, got %v", err)
+	}
+
+	ctx = errcode.CtxWithUserMsg(errcode.CtxWithOp(ctx, "orders.create"), "The order could not be created")
+	if op := errcode.OpFromCtx(ctx); op != "orders.create" {
+		t.Errorf("unexpected operation %s", op)
+	}
+	err := errcode.WrapCtx(ctx, notFound)
+	if op := errcode.Operation(err); op != "orders.create" {
+		t.Errorf("unexpected operation %s", op)
+	}
+	UserMsgEquals(t, err, "The order could not be created")
+	ErrorEquals(t, err, "orders.create: The order could not be created: no row")
+
+	specific := errcode.WrapCtx(ctx, errcode.WithUserMsg("The item is gone", notFound))
+	UserMsgEquals(t, specific, "The item is gone")
+
+	inner := errcode.CtxWithOp(ctx, "orders.load")
+	if msg := errcode.DecoratorsFromCtx(inner).UserMsg; msg != "The order could not be created" {
+		t.Errorf("expected the user message to be kept, got %s", msg)
+	}
+	if op := errcode.Operation(errcode.WrapCtx(inner, notFound)); op != "orders.load" {
+		t.Errorf("unexpected operation %s", op)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	notFound := errcode.NewNotFoundErr(errors.New("no row"))
+	if errcode.WithRequestID("req-1", nil) != nil {
+		t.Error("expected nil")
+	}
+	err := errcode.WithRequestID("req-1", notFound)
+	ErrorEquals(t, err, "no row")
+	if format := errcode.NewJSONFormat(errcode.Op("orders.get")(err)); format.RequestID != "req-1" {
+		t.Errorf("unexpected request ID %s", format.RequestID)
+	}
+
+	ctx := errcode.CtxWithRequestID(context.Background(), "req-2")
+	if id := errcode.RequestID(errcode.WrapCtx(ctx, notFound)); id != "req-2" {
+		t.Errorf("expected the request ID from the context, got %s", id)
+	}
+	if id := errcode.RequestID(errcode.WrapCtx(ctx, err)); id != "req-1" {
+		t.Errorf("expected the existing request ID to be kept, got %s", id)
+	}
+	remote := errcode.NewRemoteErr(errcode.NewJSONFormat(err), nil)
+	if id := errcode.RequestID(remote); id != "req-1" {
+		t.Errorf("expected the request ID to be propagated, got %s", id)
+	}
+}
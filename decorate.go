@@ -0,0 +1,127 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+// HasTags retrieves key/value tags of an error, for example for filtering in an error tracker.
+//
+// The tags should be retrieved with [Tags].
+// Tags are normally attached with [WithTags] or [Decorators].
+type HasTags interface {
+	GetTags() map[string]string
+}
+
+// Tags will return the tags of an error if they exist.
+// It checks recursively for the [HasTags] interface.
+// The returned map must not be modified.
+func Tags(v interface{}) map[string]string {
+	if hasTags, ok := v.(HasTags); ok {
+		return hasTags.GetTags()
+	}
+	if un, ok := v.(unwrapError); ok {
+		return Tags(un.Unwrap())
+	}
+	return nil
+}
+
+// TagsErrCode is an ErrorCode with tags attached.
+// It is constructed by [WithTags].
+type TagsErrCode struct {
+	Tags map[string]string
+	Err  ErrorCode
+}
+
+// WithTags attaches tags to an ErrorCode.
+// The tags are not copied and must not be modified afterwards.
+// Returns nil if err is nil.
+func WithTags(tags map[string]string, err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	return TagsErrCode{Tags: tags, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e TagsErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e TagsErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// GetTags satisfies the [HasTags] interface.
+func (e TagsErrCode) GetTags() map[string]string {
+	return e.Tags
+}
+
+// Code returns the underlying Code of Err.
+func (e TagsErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e TagsErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*TagsErrCode)(nil)   // assert implements interface
+var _ HasTags = (*TagsErrCode)(nil)     // assert implements interface
+var _ unwrapError = (*TagsErrCode)(nil) // assert implements interface
+
+// Decorators is a reusable set of decorations for errors.
+// It is built once, for example per request or per package, and applied to many errors with Apply.
+// Empty fields are not applied.
+//
+// Use NewDecorators so that the tags are copied:
+// a Decorators value is then immutable and safe for concurrent use.
+//
+//	decorate := errcode.NewDecorators("orders.create", "The order could not be created", map[string]string{"tenant": tenant})
+//	return decorate.Apply(errcode.NewNotFoundErr(err))
+type Decorators struct {
+	Op      string
+	UserMsg string
+	Tags    map[string]string
+}
+
+// NewDecorators creates Decorators with a copy of the tags.
+func NewDecorators(op string, userMsg string, tags map[string]string) Decorators {
+	var copied map[string]string
+	if len(tags) > 0 {
+		copied = make(map[string]string, len(tags))
+		for k, v := range tags {
+			copied[k] = v
+		}
+	}
+	return Decorators{Op: op, UserMsg: userMsg, Tags: copied}
+}
+
+// Apply decorates the ErrorCode with the tags, then the user message, then the operation.
+// It allocates one wrapper per decoration and does not modify the Decorators.
+// Returns nil if err is nil.
+func (d Decorators) Apply(err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	if d.Tags != nil {
+		err = TagsErrCode{Tags: d.Tags, Err: err}
+	}
+	if d.UserMsg != "" {
+		err = WithUserMsg(d.UserMsg, err)
+	}
+	if d.Op != "" {
+		err = Op(d.Op)(err)
+	}
+	return err
+}
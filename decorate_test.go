@@ -0,0 +1,45 @@
+package errcode_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestDecorators(t *testing.T) {
+	tags := map[string]string{"tenant": "acme"}
+	decorate := errcode.NewDecorators("orders.create", "The order could not be created", tags)
+	tags["tenant"] = "changed"
+	addOp := errcode.Op("orders")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := decorate.Apply(addOp(errcode.NewNotFoundErr(errors.New("no item"))))
+			if op := errcode.Operation(err); op != "orders.create" {
+				t.Errorf("unexpected operation %s", op)
+			}
+			if msg := errcode.GetUserMsg(err); msg != "The order could not be created" {
+				t.Errorf("unexpected user message %s", msg)
+			}
+			if tags := errcode.Tags(err); tags["tenant"] != "acme" {
+				t.Errorf("unexpected tags %v", tags)
+			}
+			if !errcode.IsNotFound(err) {
+				t.Errorf("unexpected code %v", err.Code())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if (errcode.Decorators{}).Apply(nil) != nil {
+		t.Error("expected nil")
+	}
+	if err := (errcode.Decorators{}).Apply(errcode.NewGoneErr(errors.New("deleted"))); errcode.Tags(err) != nil || errcode.Operation(err) != "" {
+		t.Errorf("expected no decoration, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"sort"
+)
+
+// CodeSpec declares a code for DefineTree.
+// Zero values are not set on the code.
+// With applies other meta data, for example from the grpc package:
+//
+//	With: []func(errcode.Code) errcode.Code{errgrpc.WithCode(codes.NotFound)}
+type CodeSpec struct {
+	HTTP        int
+	Description string
+	Remediation string
+	UserMsg     string
+	Severity    Severity
+	With        []func(Code) Code
+	Children    map[CodeStr]CodeSpec
+}
+
+// DefineTree creates codes and their children from a declaration.
+// The keys are the last segment of the CodeStr: the codes are created with NewCode and their children with Child.
+// The result is keyed by the full CodeStr.
+// As with NewCode, an invalid declaration panics.
+//
+//	var codes = errcode.DefineTree(map[errcode.CodeStr]errcode.CodeSpec{
+//		"quota": {HTTP: 429, Description: "The account quota is exhausted", Children: map[errcode.CodeStr]errcode.CodeSpec{
+//			"storage": {HTTP: 507, Description: "Storage is full"},
+//		}},
+//	})
+//	var storageCode = codes["quota.storage"]
+func DefineTree(specs map[CodeStr]CodeSpec) map[CodeStr]Code {
+	defined := make(map[CodeStr]Code)
+	for _, name := range sortedSpecNames(specs) {
+		defineSpec(NewCode(name), specs[name], defined)
+	}
+	return defined
+}
+
+// DefineChildren creates children of the code from a declaration as with DefineTree.
+// This is used to add codes under the builtin codes.
+func (code Code) DefineChildren(specs map[CodeStr]CodeSpec) map[CodeStr]Code {
+	defined := make(map[CodeStr]Code)
+	for _, name := range sortedSpecNames(specs) {
+		defineSpec(code.Child(name), specs[name], defined)
+	}
+	return defined
+}
+
+func defineSpec(code Code, spec CodeSpec, defined map[CodeStr]Code) {
+	if spec.HTTP != 0 {
+		code = code.SetHTTP(spec.HTTP)
+	}
+	if spec.Description != "" {
+		code = code.SetDescription(spec.Description)
+	}
+	if spec.Remediation != "" {
+		code = code.SetRemediation(spec.Remediation)
+	}
+	if spec.UserMsg != "" {
+		code = code.SetDefaultUserMsg(spec.UserMsg)
+	}
+	if spec.Severity != "" {
+		code = code.SetSeverity(spec.Severity)
+	}
+	for _, with := range spec.With {
+		code = with(code)
+	}
+	defined[code.CodeStr()] = code
+	for _, name := range sortedSpecNames(spec.Children) {
+		defineSpec(code.Child(name), spec.Children[name], defined)
+	}
+}
+
+func sortedSpecNames(specs map[CodeStr]CodeSpec) []CodeStr {
+	names := make([]CodeStr, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
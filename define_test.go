@@ -0,0 +1,46 @@
+package errcode_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestDefineTree(t *testing.T) {
+	var applied []errcode.CodeStr
+	codes := errcode.DefineTree(map[errcode.CodeStr]errcode.CodeSpec{
+		"definequota": {HTTP: 429, Description: "The account quota is exhausted", Children: map[errcode.CodeStr]errcode.CodeSpec{
+			"storage": {HTTP: 507, Remediation: "Delete files", Severity: errcode.SeverityInfo},
+			"seats": {UserMsg: "No seats are left", With: []func(errcode.Code) errcode.Code{
+				func(code errcode.Code) errcode.Code {
+					applied = append(applied, code.CodeStr())
+					return code
+				},
+			}},
+		}},
+	})
+	if len(codes) != 3 {
+		t.Fatalf("unexpected codes %v", codes)
+	}
+	storage := codes["definequota.storage"]
+	if storage.HTTPCode() != 507 || storage.Remediation() != "Delete files" || storage.Severity() != errcode.SeverityInfo {
+		t.Errorf("unexpected storage code %v", storage)
+	}
+	seats := codes["definequota.seats"]
+	if seats.HTTPCode() != 429 || seats.DefaultUserMsg() != "No seats are left" || seats.Parent.Description() != "The account quota is exhausted" {
+		t.Errorf("unexpected seats code %v", seats)
+	}
+	if len(applied) != 1 || applied[0] != "definequota.seats" {
+		t.Errorf("expected With to be applied, got %v", applied)
+	}
+	if _, ok := errcode.DefaultRegistry().Lookup("definequota.seats"); !ok {
+		t.Error("expected the code to be registered")
+	}
+
+	children := errcode.InvalidInputCode.DefineChildren(map[errcode.CodeStr]errcode.CodeSpec{
+		"definecoupon": {Description: "The coupon is not valid"},
+	})
+	if coupon := children["input.definecoupon"]; coupon.HTTPCode() != 400 || !coupon.IsAncestor(errcode.InvalidInputCode) {
+		t.Errorf("unexpected coupon code %v", coupon)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gregwebs/errors"
+)
+
+var descriptionMetaData = make(MetaData)
+var remediationMetaData = make(MetaData)
+
+// SetDescription adds a human readable description of the code to the meta data.
+// The description can be retrieved with Description.
+// It is used for generating documentation with GenerateDocs.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func (code Code) SetDescription(description string) Code {
+	if err := code.SetMetaData(descriptionMetaData, description); err != nil {
+		panic(errors.Wrap(err, "SetDescription"))
+	}
+	return code
+}
+
+// Description gives the description set with SetDescription.
+// Descriptions are not inherited from ancestors.
+func (code Code) Description() string {
+	description, _ := getMetaData(descriptionMetaData, code).(string)
+	return description
+}
+
+// SetRemediation adds text to the meta data that explains how a client can resolve the error.
+// The remediation can be retrieved with Remediation.
+// It is used for generating documentation with GenerateDocs.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func (code Code) SetRemediation(remediation string) Code {
+	if err := code.SetMetaData(remediationMetaData, remediation); err != nil {
+		panic(errors.Wrap(err, "SetRemediation"))
+	}
+	return code
+}
+
+// Remediation gives the remediation set with SetRemediation.
+// Remediations are not inherited from ancestors.
+func (code Code) Remediation() string {
+	remediation, _ := getMetaData(remediationMetaData, code).(string)
+	return remediation
+}
+
+var docURLMetaData = make(MetaData)
+
+// SetDocURL adds a URL of documentation for the code to the meta data.
+// The URL can be retrieved with DocURL.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func (code Code) SetDocURL(url string) Code {
+	if err := code.SetMetaData(docURLMetaData, url); err != nil {
+		panic(errors.Wrap(err, "SetDocURL"))
+	}
+	return code
+}
+
+// DocURL gives the documentation URL set with SetDocURL.
+// URLs are not inherited from ancestors.
+func (code Code) DocURL() string {
+	url, _ := getMetaData(docURLMetaData, code).(string)
+	return url
+}
+
+var (
+	docMappingsMu sync.RWMutex
+	docMappings   = make(map[string]func(Code) string)
+)
+
+// RegisterDocMapping adds a mapping of codes to another system that is included in CodeDoc.
+// For example the grpc package registers the GRPC code under the name "grpc".
+// An empty string from the mapping function is left out.
+func RegisterDocMapping(name string, mapping func(Code) string) {
+	docMappingsMu.Lock()
+	defer docMappingsMu.Unlock()
+	docMappings[name] = mapping
+}
+
+// CodeDoc is the documentation of a code.
+// HTTP is the HTTP code of the code or its ancestors and is zero if there is none.
+// Mappings are from the functions added with RegisterDocMapping.
+type CodeDoc struct {
+	Code        CodeStr           `json:"code"`
+	Parent      CodeStr           `json:"parent,omitempty"`
+	HTTP        int               `json:"http,omitempty"`
+	Mappings    map[string]string `json:"mappings,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Remediation string            `json:"remediation,omitempty"`
+	DocURL      string            `json:"doc_url,omitempty"`
+}
+
+// NewCodeDoc gives the documentation of a code.
+func NewCodeDoc(code Code) CodeDoc {
+	doc := CodeDoc{
+		Code:        code.CodeStr(),
+		Description: code.Description(),
+		Remediation: code.Remediation(),
+		DocURL:      code.DocURL(),
+	}
+	if code.Parent != nil {
+		doc.Parent = code.Parent.CodeStr()
+	}
+	if httpCode := HTTPCode(code); httpCode != nil {
+		doc.HTTP = *httpCode
+	}
+	docMappingsMu.RLock()
+	defer docMappingsMu.RUnlock()
+	for name, mapping := range docMappings {
+		if mapped := mapping(code); mapped != "" {
+			if doc.Mappings == nil {
+				doc.Mappings = make(map[string]string)
+			}
+			doc.Mappings[name] = mapped
+		}
+	}
+	return doc
+}
+
+// SampleJSONFormat gives an example JSONFormat for the code.
+// The Msg is the default user message or else the description of the code.
+func SampleJSONFormat(code Code) JSONFormat {
+	msg := code.DefaultUserMsg()
+	if msg == "" {
+		msg = code.Description()
+	}
+	return JSONFormat{Code: code.CodeStr(), Msg: msg}
+}
+
+// DocFormat is the output format of GenerateDocs.
+type DocFormat int
+
+const (
+	// DocMarkdown produces a Markdown table.
+	DocMarkdown DocFormat = iota
+	// DocJSON produces a JSON array of CodeDoc.
+	DocJSON
+)
+
+// GenerateDocs produces documentation of every code in the registry.
+// The codes are ordered by CodeStr.
+// This can be published for API consumers.
+func GenerateDocs(registry *Registry, format DocFormat) ([]byte, error) {
+	codes := registry.Codes()
+	docs := make([]CodeDoc, len(codes))
+	for i, code := range codes {
+		docs[i] = NewCodeDoc(code)
+	}
+	switch format {
+	case DocJSON:
+		return json.MarshalIndent(docs, "", "  ")
+	case DocMarkdown:
+		var b strings.Builder
+		b.WriteString("| Code | HTTP | Description | Remediation |\n")
+		b.WriteString("| ---- | ---- | ----------- | ----------- |\n")
+		for _, doc := range docs {
+			httpCode := ""
+			if doc.HTTP != 0 {
+				httpCode = fmt.Sprint(doc.HTTP)
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				doc.Code, httpCode, markdownCell(doc.Description), markdownCell(doc.Remediation))
+		}
+		return []byte(b.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown doc format %d", format)
+	}
+}
+
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,54 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	registry := errcode.NewRegistry()
+	quotaCode := errcode.NewCode("docquota").SetHTTP(429).
+		SetDescription("The account quota is exhausted").
+		SetRemediation("Wait | retry later")
+	storageCode := quotaCode.Child("docquota.storage").SetDescription("Storage is full")
+	registry.Register(storageCode, quotaCode, errcode.NotFoundCode)
+
+	if code, ok := errcode.DefaultRegistry().Lookup("docquota.storage"); !ok || code.CodeStr() != storageCode.CodeStr() {
+		t.Errorf("expected the code to be in the default registry")
+	}
+	if storageCode.Remediation() != "" {
+		t.Errorf("expected remediation not to be inherited")
+	}
+
+	markdown, err := errcode.GenerateDocs(registry, errcode.DocMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"| Code | HTTP | Description | Remediation |",
+		"| ---- | ---- | ----------- | ----------- |",
+		"| `docquota` | 429 | The account quota is exhausted | Wait \\| retry later |",
+		"| `docquota.storage` | 429 | Storage is full |  |",
+		"| `missing` | 404 |  |  |",
+		"",
+	}, "\n")
+	if string(markdown) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, markdown)
+	}
+
+	jsonDocs, err := errcode.GenerateDocs(registry, errcode.DocJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []errcode.CodeDoc
+	if err := json.Unmarshal(jsonDocs, &docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 || !reflect.DeepEqual(docs[1], errcode.CodeDoc{Code: "docquota.storage", Parent: "docquota", HTTP: 429, Description: "Storage is full"}) {
+		t.Errorf("unexpected docs %v", docs)
+	}
+}
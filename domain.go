@@ -0,0 +1,104 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+)
+
+// Domain associates a code and its descendants with a type of client data.
+// Errors of the domain can only be constructed with data of type T
+// and the data is retrieved as T, giving a type checked data contract for a family of errors.
+//
+//	type StockData struct{ Item string `json:"item"` }
+//	var OrderDomain = errcode.NewDomain[StockData](errcode.StateCode.Child("state.order"))
+//	var OutOfStock = OrderDomain.Child("state.order.stock")
+//
+//	err := OutOfStock.New(errors.New("out of stock"), StockData{Item: "apple"})
+//	data, ok := OrderDomain.Data(err)
+type Domain[T any] struct {
+	code Code
+}
+
+// NewDomain creates a Domain for the code and its descendants.
+func NewDomain[T any](code Code) Domain[T] {
+	return Domain[T]{code: code}
+}
+
+// Code gives the code of the Domain.
+func (d Domain[T]) Code() Code {
+	return d.code
+}
+
+// Child creates a child code that is in the same Domain.
+// See Code.Child.
+func (d Domain[T]) Child(childStr CodeStr) Domain[T] {
+	return Domain[T]{code: d.code.Child(childStr)}
+}
+
+// New creates a DomainErr with the code of the Domain.
+// Unlike NewCodedError, the code of the Domain is used even if the error is already an ErrorCode.
+// A stack trace is added according to the StackPolicy.
+func (d Domain[T]) New(err error, data T) DomainErr[T] {
+	if err == nil {
+		panic("Domain.New error is nil")
+	}
+	return DomainErr[T]{CodedError: newCodedError(err, d.code, 1), Data: data}
+}
+
+// Contains checks if any ErrorCode in the error has the code of the Domain or a descendant.
+// As with HasAncestor, the error is unwrapped, including groups.
+func (d Domain[T]) Contains(err error) bool {
+	return HasAncestor(err, d.code)
+}
+
+// Data retrieves the data of the first DomainErr of the Domain found by unwrapping, including groups.
+// Errors of descendant domains are included.
+// False is returned if there is no such error.
+func (d Domain[T]) Data(err error) (T, bool) {
+	var data T
+	found := walkDeep(err, func(err error) bool {
+		if domainErr, ok := err.(DomainErr[T]); ok && domainErr.GetCode.IsAncestor(d.code) {
+			data = domainErr.Data
+			return true
+		}
+		return false
+	})
+	return data, found
+}
+
+// DomainErr is an ErrorCode of a Domain with client data of type T.
+// It is constructed with Domain.New.
+type DomainErr[T any] struct {
+	CodedError
+	Data T
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e DomainErr[T]) GetClientData() interface{} {
+	return e.Data
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e DomainErr[T]) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "DomainErr{CodedError: %#v, Data: %#v}", e.CodedError, e.Data)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+var _ ErrorCode = (*DomainErr[struct{}])(nil)     // assert implements interface
+var _ HasClientData = (*DomainErr[struct{}])(nil) // assert implements interface
@@ -0,0 +1,54 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+type stockData struct {
+	Item string `json:"item"`
+}
+
+var orderDomain = errcode.NewDomain[stockData](errcode.StateCode.Child("state.domainorder"))
+var outOfStockDomain = orderDomain.Child("state.domainorder.stock")
+
+func TestDomain(t *testing.T) {
+	err := errors.Wrap(outOfStockDomain.New(errcode.NewNotFoundErr(errors.New("no apples")), stockData{Item: "apple"}), "order")
+	if data, ok := orderDomain.Data(err); !ok || data.Item != "apple" {
+		t.Errorf("unexpected data %v", data)
+	}
+	if !orderDomain.Contains(err) || errcode.CodeChain(err).Code().CodeStr() != outOfStockDomain.Code().CodeStr() {
+		t.Error("expected the domain code to be used")
+	}
+	// like errors.Is with CodeIs, the predicates find the code of the wrapped error
+	if !errcode.IsNotFound(err) {
+		t.Error("expected the wrapped code to be found")
+	}
+	b, jsonErr := json.Marshal(errcode.NewJSONFormat(outOfStockDomain.New(errors.New("no apples"), stockData{Item: "apple"})))
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if expected := `{"code":"state.domainorder.stock","msg":"no apples","data":{"item":"apple"}}`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	// every ErrorCode of a group is checked, as with HasAncestor
+	grouped := errcode.Combine(errcode.NewUnavailableErr(errors.New("down")), outOfStockDomain.New(errors.New("no pears"), stockData{Item: "pear"}))
+	if !orderDomain.Contains(grouped) {
+		t.Error("expected the domain to contain a member of the group")
+	}
+	if data, ok := orderDomain.Data(grouped); !ok || data.Item != "pear" {
+		t.Errorf("unexpected data of a group %v", data)
+	}
+
+	otherDomain := errcode.NewDomain[stockData](errcode.InvalidInputCode.Child("input.domainother"))
+	if _, ok := otherDomain.Data(err); ok {
+		t.Error("expected no data from another domain")
+	}
+	if _, ok := outOfStockDomain.Data(orderDomain.New(errors.New("order failed"), stockData{})); ok {
+		t.Error("expected no data from an ancestor domain")
+	}
+}
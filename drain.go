@@ -0,0 +1,66 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDrainRetryAfter is the default Retry-After of NewDrainingErr.
+const DefaultDrainRetryAfter = 5 * time.Second
+
+var (
+	draining atomic.Bool
+	// drainRetryAfter is nil until SetDrainRetryAfter is called: getDrainRetryAfter then gives DefaultDrainRetryAfter.
+	drainRetryAfter atomic.Pointer[time.Duration]
+)
+
+// SetDraining marks the process as draining during a graceful shutdown.
+// While draining, the HTTP and gRPC integrations respond to new requests with NewDrainingErr
+// so that clients retry against another instance.
+func SetDraining(isDraining bool) {
+	draining.Store(isDraining)
+}
+
+// IsDraining tells if SetDraining(true) was called.
+func IsDraining() bool {
+	return draining.Load()
+}
+
+// SetDrainRetryAfter sets the Retry-After given by NewDrainingErr.
+// The default is DefaultDrainRetryAfter.
+func SetDrainRetryAfter(retryAfter time.Duration) {
+	drainRetryAfter.Store(&retryAfter)
+}
+
+func getDrainRetryAfter() time.Duration {
+	if retryAfter := drainRetryAfter.Load(); retryAfter != nil {
+		return *retryAfter
+	}
+	return DefaultDrainRetryAfter
+}
+
+// errDraining is the underlying error of NewDrainingErr.
+// It has no stack trace: one created at package initialization would not say anything about the request.
+var errDraining = errors.New("the service is shutting down")
+
+// NewDrainingErr gives the error for a request that arrives while draining.
+// It has UnavailableCode and the Retry-After set with SetDrainRetryAfter.
+// Unlike NewUnavailableErr no stack trace is captured: draining is expected and happens for every request during a shutdown.
+func NewDrainingErr() ErrorCode {
+	drainingErr := CodedError{GetCode: UnavailableCode, Err: errDraining, time: newTimestamp(errDraining)}
+	return WithRetryAfter(getDrainRetryAfter(), drainingErr)
+}
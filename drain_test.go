@@ -0,0 +1,28 @@
+package errcode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestDraining(t *testing.T) {
+	defer errcode.SetDraining(false)
+	defer errcode.SetDrainRetryAfter(errcode.DefaultDrainRetryAfter)
+	if errcode.IsDraining() {
+		t.Fatal("expected not to be draining")
+	}
+	errcode.SetDraining(true)
+	if !errcode.IsDraining() {
+		t.Fatal("expected to be draining")
+	}
+	errcode.SetDrainRetryAfter(time.Second)
+	errCode := errcode.NewDrainingErr()
+	if !errcode.IsUnavailable(errCode) || errcode.RetryAfter(errCode) != time.Second {
+		t.Errorf("unexpected draining error %v", errCode)
+	}
+	if errcode.HasStack(errCode) {
+		t.Errorf("expected no stack trace for a draining error, got %+v", errcode.StackTrace(errCode))
+	}
+}
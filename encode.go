@@ -0,0 +1,248 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONIndent indents the output of WriteJSON as with json.MarshalIndent.
+// It does not change NewJSONFormat.
+func JSONIndent(prefix string, indent string) FormatOption {
+	return func(c *formatConfig) {
+		c.indentPrefix = prefix
+		c.indent = indent
+	}
+}
+
+// OmitFields leaves fields out of the output of WriteJSON.
+// The fields are the JSON names of the JSONFormat fields, for example "data" or "operation".
+// It does not change NewJSONFormat.
+func OmitFields(fields ...string) FormatOption {
+	return func(c *formatConfig) {
+		if c.omitFields == nil {
+			c.omitFields = make(map[string]struct{}, len(fields))
+		}
+		for _, field := range fields {
+			c.omitFields[field] = struct{}{}
+		}
+	}
+}
+
+var encodeBufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// WriteJSON writes the JSON of the JSONFormat of the ErrorCode followed by a newline, as json.Encoder does.
+// The JSON is the same as for NewJSONFormat with the same options,
+// but it is encoded without building the intermediate JSONFormat values.
+// This reduces allocations in hot error paths such as HTTP handlers.
+// The JSONIndent and OmitFields options change the output.
+// The cost is reported to the observer set with ObserveSerialization.
+func WriteJSON(w io.Writer, errCode ErrorCode, opts ...FormatOption) error {
+	config := newFormatConfig(opts)
+	buf := encodeBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer encodeBufferPool.Put(buf)
+
+	observer := serializationObserver.Load()
+	var start time.Time
+	if observer != nil {
+		start = time.Now()
+	}
+	if err := config.encodeJSON(buf, errCode, nil); err != nil {
+		return err
+	}
+	if config.indent != "" || config.indentPrefix != "" {
+		indented := encodeBufferPool.Get().(*bytes.Buffer)
+		indented.Reset()
+		defer encodeBufferPool.Put(indented)
+		if err := json.Indent(indented, buf.Bytes(), config.indentPrefix, config.indent); err != nil {
+			return err
+		}
+		buf = indented
+	}
+	buf.WriteByte('\n')
+	if observer != nil {
+		(*observer)(SerializationStat{Code: errCode.Code().CodeStr(), Duration: time.Since(start), Bytes: buf.Len()})
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// jsonObject writes the fields of a JSON object that are not omitted.
+type jsonObject struct {
+	buf    *bytes.Buffer
+	omit   map[string]struct{}
+	fields int
+}
+
+// field writes the field name if the field is not omitted.
+func (o *jsonObject) field(name string) bool {
+	if _, ok := o.omit[name]; ok {
+		return false
+	}
+	if o.fields > 0 {
+		o.buf.WriteByte(',')
+	}
+	o.fields++
+	writeJSONString(o.buf, name)
+	o.buf.WriteByte(':')
+	return true
+}
+
+func (o *jsonObject) stringField(name string, value string, omitEmpty bool) {
+	if (omitEmpty && value == "") || !o.field(name) {
+		return
+	}
+	writeJSONString(o.buf, value)
+}
+
+// encodeJSON follows the field order and omitempty tags of JSONFormat.
+// The path is the Path of one of the Others.
+func (c formatConfig) encodeJSON(buf *bytes.Buffer, errCode ErrorCode, path []int) error {
+	op, data := c.operationClientData(errCode)
+	obj := jsonObject{buf: buf, omit: c.omitFields}
+	buf.WriteByte('{')
+	obj.stringField("code", errCode.Code().CodeStr().String(), false)
+	obj.stringField("msg", c.userMsg(errCode), false)
+	if obj.field("data") {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	obj.stringField("operation", op, true)
+	if ops := c.operationChain(errCode); len(ops) > 0 && obj.field("operations") {
+		buf.WriteByte('[')
+		for i, op := range ops {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, op)
+		}
+		buf.WriteByte(']')
+	}
+	obj.stringField("label", Label(errCode), true)
+	if len(path) > 0 && obj.field("path") {
+		buf.WriteByte('[')
+		for i, index := range path {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Itoa(index))
+		}
+		buf.WriteByte(']')
+	}
+	obj.stringField("request_id", RequestID(errCode), true)
+	obj.stringField("origin", originOf(errCode), true)
+	if t := timestampOf(errCode); t != nil && obj.field("time") {
+		b, err := t.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	obj.stringField("doc", c.docLink(errCode.Code()), true)
+	if stack := c.stackFrames(errCode); len(stack) > 0 && obj.field("stack") {
+		b, err := json.Marshal(stack)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	errorCodes, omitted := c.others(errCode)
+	if len(errorCodes) > 0 && obj.field("others") {
+		buf.WriteByte('[')
+		for i, other := range errorCodes {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := c.encodeJSON(buf, other.ErrCode, other.Path); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	}
+	if omitted != 0 && obj.field("others_omitted") {
+		buf.WriteString(strconv.Itoa(omitted))
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// writeJSONString writes a JSON string with the escaping of encoding/json in Go 1.22 and later.
+// Earlier versions of encoding/json escape backspace, form feed, and the replacement of invalid UTF-8 as \u0008, \u000c, and \ufffd,
+// which decode to the same string.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[b>>4])
+				buf.WriteByte(hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
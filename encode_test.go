@@ -0,0 +1,89 @@
+package errcode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestWriteJSON(t *testing.T) {
+	group := errcode.Combine(
+		errcode.Op("orders.get")(errcode.NewNotFoundErr(errors.New("no <order> & \"quote\"\n\x01   \xff"))),
+		errcode.WithRequestID("req-1", errcode.NewGoneErr(errors.New("deleted"))),
+		errcode.NewNotFoundErr(errors.New("no price")),
+	)
+	for _, test := range []struct {
+		errCode errcode.ErrorCode
+		opts    []errcode.FormatOption
+	}{
+		{errcode.NewInternalErr(errors.New("internal")), nil},
+		{errcode.NewInternalErr(errors.New("internal")), []errcode.FormatOption{errcode.IncludeStack()}},
+		{errcode.WithTimestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), errcode.NewNotFoundErr(errors.New("not found"))), nil},
+		{errcode.WithOrigin("billing", errcode.NewNotFoundErr(errors.New("not found"))), nil},
+		{errcode.NewFieldErrors(errcode.NewFieldError("name", errcode.InvalidInputCode, "required")), nil},
+		{group, nil},
+		{group, []errcode.FormatOption{errcode.MaxOthers(1), errcode.IncludeOperations(), errcode.DocLinks("https://docs.example.com/errors")}},
+		{group, []errcode.FormatOption{errcode.NestOthers(), errcode.DedupeOthers()}},
+	} {
+		expected, err := json.Marshal(errcode.NewJSONFormat(test.errCode, test.opts...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := errcode.WriteJSON(&buf, test.errCode, test.opts...); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != normalizeEscapes(expected)+"\n" {
+			t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+		}
+	}
+
+	expected, _ := json.MarshalIndent(errcode.NewJSONFormat(group), "", "  ")
+	var buf bytes.Buffer
+	if err := errcode.WriteJSON(&buf, group, errcode.JSONIndent("", "  ")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != normalizeEscapes(expected)+"\n" {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := errcode.WriteJSON(&buf, group, errcode.OmitFields("data", "others")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `"data"`) || strings.Contains(buf.String(), `"others"`) || !strings.HasPrefix(buf.String(), `{"code":"missing","msg":`) {
+		t.Errorf("expected the fields to be omitted, got %s", buf.String())
+	}
+}
+
+func TestWriteJSONEscaping(t *testing.T) {
+	var msg strings.Builder
+	for b := 0; b < 0x20; b++ {
+		msg.WriteByte(byte(b))
+	}
+	msg.WriteString("\"\\<>&\u2028\u2029\xff\x7f é")
+	errCode := errcode.NewNotFoundErr(errors.New(msg.String()))
+	expected, err := json.Marshal(errcode.NewJSONFormat(errCode))
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized := normalizeEscapes(expected)
+	var buf bytes.Buffer
+	if err := errcode.WriteJSON(&buf, errCode); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != normalized+"\n" {
+		t.Errorf("expected\n%s\ngot\n%s", normalized, buf.String())
+	}
+}
+
+// normalizeEscapes gives the escaping of encoding/json in Go 1.22 and later, which WriteJSON follows.
+// Earlier versions escape backspace, form feed, and the replacement of invalid UTF-8.
+func normalizeEscapes(encoded []byte) string {
+	return strings.NewReplacer(`\u0008`, `\b`, `\u000c`, `\f`, `\ufffd`, "\ufffd").Replace(string(encoded))
+}
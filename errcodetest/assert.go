@@ -0,0 +1,93 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcodetest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+// errorCode gives the ErrorCode of the error as found by CodeChain.
+// A missing ErrorCode is reported.
+func errorCode(t testing.TB, err error) errcode.ErrorCode {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected an error but got nil")
+		return nil
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		t.Errorf("expected an error with a code but got %v", err)
+	}
+	return errCode
+}
+
+// AssertCode checks that the error has exactly the code, as found by CodeChain.
+func AssertCode(t testing.TB, err error, code errcode.Code) {
+	t.Helper()
+	if errCode := errorCode(t, err); errCode != nil && errCode.Code().CodeStr() != code.CodeStr() {
+		t.Errorf("expected the code %s but got %s: %v", code.CodeStr(), errCode.Code().CodeStr(), err)
+	}
+}
+
+// AssertHTTP checks the HTTP status of the code of the error.
+func AssertHTTP(t testing.TB, err error, status int) {
+	t.Helper()
+	if errCode := errorCode(t, err); errCode != nil && errCode.Code().HTTPCode() != status {
+		t.Errorf("expected the HTTP status %d but got %d for the code %s", status, errCode.Code().HTTPCode(), errCode.Code().CodeStr())
+	}
+}
+
+// AssertUserMsg checks the user message of the error as given by errcode.GetUserMsg.
+func AssertUserMsg(t testing.TB, err error, msg string) {
+	t.Helper()
+	if got := errcode.GetUserMsg(errorCode(t, err)); got != msg {
+		t.Errorf("expected the user message %q but got %q", msg, got)
+	}
+}
+
+// AssertClientDataEquals checks the client data of the error as given by errcode.ClientData.
+// The data is compared by its JSON so that, for example, a struct can be compared to the map that a client decodes.
+func AssertClientDataEquals(t testing.TB, err error, data interface{}) {
+	t.Helper()
+	errCode := errorCode(t, err)
+	if errCode == nil {
+		return
+	}
+	expected, err := json.Marshal(data)
+	if err != nil {
+		t.Errorf("could not serialize the expected client data to JSON: %v", err)
+		return
+	}
+	got, err := json.Marshal(errcode.ClientData(errCode))
+	if err != nil {
+		t.Errorf("could not serialize the client data to JSON: %v", err)
+		return
+	}
+	if string(expected) != string(got) {
+		t.Errorf("expected the client data %s but got %s", expected, got)
+	}
+}
+
+// RequireIsCode stops the test unless errors.Is matches the error with errcode.CodeIs:
+// the error has the code or a descendant of it.
+func RequireIsCode(t testing.TB, err error, code errcode.Code) {
+	t.Helper()
+	if !errors.Is(err, errcode.CodeIs(code)) {
+		t.Fatalf("expected an error with the code %s but got %v", code.CodeStr(), err)
+	}
+}
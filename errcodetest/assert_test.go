@@ -0,0 +1,60 @@
+package errcodetest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/errcodetest"
+	"github.com/gregwebs/errors"
+)
+
+// recordingT records failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failures []string
+	fatal    bool
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	t.fatal = true
+}
+
+func TestAssertions(t *testing.T) {
+	data := map[string]string{"item": "item-1"}
+	err := errors.Wrap(errcode.WithUserMsg("Not found", errcode.WithClientData(data, errcode.NewNotFoundErr(errors.New("no item")))), "get")
+
+	passing := &recordingT{TB: t}
+	errcodetest.AssertCode(passing, err, errcode.NotFoundCode)
+	errcodetest.AssertHTTP(passing, err, 404)
+	errcodetest.AssertUserMsg(passing, err, "Not found")
+	errcodetest.AssertClientDataEquals(passing, err, struct {
+		Item string `json:"item"`
+	}{Item: "item-1"})
+	errcodetest.RequireIsCode(passing, err, errcode.NotFoundCode)
+	if len(passing.failures) > 0 {
+		t.Errorf("unexpected failures %v", passing.failures)
+	}
+
+	failing := &recordingT{TB: t}
+	errcodetest.AssertCode(failing, err, errcode.GoneCode)
+	errcodetest.AssertHTTP(failing, err, 410)
+	errcodetest.AssertUserMsg(failing, err, "Gone")
+	errcodetest.AssertClientDataEquals(failing, err, map[string]string{"item": "item-2"})
+	errcodetest.AssertCode(failing, errors.New("uncoded"), errcode.NotFoundCode)
+	errcodetest.AssertCode(failing, nil, errcode.NotFoundCode)
+	if len(failing.failures) != 6 || failing.fatal {
+		t.Errorf("expected 6 failures, got %v", failing.failures)
+	}
+	errcodetest.RequireIsCode(failing, err, errcode.GoneCode)
+	if !failing.fatal {
+		t.Error("expected RequireIsCode to stop the test")
+	}
+}
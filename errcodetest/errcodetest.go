@@ -0,0 +1,127 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errcodetest verifies the error contract of an HTTP service end to end and has assertions for tests.
+//
+// Sample requests that produce an error with a code are attached to the code as meta data with SetSamples.
+// CheckContract sends the samples to a test server and checks that
+// the response has the code and the HTTP status of the code.
+//
+//	var OutOfStockCode = errcode.StateCode.Child("state.stock")
+//	var _ = errcodetest.SetSamples(OutOfStockCode, errcodetest.Sample{Method: "POST", Path: "/orders", Body: `{"item": "sold-out"}`})
+//
+//	func TestContract(t *testing.T) {
+//		server := httptest.NewServer(newHandler())
+//		defer server.Close()
+//		errcodetest.CheckContract(t, errcode.DefaultRegistry(), server)
+//	}
+//
+// It also has assertions for the code, HTTP status, user message, and client data of an error:
+//
+//	errcodetest.AssertCode(t, err, OutOfStockCode)
+//	errcodetest.AssertUserMsg(t, err, "The item is out of stock")
+package errcodetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+// Sample is an HTTP request that should give an error with a particular code.
+type Sample struct {
+	Method string
+	Path   string
+	Body   string
+	Header http.Header
+}
+
+func (s Sample) String() string {
+	return s.Method + " " + s.Path
+}
+
+var samplesMetaData = make(errcode.MetaData)
+
+// SetSamples adds sample requests to the meta data of the code.
+// The samples can be retrieved with Samples.
+// Panic if the metadata is already set for the code.
+// Returns the code.
+func SetSamples(code errcode.Code, samples ...Sample) errcode.Code {
+	if err := code.SetMetaData(samplesMetaData, samples); err != nil {
+		panic(errors.Wrap(err, "SetSamples"))
+	}
+	return code
+}
+
+// Samples gives the samples of the code set with SetSamples.
+// Samples are not inherited from ancestors.
+func Samples(code errcode.Code) []Sample {
+	samples, _ := code.GetMetaData(samplesMetaData).([]Sample)
+	return samples
+}
+
+// CheckContract sends the samples of every code in the registry to the server.
+// Each response must have the code in its JSONFormat body and the HTTP status of the code.
+// Each code is checked in a subtest named by its CodeStr.
+// Codes without samples are skipped.
+func CheckContract(t *testing.T, registry *errcode.Registry, server *httptest.Server) {
+	t.Helper()
+	for _, code := range registry.Codes() {
+		samples := Samples(code)
+		if len(samples) == 0 {
+			continue
+		}
+		code := code
+		t.Run(code.CodeStr().String(), func(t *testing.T) {
+			for _, sample := range samples {
+				if err := CheckSample(server, code, sample); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+// CheckSample sends a sample request to the server.
+// It returns an error if the response does not have the code or does not have the HTTP status of the code.
+func CheckSample(server *httptest.Server, code errcode.Code, sample Sample) error {
+	req, err := http.NewRequest(sample.Method, server.URL+sample.Path, strings.NewReader(sample.Body))
+	if err != nil {
+		return errors.Wrapf(err, "sample %s", sample)
+	}
+	for key, values := range sample.Header {
+		req.Header[key] = values
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "sample %s", sample)
+	}
+	defer resp.Body.Close()
+
+	var body errcode.JSONFormat
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return errors.Wrapf(err, "sample %s: decoding the response body", sample)
+	}
+	if body.Code != code.CodeStr() {
+		return errors.Errorf("sample %s: expected code %s, got %s", sample, code.CodeStr(), body.Code)
+	}
+	if status := code.HTTPCode(); resp.StatusCode != status {
+		return errors.Errorf("sample %s: expected status %d, got %d", sample, status, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package errcodetest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/errcodetest"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+)
+
+var outOfStockCode = errcodetest.SetSamples(
+	errcode.StateCode.Child("state.contractstock"),
+	errcodetest.Sample{Method: "POST", Path: "/orders"},
+)
+
+var wrongStatusCode = errcodetest.SetSamples(
+	errcode.NotFoundCode.Child("missing.contractwrong"),
+	errcodetest.Sample{Method: "GET", Path: "/wrong"},
+)
+
+func newServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/orders", httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errcode.NewCodedError(errors.New("sold out"), outOfStockCode)
+	}))
+	mux.HandleFunc("/wrong", func(w http.ResponseWriter, r *http.Request) {
+		httperr.WriteJSON(w, 500, errcode.JSONFormat{Code: wrongStatusCode.CodeStr()})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCheckContract(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	registry := errcode.NewRegistry()
+	registry.Register(outOfStockCode, errcode.InternalCode)
+	errcodetest.CheckContract(t, registry, server)
+
+	if len(errcodetest.Samples(errcode.InternalCode)) != 0 {
+		t.Errorf("expected no samples")
+	}
+	err := errcodetest.CheckSample(server, wrongStatusCode, errcodetest.Samples(wrongStatusCode)[0])
+	if err == nil || err.Error() != "sample GET /wrong: expected status 404, got 500" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs functions in goroutines and combines all of their errors into one ErrorCode.
+// It is modeled on golang.org/x/sync/errgroup, which only keeps the first error.
+// This is useful for fan-out request handling where each item can fail with its own code.
+//
+// A zero Group is valid and does not limit the number of goroutines.
+// A Group must not be copied after first use.
+//
+//	var group errcode.Group
+//	for _, id := range ids {
+//		id := id
+//		group.GoLabeled(id, func() error { return fetch(ctx, id) })
+//	}
+//	if errCode := group.Wait(); errCode != nil {
+//		return errCode
+//	}
+type Group struct {
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	results []groupResult
+}
+
+type groupResult struct {
+	label string
+	err   error
+}
+
+// GroupWithContext gives a Group and a context derived from ctx.
+// The context is canceled when Wait returns.
+// Unlike errgroup, it is not canceled by the first error: every function runs so that all of the errors are collected.
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of goroutines running at once to n.
+// Go blocks until a goroutine can be started.
+// A negative n removes the limit.
+// The limit must not be changed while goroutines are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go calls the function in a new goroutine.
+// A returned error is included in the ErrorCode from Wait.
+// A panic is recovered and converted with RecoverToErrorCode.
+func (g *Group) Go(fn func() error) {
+	g.GoLabeled("", fn)
+}
+
+// GoLabeled is Go with a label for the error of the function, for example the ID of the item that it handles.
+// The error is given as a LabeledErrCode as with CombineLabeled.
+func (g *Group) GoLabeled(label string, fn func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.mu.Lock()
+	index := len(g.results)
+	g.results = append(g.results, groupResult{label: label})
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := callRecover(fn)
+		if g.sem != nil {
+			<-g.sem
+		}
+		if err != nil {
+			g.mu.Lock()
+			g.results[index].err = err
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for all of the functions to return.
+// The errors are combined with Combine in the order that the functions were given to Go.
+// An error without an ErrorCode (as found by CodeChain) is converted with NewInternalErr.
+// A single error is returned as its ErrorCode rather than as a MultiErrCode.
+// Returns nil if there are no errors.
+func (g *Group) Wait() ErrorCode {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var codes []ErrorCode
+	for _, result := range g.results {
+		if result.err == nil {
+			continue
+		}
+		errCode := CodeChain(result.err)
+		if errCode == nil {
+			errCode = NewInternalErr(result.err)
+		}
+		if result.label != "" {
+			errCode = LabeledErrCode{Label: result.label, Err: errCode}
+		}
+		codes = append(codes, errCode)
+	}
+	switch len(codes) {
+	case 0:
+		return nil
+	case 1:
+		return codes[0]
+	default:
+		return Combine(codes[0], codes[1:]...)
+	}
+}
+
+func callRecover(fn func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = RecoverToErrorCode(recovered)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,71 @@
+package errcode_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestGroup(t *testing.T) {
+	var group errcode.Group
+	if group.Wait() != nil {
+		t.Error("expected nil without functions")
+	}
+
+	group.SetLimit(2)
+	var running, maxRunning atomic.Int32
+	for _, item := range []string{"a", "b", "c", "d"} {
+		item := item
+		group.GoLabeled(item, func() error {
+			if n := running.Add(1); n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			defer running.Add(-1)
+			time.Sleep(time.Millisecond)
+			switch item {
+			case "a":
+				// finish last to check that the order is the order of GoLabeled
+				time.Sleep(5 * time.Millisecond)
+				return errcode.NewNotFoundErr(errors.New("no a"))
+			case "c":
+				return errors.New("uncoded")
+			case "d":
+				panic("boom")
+			}
+			return nil
+		})
+	}
+	errCode := group.Wait()
+	if maxRunning.Load() > 2 {
+		t.Errorf("expected at most 2 goroutines, got %d", maxRunning.Load())
+	}
+	members := errors.Errors(errCode)
+	var labels []string
+	for _, member := range members {
+		labels = append(labels, errcode.Label(member))
+	}
+	if len(labels) != 3 || labels[0] != "a" || labels[1] != "c" || labels[2] != "d" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+	if !errcode.HasCode(errCode, errcode.NotFoundCode) || !errcode.HasCode(errCode, errcode.InternalCode) {
+		t.Errorf("unexpected codes %v", errCode)
+	}
+	var panicErr errcode.PanicErr
+	if !errors.As(members[2], &panicErr) {
+		t.Errorf("expected the panic to be recovered, got %v", errCode)
+	}
+
+	single, ctx := errcode.GroupWithContext(context.Background())
+	single.Go(func() error { return errcode.NewGoneErr(errors.New("deleted")) })
+	single.Go(func() error { return nil })
+	if errCode := single.Wait(); errCode == nil || errCode.Code().CodeStr() != errcode.GoneCode.CodeStr() {
+		t.Errorf("expected the single error, got %v", errCode)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the context to be canceled by Wait")
+	}
+}
@@ -45,6 +45,7 @@ package errcode
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gregwebs/errors"
 )
@@ -82,17 +83,23 @@ func (code Code) CodeStr() CodeStr {
 // NewCode creates a new top-level code.
 // A top-level code must not contain any dot separators: that will panic
 // Most codes should be created from hierachry with the Child method.
+// The code is registered in the DefaultRegistry.
+// With SetStrictCodeStrs, an invalid CodeStr (see CodeStr.Validate) panics.
 func NewCode(codeRep CodeStr) Code {
 	code := Code{codeStr: codeRep}
 	if err := code.checkCodePath(); err != nil {
 		panic(err)
 	}
+	code.checkStrict()
+	defaultRegistry.Register(code)
 	return code
 }
 
 // Child creates a new code from a parent.
 // For documentation purposes, a childStr may include the parent codes with dot-separation.
 // An incorrect parent reference in the string panics.
+// The code is registered in the DefaultRegistry.
+// With SetStrictCodeStrs, an invalid full CodeStr (see CodeStr.Validate) panics.
 func (code Code) Child(childStr CodeStr) Code {
 	child := Code{codeStr: childStr, Parent: &code}
 	if err := child.checkCodePath(); err != nil {
@@ -101,6 +108,8 @@ func (code Code) Child(childStr CodeStr) Code {
 	// Don't store parent paths, those are re-constructed in CodeStr()
 	paths := strings.Split(child.codeStr.String(), ".")
 	child.codeStr = CodeStr(paths[len(paths)-1])
+	child.checkStrict()
+	defaultRegistry.Register(child)
 	return child
 }
 
@@ -116,8 +125,18 @@ func (code Code) findAncestor(test func(Code) bool) *Code {
 }
 
 // IsAncestor looks for the given code in its ancestors.
+// A code is considered to be its own ancestor.
+// Codes are compared by their CodeStr.
+// For a code from Rebase, the ancestors of the code it was rebased from are also its ancestors.
 func (code Code) IsAncestor(ancestorCode Code) bool {
-	return nil != code.findAncestor(func(an Code) bool { return an == ancestorCode })
+	ancestorStr := ancestorCode.CodeStr()
+	return nil != code.findAncestor(func(an Code) bool {
+		if an.CodeStr() == ancestorStr {
+			return true
+		}
+		origin, ok := an.MountOrigin()
+		return ok && codeFromStr(origin).IsAncestor(ancestorCode)
+	})
 }
 
 // ErrorCode is the interface that ties an error and RegisteredCode together.
@@ -183,6 +202,11 @@ func (wrapped wrappedErrorCode[Wrapped]) Code() Code {
 	return wrapped.ErrorCode.Code()
 }
 
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (wrapped wrappedErrorCode[Wrapped]) Is(target error) bool {
+	return isCodeTarget(wrapped.Code(), target)
+}
+
 // Error fulfills the ErrorCode interface
 func (wrapped wrappedErrorCode[Wrapped]) Error() string {
 	return wrapped.Err.Error()
@@ -197,10 +221,25 @@ func (wrapped wrappedErrorCode[Wrapped]) Unwrapped() Wrapped {
 	return wrapped.ErrorCode
 }
 
+func (wrapped wrappedErrorCode[Wrapped]) wrapsMessage() {}
+
+// messageWrapper is implemented by the result of Wrap, Wrapf, and Wraps, which only add a message to an ErrorCode.
+type messageWrapper interface {
+	ErrorCode
+	wrapsMessage()
+}
+
 // Wrap is a convenience that calls errors.Wrap but still returns the ErrorCode interface
+// An ErrorCode that already has a stack trace (see HasStack) is wrapped with errors.WithMessage instead,
+// so that a second stack trace is not captured.
 // If a nil ErrorCode is given it will be returned as nil
 func Wrap[EC ErrorCode](errCode EC, msg string) ErrorCodeWrap[EC] {
-	err := errors.Wrap(errCode, msg)
+	var err error
+	if HasStack(errCode) {
+		err = errors.WithMessage(errCode, msg)
+	} else {
+		err = errors.Wrap(errCode, msg)
+	}
 	if err == nil {
 		return nil
 	}
@@ -211,9 +250,15 @@ func Wrap[EC ErrorCode](errCode EC, msg string) ErrorCodeWrap[EC] {
 }
 
 // Wrapf is a convenience that calls errors.Wrapf but still returns the ErrorCode interface
+// As with Wrap, a second stack trace is not captured.
 // If a nil ErrorCode is given it will be returned as nil
 func Wrapf[EC ErrorCode](errCode EC, msg string, args ...interface{}) ErrorCodeWrap[EC] {
-	err := errors.Wrapf(errCode, msg, args...)
+	var err error
+	if HasStack(errCode) {
+		err = errors.WithMessage(errCode, fmt.Sprintf(msg, args...))
+	} else {
+		err = errors.Wrapf(errCode, msg, args...)
+	}
 	if err == nil {
 		return nil
 	}
@@ -245,23 +290,17 @@ type HasClientData interface {
 }
 
 // ClientData retrieves data from a structure that implements HasClientData
-// It will unwrap errors to look for HasClientData
+// It will unwrap errors to look for HasClientData, including the members of groups in the order given by ErrorCodes.
 // Normally this function is used rather than GetClientData.
+// The data is given to the Redactor set with SetRedactor.
 func ClientData(errCode ErrorCode) interface{} {
-	if hasData, ok := errCode.(HasClientData); ok {
+	return redact(findClientData(errCode))
+}
+
+func findClientData(errCode ErrorCode) interface{} {
+	if hasData, ok := findDeep[HasClientData](errCode); ok {
 		return hasData.GetClientData()
 	}
-	var err error = errCode
-	for {
-		if un, ok := err.(unwrapError); ok {
-			err = un.Unwrap()
-			if hasData, ok := err.(HasClientData); ok {
-				return hasData.GetClientData()
-			}
-		} else {
-			break
-		}
-	}
 	return nil
 }
 
@@ -275,14 +314,32 @@ func ClientData(errCode ErrorCode) interface{} {
 // The Operation field may be missing, and the Data field may be empty.
 //
 // The rest of the fields may be populated sparsely depending on the application:
-// * Stack is a stack trace. This is only given for internal errors.
+// * Stack is the stack trace of the error (see the IncludeStack option). By default only internal errors have one.
 // * Others gives other errors that occurred (perhaps due to parallel requests).
+// * Label identifies which member of a group produced the error (see CombineLabeled).
+// * Path gives the position of one of the Others in the groups of the error (see the IncludePaths option).
+// * Doc is a link to the documentation of the code (see the DocLinks option).
+// * Operations is the OperationChain of the error (see the IncludeOperations option).
+// * RequestID correlates the error with a request (see WithRequestID).
+// * Origin is the service or component that produced the error (see SetServiceName and WithOrigin).
+// * Time is when the error was created (see SetCaptureTimestamps and WithTimestamp).
+// * OthersOmitted is the number of other errors left out by the MaxOthers option.
 type JSONFormat struct {
-	Code      CodeStr      `json:"code"`
-	Msg       string       `json:"msg"`
-	Data      interface{}  `json:"data"`
-	Operation string       `json:"operation,omitempty"`
-	Others    []JSONFormat `json:"others,omitempty"`
+	Code       CodeStr      `json:"code"`
+	Msg        string       `json:"msg"`
+	Data       interface{}  `json:"data"`
+	Operation  string       `json:"operation,omitempty"`
+	Operations []string     `json:"operations,omitempty"`
+	Label      string       `json:"label,omitempty"`
+	Path       []int        `json:"path,omitempty"`
+	RequestID  string       `json:"request_id,omitempty"`
+	Origin     string       `json:"origin,omitempty"`
+	Time       *time.Time   `json:"time,omitempty"`
+	Doc        string       `json:"doc,omitempty"`
+	Stack      []Frame      `json:"stack,omitempty"`
+	Others     []JSONFormat `json:"others,omitempty"`
+
+	OthersOmitted int `json:"others_omitted,omitempty"`
 }
 
 // OperationClientData gives the results of both the ClientData and Operation functions.
@@ -300,28 +357,36 @@ func OperationClientData(errCode ErrorCode) (string, interface{}) {
 
 // NewJSONFormat turns an ErrorCode into a JSONFormat.
 // You can create your own json struct and write your own version of this function.
-func NewJSONFormat(errCode ErrorCode) JSONFormat {
-	// Gather up multiple errors.
-	// We discard any that are not ErrorCode.
-	errorCodes := ErrorCodes(errCode)[1:]
+// Others is filled in the order given by ErrorCodes.
+// The NestOthers, DedupeOthers, and MaxOthers options change how Others is filled.
+// The Msg is the user message, or the default user message of the code, or else Error().
+// The options are also applied to Others.
+func NewJSONFormat(errCode ErrorCode, opts ...FormatOption) JSONFormat {
+	config := newFormatConfig(opts)
+	errorCodes, omitted := config.others(errCode)
 	others := make([]JSONFormat, len(errorCodes))
-	for i, err := range errorCodes {
-		others[i] = NewJSONFormat(err)
+	for i, other := range errorCodes {
+		others[i] = NewJSONFormat(other.ErrCode, opts...)
+		others[i].Path = other.Path
 	}
 
-	op, data := OperationClientData(errCode)
-
-	msg := GetUserMsg(errCode)
-	if msg == "" {
-		msg = errCode.Error()
-	}
+	op, data := config.operationClientData(errCode)
 
 	return JSONFormat{
-		Data:      data,
-		Msg:       msg,
-		Code:      errCode.Code().CodeStr(),
-		Operation: op,
-		Others:    others,
+		Data:       data,
+		Msg:        config.userMsg(errCode),
+		Code:       errCode.Code().CodeStr(),
+		Operation:  op,
+		Operations: config.operationChain(errCode),
+		Label:      Label(errCode),
+		RequestID:  RequestID(errCode),
+		Origin:     originOf(errCode),
+		Time:       timestampOf(errCode),
+		Doc:        config.docLink(errCode.Code()),
+		Stack:      config.stackFrames(errCode),
+		Others:     others,
+
+		OthersOmitted: omitted,
 	}
 }
 
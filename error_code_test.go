@@ -16,6 +16,7 @@ package errcode_test
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
 
@@ -564,3 +565,116 @@ func AssertStackEquals(t *testing.T, given errcode.ErrorCode, stExpected errors.
 		t.Errorf("\nStack expected: %#v\n Stack but got: %#v", stExpected[0], stGiven[0])
 	}
 }
+
+func TestRepeatedWrapping(t *testing.T) {
+	op := errcode.Op("fetch")
+	opErr := op.AddTo(op.AddTo(MinimalError{}))
+	ErrorEquals(t, opErr, "fetch: error")
+	if _, ok := opErr.Err.(MinimalError); !ok {
+		t.Errorf("expected the operation to be added once, got %#v", opErr)
+	}
+	OpEquals(t, opErr, "fetch")
+
+	// the same operation through another wrapper
+	stacked := errcode.NewStackCode(op.AddTo(MinimalError{}))
+	ErrorEquals(t, op.AddTo(stacked), "fetch: error")
+	// different operations are kept
+	ErrorEquals(t, errcode.Op("get").AddTo(op.AddTo(MinimalError{})), "get: fetch: error")
+	ErrorEquals(t, op.AddTo(errcode.Op("get").AddTo(op.AddTo(MinimalError{}))), "fetch: get: fetch: error")
+	// an embedded operation does not prefix Error()
+	ErrorEquals(t, errcode.Op("field").AddTo(OpErrorEmbed{EmbedOp: errcode.EmbedOp{Op: "field"}}), "field: error")
+
+	um := errcode.UserMsg("try again")
+	userErr := um.AddTo(um.AddTo(MinimalError{}))
+	ErrorEquals(t, userErr, "try again: error")
+	UserMsgEquals(t, userErr, "try again")
+	if _, ok := userErr.(errcode.UserMsgErrCode).Err.(MinimalError); !ok {
+		t.Errorf("expected the user message to be added once, got %#v", userErr)
+	}
+	ErrorEquals(t, um.AddTo(op.AddTo(um.AddTo(MinimalError{}))), "try again: fetch: try again: error")
+	ClientDataResult(t, op.AddTo(userErr), clientDataResult{
+		operation: "fetch",
+		codeStr:   codeString,
+	})
+}
+
+func TestBuiltinHTTPCodes(t *testing.T) {
+	uncoded := fmt.Errorf("uncoded")
+	for _, test := range []struct {
+		errCode errcode.ErrorCode
+		status  int
+	}{
+		{errcode.NewPaymentRequiredErr(uncoded), 402},
+		{errcode.NewGoneErr(uncoded), 410},
+		{errcode.NewPayloadTooLargeErr(uncoded, 10, 0), 413},
+		{errcode.NewConflictErr(uncoded), 409},
+		{errcode.NewPreconditionFailedErr(uncoded), 412},
+		{errcode.NewMethodNotAllowedErr(uncoded, "PUT", "GET"), 405},
+		{errcode.NewQuotaErr(uncoded, errcode.QuotaStorageCode, 11, 10), 507},
+		{errcode.NewQuotaErr(uncoded, errcode.QuotaRateCode, 1000, 1000), 429},
+	} {
+		if status := test.errCode.Code().HTTPCode(); status != test.status {
+			t.Errorf("expected %d for %v, got %d", test.status, test.errCode.Code(), status)
+		}
+	}
+}
+
+func TestQuotaErr(t *testing.T) {
+	errCode := errcode.NewQuotaErr(errors.New("too many projects"), errcode.QuotaExceededCode, 5, 5)
+	if codeStr := errCode.Code().CodeStr(); codeStr != "quota.exceeded" {
+		t.Errorf("unexpected code %s", codeStr)
+	}
+	if data, ok := errcode.ClientData(errCode).(errcode.QuotaData); !ok || data != (errcode.QuotaData{Current: 5, Limit: 5}) {
+		t.Errorf("unexpected client data %#v", errcode.ClientData(errCode))
+	}
+	if !errcode.IsQuota(errcode.Op("create").AddTo(errCode)) || errcode.IsQuota(errcode.NewRateLimitErr(errors.New("slow down"), 0)) {
+		t.Error("expected only the quota error to be a quota error")
+	}
+}
+
+func TestFromHTTPStatus(t *testing.T) {
+	for _, test := range []struct {
+		status int
+		code   errcode.Code
+	}{
+		{404, errcode.NotFoundCode},
+		{503, errcode.UnavailableCode},
+		{504, errcode.TimeoutGatewayCode},
+		{429, errcode.TooManyRequestsCode},
+		{418, errcode.InvalidInputCode},
+		{502, errcode.InternalCode},
+		{507, errcode.QuotaStorageCode},
+	} {
+		errCode := errcode.FromHTTPStatus(test.status, nil)
+		if errCode.Code().CodeStr() != test.code.CodeStr() {
+			t.Errorf("expected %v for %d, got %v", test.code, test.status, errCode.Code())
+		}
+	}
+	if msg := errcode.FromHTTPStatus(404, nil).Error(); msg != "HTTP 404 Not Found" {
+		t.Errorf("unexpected message %s", msg)
+	}
+	if code := errcode.FromHTTPStatus(500, errcode.NewGoneErr(errors.New("gone"))).Code(); code.CodeStr() != errcode.GoneCode.CodeStr() {
+		t.Errorf("expected the existing code, got %v", code)
+	}
+}
+
+func TestCodeErr(t *testing.T) {
+	err := errcode.NotFoundCode.Err("no item")
+	ErrorEquals(t, err, "no item")
+	if err.Code() != errcode.NotFoundCode || errcode.StackTrace(err) != nil {
+		t.Errorf("expected the not found code without a stack trace, got %v", err.Code().CodeStr())
+	}
+
+	internal := errcode.InternalCode.Errf("query %s: %w", "items", io.ErrUnexpectedEOF)
+	ErrorEquals(t, internal, "query items: unexpected EOF")
+	if !errors.Is(internal, io.ErrUnexpectedEOF) {
+		t.Error("expected the wrapped error to be found")
+	}
+	stack := errcode.StackTrace(internal)
+	if len(stack) == 0 {
+		t.Fatal("expected a stack trace for an internal code")
+	}
+	if top := fmt.Sprintf("%n", stack[0]); top != "TestCodeErr" {
+		t.Errorf("expected the stack to start at the caller, got %s", top)
+	}
+}
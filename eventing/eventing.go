@@ -0,0 +1,190 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eventing gives a stable error envelope for messages that failed asynchronous processing,
+// for example messages sent to an AMQP or Kafka dead-letter queue.
+//
+// The envelope is given both as message headers and as a JSON body
+// so that consumers can classify failures by code without decoding the body.
+// Decode converts the headers and body of a message back into an Envelope and its ErrorCode.
+package eventing
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gregwebs/errcode"
+)
+
+// The headers of an envelope.
+// Header values are strings, which can be converted to the header type of a broker.
+const (
+	HeaderCode      = "errcode-code"
+	HeaderRetryable = "errcode-retryable"
+	HeaderOrigin    = "errcode-origin"
+	HeaderTimestamp = "errcode-timestamp"
+)
+
+// Envelope describes an error for a dead-letter queue.
+//
+// * Code and Msg are from the JSONFormat of the error.
+// * Retryable tells if processing the message again may succeed (see IsRetryable).
+// * Origin is the service that failed to process the message, by default the Origin of the JSONFormat.
+// * Timestamp is when the error occurred.
+// * Error is the JSONFormat of the error with its data and other errors.
+type Envelope struct {
+	Code      errcode.CodeStr    `json:"code"`
+	Msg       string             `json:"msg"`
+	Retryable bool               `json:"retryable"`
+	Origin    string             `json:"origin,omitempty"`
+	Timestamp time.Time          `json:"timestamp"`
+	Error     errcode.JSONFormat `json:"error"`
+}
+
+type config struct {
+	origin        string
+	now           func() time.Time
+	retryable     func(errcode.ErrorCode) bool
+	formatOptions []errcode.FormatOption
+}
+
+// Option configures NewEnvelope.
+type Option func(*config)
+
+// Origin sets the service that failed to process the message.
+// Without it, the Origin of the JSONFormat of the error is used.
+func Origin(service string) Option {
+	return func(c *config) {
+		c.origin = service
+	}
+}
+
+// Now sets the clock for the Timestamp. The default is time.Now.
+func Now(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+// RetryPolicy replaces IsRetryable for deciding if an error is retryable.
+func RetryPolicy(retryable func(errcode.ErrorCode) bool) Option {
+	return func(c *config) {
+		c.retryable = retryable
+	}
+}
+
+// WithFormat sets the options given to NewJSONFormat.
+func WithFormat(opts ...errcode.FormatOption) Option {
+	return func(c *config) {
+		c.formatOptions = append(c.formatOptions, opts...)
+	}
+}
+
+// IsRetryable is the default retry policy.
+// An error is retryable if it has a retry time (see errcode.RetryAfter and errcode.RetryAt)
+// or its code is unavailable, timeout, or too many requests.
+func IsRetryable(errCode errcode.ErrorCode) bool {
+	if errcode.RetryAfter(errCode) > 0 || !errcode.RetryAt(errCode).IsZero() {
+		return true
+	}
+	if errcode.IsUnavailable(errCode) || errcode.IsTimeout(errCode) {
+		return true
+	}
+	return errcode.CodeIs(errcode.TooManyRequestsCode).Matches(errCode.Code())
+}
+
+// NewEnvelope creates an Envelope for an error.
+// The ErrorCode of the error is resolved with CodeChain.
+// An error without an ErrorCode is converted with NewInternalErr.
+func NewEnvelope(err error, opts ...Option) Envelope {
+	c := config{now: time.Now, retryable: IsRetryable}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		errCode = errcode.NewInternalErr(err)
+	}
+	format := errcode.NewJSONFormat(errCode, c.formatOptions...)
+	if c.origin == "" {
+		c.origin = format.Origin
+	}
+	return Envelope{
+		Code:      format.Code,
+		Msg:       format.Msg,
+		Retryable: c.retryable(errCode),
+		Origin:    c.origin,
+		Timestamp: c.now().UTC(),
+		Error:     format,
+	}
+}
+
+// Headers gives the message headers of the envelope.
+// The timestamp is in RFC 3339 format.
+func (e Envelope) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderCode:      e.Code.String(),
+		HeaderRetryable: strconv.FormatBool(e.Retryable),
+		HeaderTimestamp: e.Timestamp.Format(time.RFC3339Nano),
+	}
+	if e.Origin != "" {
+		headers[HeaderOrigin] = e.Origin
+	}
+	return headers
+}
+
+// Marshal gives the JSON body of the envelope.
+func (e Envelope) Marshal() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// ErrorCode reconstructs the error of the envelope as an errcode.RemoteErr.
+func (e Envelope) ErrorCode() errcode.ErrorCode {
+	format := e.Error
+	if format.Code == "" {
+		format.Code = e.Code
+		format.Msg = e.Msg
+	}
+	return errcode.NewRemoteErr(format, nil)
+}
+
+// ErrNoEnvelope is returned by Decode when a message has neither envelope headers nor a body.
+var ErrNoEnvelope = errors.New("eventing: no error envelope")
+
+// Decode gives the Envelope of a message from its JSON body.
+// When the body is empty, the envelope is built from the headers
+// so that a consumer can still classify a message whose body was not kept.
+func Decode(headers map[string]string, body []byte) (Envelope, error) {
+	var envelope Envelope
+	if len(body) > 0 {
+		err := json.Unmarshal(body, &envelope)
+		return envelope, err
+	}
+	code := headers[HeaderCode]
+	if code == "" {
+		return envelope, ErrNoEnvelope
+	}
+	envelope.Code = errcode.CodeStr(code)
+	envelope.Origin = headers[HeaderOrigin]
+	envelope.Retryable, _ = strconv.ParseBool(headers[HeaderRetryable])
+	if timestamp := headers[HeaderTimestamp]; timestamp != "" {
+		t, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			return envelope, err
+		}
+		envelope.Timestamp = t
+	}
+	return envelope, nil
+}
@@ -0,0 +1,78 @@
+package eventing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/eventing"
+	"github.com/gregwebs/errors"
+)
+
+func TestEnvelope(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := errcode.Op("orders.process").AddTo(errcode.NewNotFoundErr(errors.New("no order")))
+	envelope := eventing.NewEnvelope(err, eventing.Origin("orders"), eventing.Now(func() time.Time { return now }))
+	if envelope.Code != errcode.NotFoundCode.CodeStr() || envelope.Retryable || envelope.Origin != "orders" || !envelope.Timestamp.Equal(now) {
+		t.Errorf("unexpected envelope %+v", envelope)
+	}
+
+	headers := envelope.Headers()
+	body, marshalErr := envelope.Marshal()
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	decoded, decodeErr := eventing.Decode(headers, body)
+	if decodeErr != nil {
+		t.Fatal(decodeErr)
+	}
+	errCode := decoded.ErrorCode()
+	if errCode.Code().CodeStr() != errcode.NotFoundCode.CodeStr() || errcode.Operation(errCode) != "orders.process" {
+		t.Errorf("unexpected error %v", errCode)
+	}
+
+	fromHeaders, decodeErr := eventing.Decode(headers, nil)
+	if decodeErr != nil {
+		t.Fatal(decodeErr)
+	}
+	if fromHeaders.Code != envelope.Code || fromHeaders.Origin != "orders" || !fromHeaders.Timestamp.Equal(now) {
+		t.Errorf("unexpected envelope from headers %+v", fromHeaders)
+	}
+	if errCode := fromHeaders.ErrorCode(); errCode.Code().CodeStr() != errcode.NotFoundCode.CodeStr() {
+		t.Errorf("unexpected error from headers %v", errCode)
+	}
+	if _, err := eventing.Decode(nil, nil); err != eventing.ErrNoEnvelope {
+		t.Errorf("expected ErrNoEnvelope, got %v", err)
+	}
+}
+
+func TestEnvelopeOrigin(t *testing.T) {
+	err := errcode.WithOrigin("billing", errcode.NewNotFoundErr(errors.New("no invoice")))
+	if envelope := eventing.NewEnvelope(err); envelope.Origin != "billing" {
+		t.Errorf("expected the origin of the error, got %q", envelope.Origin)
+	}
+	if envelope := eventing.NewEnvelope(err, eventing.Origin("orders")); envelope.Origin != "orders" {
+		t.Errorf("expected the Origin option, got %q", envelope.Origin)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, test := range []struct {
+		err       errcode.ErrorCode
+		retryable bool
+	}{
+		{errcode.NewNotFoundErr(errors.New("no order")), false},
+		{errcode.NewInternalErr(errors.New("bug")), false},
+		{errcode.NewUnavailableErr(errors.New("down")), true},
+		{errcode.NewRateLimitErr(errors.New("slow down"), time.Second), true},
+		{errcode.WithRetryAfter(time.Second, errcode.NewInternalErr(errors.New("busy"))), true},
+	} {
+		if retryable := eventing.IsRetryable(test.err); retryable != test.retryable {
+			t.Errorf("expected %v for %v", test.retryable, test.err)
+		}
+	}
+	envelope := eventing.NewEnvelope(errors.New("plain"), eventing.RetryPolicy(func(errcode.ErrorCode) bool { return true }))
+	if !envelope.Retryable || envelope.Code != errcode.InternalCode.CodeStr() {
+		t.Errorf("unexpected envelope %+v", envelope)
+	}
+}
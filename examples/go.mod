@@ -0,0 +1,32 @@
+module github.com/gregwebs/errcode/examples
+
+go 1.21.9
+
+require (
+	github.com/gregwebs/errcode v0.11.0
+	github.com/gregwebs/errcode/goa v0.11.0
+	github.com/gregwebs/errcode/grpc v0.11.0
+	github.com/gregwebs/errors v1.5.0
+	goa.design/goa/v3 v3.10.0
+	google.golang.org/grpc v1.58.2
+)
+
+require (
+	github.com/dimfeld/httptreemux/v5 v5.4.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/hashicorp/errwrap v1.1.0 // indirect
+	github.com/hashicorp/go-multierror v1.1.1 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+)
+
+replace github.com/gregwebs/errcode => ../
+
+replace github.com/gregwebs/errcode/goa => ../goa
+
+replace github.com/gregwebs/errcode/grpc => ../grpc
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/gregwebs/errcode"
+	errgrpc "github.com/gregwebs/errcode/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// The codes of the service.
+// Codes are created once at package initialization, extending the built-in hierarchy,
+// and are registered in errcode.DefaultRegistry for documentation.
+var (
+	// OutOfStockCode is returned when an item cannot be ordered.
+	// It inherits HTTP 400 from StateCode.
+	OutOfStockCode = errgrpc.SetCode(
+		errcode.StateCode.Child("state.outofstock").
+			SetDescription("The item is out of stock").
+			SetRemediation("Order a different item or try again later").
+			SetDefaultUserMsg("That item is out of stock"),
+		codes.FailedPrecondition,
+	)
+
+	// UnknownItemCode is returned when an item does not exist.
+	UnknownItemCode = errcode.NotFoundCode.Child("missing.item").
+			SetDescription("The item does not exist")
+)
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gregwebs/errcode"
+	errgrpc "github.com/gregwebs/errcode/grpc"
+	"google.golang.org/grpc"
+)
+
+// unaryServerInterceptor converts returned errors to a gRPC status with the code from errgrpc.SetCode.
+// Errors are reported with Report.
+func unaryServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		errCode = errcode.NewInternalErr(err)
+	}
+	Report(ctx, errCode)
+	return resp, errgrpc.WrapAsGRPC(errCode)
+}
+
+// NewGRPCServer creates a gRPC server that converts errors with codes.
+// While draining, requests are rejected with an unavailable status.
+// A panic in a handler is converted with errcode.RecoverToErrorCode and reported.
+// Services generated from protobuf definitions are registered on it.
+func NewGRPCServer() *grpc.Server {
+	return grpc.NewServer(grpc.ChainUnaryInterceptor(
+		errgrpc.DrainUnaryServerInterceptor,
+		unaryServerInterceptor,
+		errgrpc.RecoverUnaryServerInterceptor,
+	))
+}
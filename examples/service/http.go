@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gregwebs/errcode"
+	errgoa "github.com/gregwebs/errcode/goa"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+	goahttp "goa.design/goa/v3/http"
+)
+
+// NewHTTPHandler serves the store over HTTP.
+// Errors are written as JSONFormat by httperr and reported with Report.
+func NewHTTPHandler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/order", reporting(func(w http.ResponseWriter, r *http.Request) error {
+		item := r.URL.Query().Get("item")
+		if item == "" {
+			return errcode.NewInvalidInputErr(errors.New("item is required"))
+		}
+		if err := store.Order(r.Context(), item); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+	return mux
+}
+
+// reporting reports errors before they are written.
+// The user message format option keeps Error() messages from leaking to clients.
+// Client disconnects are not reported as internal errors.
+func reporting(handler httperr.HandlerFunc) http.Handler {
+	return httperr.NewHandler(handler,
+		httperr.OnError(func(r *http.Request, errCode errcode.ErrorCode) {
+			Report(r.Context(), errCode)
+		}),
+		httperr.WithFormat(errcode.RequireUserMsg()),
+		httperr.DetectClientDisconnect(),
+	)
+}
+
+// GoaErrorEncoder is the error encoder for goa generated servers.
+// Pass it to the generated server constructor, for example:
+//
+//	server := ordersvr.New(endpoints, mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, GoaErrorEncoder, nil)
+func GoaErrorEncoder(ctx context.Context, w http.ResponseWriter, err error) error {
+	return goahttp.ErrorEncoder(goahttp.ResponseEncoder, goaFormatter)(ctx, w, err)
+}
+
+// goaFormatter converts goa errors, such as validation errors, to error codes.
+func goaFormatter(ctx context.Context, err error) goahttp.Statuser {
+	response := errgoa.ErrorResponse(err)
+	Report(ctx, response)
+	return response
+}
@@ -0,0 +1,61 @@
+// Command service is an example service that uses error codes in every layer.
+//
+//   - codes.go defines the codes of the service with their metadata
+//   - store.go is the domain logic returning errors with codes
+//   - http.go serves HTTP with httperr and encodes goa errors
+//   - grpc.go converts errors to gRPC statuses in an interceptor
+//   - report.go logs errors with slog and counts them by code with expvar
+//
+// Run it with:
+//
+//	go run . -http :8080 -grpc :9090
+//	curl -i 'localhost:8080/order?item=widget'
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/gregwebs/errcode"
+)
+
+func main() {
+	httpAddr := flag.String("http", ":8080", "HTTP listen address")
+	grpcAddr := flag.String("grpc", ":9090", "gRPC listen address")
+	docs := flag.Bool("docs", false, "print the documentation of the error codes and exit")
+	flag.Parse()
+
+	if *docs {
+		markdown, err := errcode.GenerateDocs(errcode.DefaultRegistry(), errcode.DocMarkdown)
+		if err != nil {
+			slog.Error("generating docs", "error", err)
+			os.Exit(1)
+		}
+		_, _ = os.Stdout.Write(markdown)
+		return
+	}
+
+	store := NewStore(map[string]int{"widget": 3, "gadget": 0})
+
+	listener, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		slog.Error("listening for gRPC", "error", err)
+		os.Exit(1)
+	}
+	grpcServer := NewGRPCServer()
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			slog.Error("serving gRPC", "error", err)
+		}
+	}()
+
+	http.Handle("/", NewHTTPHandler(store))
+	slog.Info("serving HTTP", "addr", *httpAddr)
+	if err := http.ListenAndServe(*httpAddr, nil); err != nil {
+		slog.Error("serving HTTP", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+
+	"github.com/gregwebs/errcode"
+)
+
+// errorCounts is a metric of the number of errors by CodeStr.
+// Codes that are excluded from error rates are not counted.
+// It is published by expvar at /debug/vars.
+var errorCounts = expvar.NewMap("errors")
+
+// Report logs an error and records it in the metrics.
+// Every transport reports errors here so that they are handled consistently.
+func Report(ctx context.Context, errCode errcode.ErrorCode) {
+	code := errCode.Code()
+	if !code.ExcludedFromErrorRate() {
+		errorCounts.Add(code.CodeStr().String(), 1)
+	}
+
+	level := slog.LevelWarn
+	switch code.Severity() {
+	case errcode.SeverityError, errcode.SeverityFatal:
+		level = slog.LevelError
+	case errcode.SeverityInfo:
+		level = slog.LevelInfo
+	case errcode.SeverityDebug:
+		level = slog.LevelDebug
+	}
+	attrs := []slog.Attr{slog.String("code", code.CodeStr().String())}
+	if op := errcode.Operation(errCode); op != "" {
+		attrs = append(attrs, slog.String("operation", op))
+	}
+	slog.LogAttrs(ctx, level, errCode.Error(), attrs...)
+}
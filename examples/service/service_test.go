@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/errcodetest"
+	errgrpc "github.com/gregwebs/errcode/grpc"
+	goalib "goa.design/goa/v3/pkg"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	errcodetest.SetSamples(OutOfStockCode, errcodetest.Sample{Method: "POST", Path: "/order?item=gadget"})
+	errcodetest.SetSamples(UnknownItemCode, errcodetest.Sample{Method: "POST", Path: "/order?item=unknown"})
+	errcodetest.SetSamples(errcode.InvalidInputCode, errcodetest.Sample{Method: "POST", Path: "/order"})
+}
+
+func TestHTTPContract(t *testing.T) {
+	server := httptest.NewServer(NewHTTPHandler(NewStore(map[string]int{"gadget": 0})))
+	defer server.Close()
+	errcodetest.CheckContract(t, errcode.DefaultRegistry(), server)
+
+	resp, err := server.Client().Post(server.URL+"/order?item=gadget", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body errcode.JSONFormat
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Msg != "That item is out of stock" || body.Operation != "order" {
+		t.Errorf("unexpected body %+v", body)
+	}
+	if errorCounts.Get("state.outofstock") == nil {
+		t.Error("expected the error to be counted")
+	}
+}
+
+func TestGRPCInterceptor(t *testing.T) {
+	store := NewStore(map[string]int{"gadget": 0})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, store.Order(ctx, req.(string))
+	}
+	_, err := unaryServerInterceptor(context.Background(), "gadget", &grpc.UnaryServerInfo{}, handler)
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", code)
+	}
+}
+
+func TestGRPCServerPanic(t *testing.T) {
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		panic("boom")
+	}
+	recovering := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return errgrpc.RecoverUnaryServerInterceptor(ctx, req, &grpc.UnaryServerInfo{}, handler)
+	}
+	_, err := unaryServerInterceptor(context.Background(), "gadget", &grpc.UnaryServerInfo{}, recovering)
+	if code := status.Code(err); code != codes.Internal {
+		t.Errorf("expected Internal, got %v", code)
+	}
+	var panicErr errcode.PanicErr
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("expected a PanicErr, got %v", err)
+	}
+}
+
+func TestGoaErrorEncoder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := GoaErrorEncoder(context.Background(), rec, goalib.MissingFieldError("item", "body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
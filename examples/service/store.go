@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+// Store is the domain logic of the service.
+// It returns errors with codes but knows nothing about transports.
+type Store struct {
+	mu    sync.Mutex
+	stock map[string]int
+}
+
+// NewStore creates a Store with the given stock.
+func NewStore(stock map[string]int) *Store {
+	return &Store{stock: stock}
+}
+
+// Order takes one of the item from the stock.
+func (s *Store) Order(_ context.Context, item string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count, ok := s.stock[item]
+	if !ok {
+		return errcode.NewCodedError(errors.Errorf("unknown item %s", item), UnknownItemCode)
+	}
+	if count == 0 {
+		return errcode.Op("order")(errcode.NewCodedError(errors.Errorf("no stock for %s", item), OutOfStockCode))
+	}
+	s.stock[item] = count - 1
+	return nil
+}
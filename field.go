@@ -0,0 +1,175 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import "strings"
+
+// FieldError is an error for a single field of the input, normally from validation.
+// Field is a path to the field, for example "address.street".
+// Msg should be friendly to end users.
+type FieldError struct {
+	Field   string
+	GetCode Code
+	Msg     string
+}
+
+// NewFieldError constructs a FieldError.
+// Use FieldErrors to aggregate multiple FieldError.
+func NewFieldError(field string, code Code, msg string) FieldError {
+	return FieldError{Field: field, GetCode: code, Msg: msg}
+}
+
+// FieldErrorData is the client data representation of a FieldError.
+type FieldErrorData struct {
+	Field string  `json:"field"`
+	Code  CodeStr `json:"code"`
+	Msg   string  `json:"msg"`
+}
+
+// Error gives the field and the message.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+// Code returns the GetCode field
+func (e FieldError) Code() Code {
+	return e.GetCode
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e FieldError) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+// GetUserMsg satisfies the [HasUserMsg] interface.
+func (e FieldError) GetUserMsg() string {
+	return e.Msg
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e FieldError) GetClientData() interface{} {
+	return e.data()
+}
+
+func (e FieldError) data() FieldErrorData {
+	return FieldErrorData{Field: e.Field, Code: e.GetCode.CodeStr(), Msg: e.Msg}
+}
+
+var _ ErrorCode = (*FieldError)(nil)     // assert implements interface
+var _ HasUserMsg = (*FieldError)(nil)    // assert implements interface
+var _ HasClientData = (*FieldError)(nil) // assert implements interface
+
+// FieldErrors aggregates errors for multiple fields.
+// The client data is an array of FieldErrorData.
+//
+//	var fieldErrs errcode.FieldErrors
+//	if name == "" {
+//		fieldErrs.Add("name", errcode.InvalidInputCode, "name is required")
+//	}
+//	return fieldErrs.ErrorOrNil()
+type FieldErrors struct {
+	Fields []FieldError
+}
+
+// NewFieldErrors constructs FieldErrors.
+func NewFieldErrors(fieldErrs ...FieldError) *FieldErrors {
+	return &FieldErrors{Fields: fieldErrs}
+}
+
+// Add a FieldError.
+func (e *FieldErrors) Add(field string, code Code, msg string) {
+	e.Fields = append(e.Fields, NewFieldError(field, code, msg))
+}
+
+// Append FieldError.
+func (e *FieldErrors) Append(fieldErrs ...FieldError) {
+	e.Fields = append(e.Fields, fieldErrs...)
+}
+
+// ErrorOrNil returns nil if there are no FieldError.
+// Otherwise it returns the FieldErrors.
+func (e *FieldErrors) ErrorOrNil() ErrorCode {
+	if e == nil || len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error gives all the field errors separated by a semi-colon.
+func (e *FieldErrors) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, fieldErr := range e.Fields {
+		msgs[i] = fieldErr.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Code gives the most specific code shared by all the field errors.
+// If there are no shared codes, it gives InvalidInputCode.
+func (e *FieldErrors) Code() Code {
+	if len(e.Fields) == 0 {
+		return InvalidInputCode
+	}
+	code := e.Fields[0].GetCode
+	for _, fieldErr := range e.Fields[1:] {
+		common := commonAncestor(code, fieldErr.GetCode)
+		if common == nil {
+			return InvalidInputCode
+		}
+		code = *common
+	}
+	return code
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e *FieldErrors) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+// Unwrap gives the individual FieldError.
+func (e *FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, fieldErr := range e.Fields {
+		errs[i] = fieldErr
+	}
+	return errs
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+// It gives a []FieldErrorData
+func (e *FieldErrors) GetClientData() interface{} {
+	data := make([]FieldErrorData, len(e.Fields))
+	for i, fieldErr := range e.Fields {
+		data[i] = fieldErr.data()
+	}
+	return data
+}
+
+// GetUserMsg satisfies the [HasUserMsg] interface.
+// It is empty: the user messages are given for each field by the client data.
+// This stops GetUserMsg from giving the user message of just the first field.
+func (e *FieldErrors) GetUserMsg() string {
+	return ""
+}
+
+var _ ErrorCode = (*FieldErrors)(nil)     // assert implements interface
+var _ HasClientData = (*FieldErrors)(nil) // assert implements interface
+var _ HasUserMsg = (*FieldErrors)(nil)    // assert implements interface
+
+// commonAncestor finds the most specific code that is an ancestor of (or equal to) both codes.
+func commonAncestor(code1 Code, code2 Code) *Code {
+	return code1.findAncestor(func(ancestor Code) bool {
+		return code2.IsAncestor(ancestor)
+	})
+}
@@ -0,0 +1,35 @@
+package errcode_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+)
+
+var requiredCode = errcode.InvalidInputCode.Child("input.required")
+var formatCode = errcode.InvalidInputCode.Child("input.format")
+
+func TestFieldErrors(t *testing.T) {
+	var fieldErrs errcode.FieldErrors
+	if fieldErrs.ErrorOrNil() != nil {
+		t.Errorf("expected nil")
+	}
+
+	fieldErrs.Add("name", requiredCode, "name is required")
+	AssertCode(t, fieldErrs.ErrorOrNil(), requiredCode.CodeStr())
+
+	fieldErrs.Add("address.zip", formatCode, "zip code must be 5 digits")
+	errCode := fieldErrs.ErrorOrNil()
+	AssertCodes(t, errCode, errcode.InvalidInputCode.CodeStr())
+	ErrorEquals(t, errCode, "name: name is required; address.zip: zip code must be 5 digits")
+	ClientDataEquals(t, errCode, []errcode.FieldErrorData{
+		{Field: "name", Code: requiredCode.CodeStr(), Msg: "name is required"},
+		{Field: "address.zip", Code: formatCode.CodeStr(), Msg: "zip code must be 5 digits"},
+	}, errcode.InvalidInputCode.CodeStr(), fieldErrs.Fields[0], fieldErrs.Fields[1])
+
+	codes := errcode.ErrorCodes(errCode)
+	AssertLength(t, codes, 3)
+
+	fieldErrs.Append(errcode.NewFieldError("id", errcode.NotFoundCode, "no such id"))
+	AssertCode(t, fieldErrs.ErrorOrNil(), errcode.InvalidInputCode.CodeStr())
+}
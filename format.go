@@ -0,0 +1,301 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gregwebs/errors"
+)
+
+type formatConfig struct {
+	requireUserMsg bool
+	translate      func(Code) (string, bool)
+	docBaseURL     string
+	nestOthers     bool
+	operations     bool
+	dedupeOthers   bool
+	limitOthers    bool
+	maxOthers      int
+	indentPrefix   string
+	indent         string
+	omitFields     map[string]struct{}
+
+	paths           bool
+	stack           bool
+	mergeClientData bool
+	mergeStrategy   MergeStrategy
+
+	// opts are kept for formatting the client data of a BatchError.
+	opts []FormatOption
+}
+
+// FormatOption configures NewJSONFormat and WriteJSON.
+type FormatOption func(*formatConfig)
+
+func newFormatConfig(opts []FormatOption) formatConfig {
+	config := formatConfig{opts: opts}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// RequireUserMsg prevents the Error() message from being used as the Msg of a JSONFormat.
+// Error() messages are for developers and may leak internal details to clients.
+//
+// When an error has no user message and its code has no default user message,
+// the Msg is GenericUserMsg for the HTTP code instead.
+// A warning with the code is logged with slog so that a user message can be added.
+func RequireUserMsg() FormatOption {
+	return func(c *formatConfig) {
+		c.requireUserMsg = true
+	}
+}
+
+// TranslateUserMsg gives the Msg of a JSONFormat from the translate function when it finds a message for the code.
+// It is applied to each of the Others and to the items of a BatchError as well.
+// Otherwise the Msg is given as without this option.
+// The i18n package uses this to localize user messages.
+func TranslateUserMsg(translate func(Code) (string, bool)) FormatOption {
+	return func(c *formatConfig) {
+		c.translate = translate
+	}
+}
+
+// DocLinks fills the Doc field of a JSONFormat with a link to the documentation of the code.
+// The link is the URL set with SetDocURL.
+// Otherwise it is the base URL joined with the CodeStr,
+// which matches the routes of the catalog handler in the httperr package.
+func DocLinks(baseURL string) FormatOption {
+	return func(c *formatConfig) {
+		c.docBaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func (c formatConfig) docLink(code Code) string {
+	if url := code.DocURL(); url != "" {
+		return url
+	}
+	if c.docBaseURL == "" {
+		return ""
+	}
+	return c.docBaseURL + "/" + code.CodeStr().String()
+}
+
+// IncludeOperations fills the Operations of a JSONFormat with the OperationChain of the error.
+// It is left empty when there is not more than the one operation already given as the Operation.
+func IncludeOperations() FormatOption {
+	return func(c *formatConfig) {
+		c.operations = true
+	}
+}
+
+func (c formatConfig) operationChain(errCode ErrorCode) []string {
+	if !c.operations {
+		return nil
+	}
+	if ops := OperationChain(errCode); len(ops) > 1 {
+		return ops
+	}
+	return nil
+}
+
+// NestOthers preserves the nesting of groups in the Others of a JSONFormat.
+// Others only has the members of the group of the error,
+// and a member that is itself a group has its members in its own Others.
+// By default, all the errors found by ErrorCodes are flattened into Others.
+func NestOthers() FormatOption {
+	return func(c *formatConfig) {
+		c.nestOthers = true
+	}
+}
+
+// DedupeOthers leaves out of Others an error with the same code as the error or an earlier member of Others.
+// The first error with a code is kept.
+func DedupeOthers() FormatOption {
+	return func(c *formatConfig) {
+		c.dedupeOthers = true
+	}
+}
+
+// IncludePaths fills the Path of each of the Others of a JSONFormat with the path from ErrorCodePaths.
+// The Path gives the index of the member of each group that was traversed to find the error.
+// This has no effect with NestOthers, where the nesting gives the path.
+func IncludePaths() FormatOption {
+	return func(c *formatConfig) {
+		c.paths = true
+	}
+}
+
+// IncludeStack fills the Stack of a JSONFormat with the StackFrames of the error.
+// Only an error with a stack trace has a Stack: by default these are internal errors (see SetStackPolicy).
+// A stack trace exposes the implementation, so this is for internal tooling and logs rather than responses to clients.
+func IncludeStack() FormatOption {
+	return func(c *formatConfig) {
+		c.stack = true
+	}
+}
+
+func (c formatConfig) stackFrames(errCode ErrorCode) []Frame {
+	if !c.stack {
+		return nil
+	}
+	return StackFrames(errCode)
+}
+
+// MaxOthers caps the number of Others in a JSONFormat.
+// The errors that come first are kept and the number left out is given as OthersOmitted.
+// This is applied after DedupeOthers.
+func MaxOthers(max int) FormatOption {
+	return func(c *formatConfig) {
+		c.limitOthers = true
+		c.maxOthers = max
+		if c.maxOthers < 0 {
+			c.maxOthers = 0
+		}
+	}
+}
+
+// others gives the errors for the Others of a JSONFormat and how many were left out.
+// Errors that are not an ErrorCode are discarded.
+// The order is deterministic: it is the order of ErrorCodes or of the group members.
+func (c formatConfig) others(errCode ErrorCode) ([]CodePath, int) {
+	var errorCodes []CodePath
+	if c.nestOthers {
+		for _, member := range groupMembers(errCode) {
+			errorCodes = append(errorCodes, CodePath{ErrCode: member})
+		}
+	} else {
+		errorCodes = errorCodePaths(errCode, isBatchError)[1:]
+	}
+	if !c.paths {
+		for i := range errorCodes {
+			errorCodes[i].Path = nil
+		}
+	}
+	if c.dedupeOthers {
+		seen := map[CodeStr]bool{errCode.Code().CodeStr(): true}
+		deduped := make([]CodePath, 0, len(errorCodes))
+		for _, other := range errorCodes {
+			codeStr := other.ErrCode.Code().CodeStr()
+			if !seen[codeStr] {
+				seen[codeStr] = true
+				deduped = append(deduped, other)
+			}
+		}
+		errorCodes = deduped
+	}
+	omitted := 0
+	if c.limitOthers && len(errorCodes) > c.maxOthers {
+		omitted = len(errorCodes) - c.maxOthers
+		errorCodes = errorCodes[:c.maxOthers]
+	}
+	return errorCodes, omitted
+}
+
+// isBatchError checks for a BatchError.
+// The members of a BatchError are not in the Others of a JSONFormat: they are already given by its client data.
+func isBatchError(err error) bool {
+	_, ok := err.(*BatchError)
+	return ok
+}
+
+// groupMembers gives the members of the first group found by unwrapping the ErrorCode.
+// A member that is reached by unwrapping the ErrorCode is the ErrorCode itself and is left out.
+func groupMembers(errCode ErrorCode) []ErrorCode {
+	for err := error(errCode); err != nil; err = errors.Unwrap(err) {
+		if isBatchError(err) {
+			return nil
+		}
+		group := errors.Errors(err)
+		if group == nil {
+			continue
+		}
+		members := make([]ErrorCode, 0, len(group))
+		for _, member := range group {
+			if unwrapsTo(errCode, member) {
+				continue
+			}
+			if memberCode := CodeChain(member); memberCode != nil {
+				members = append(members, memberCode)
+			}
+		}
+		return members
+	}
+	return nil
+}
+
+// GenericUserMsg gives a generic user message for the class of an HTTP code.
+// This is used by RequireUserMsg.
+func GenericUserMsg(httpCode int) string {
+	if httpCode >= http.StatusInternalServerError {
+		return "Something went wrong"
+	}
+	return "There was a problem with the request"
+}
+
+// userMsg gives the user message for NewJSONFormat.
+func (c formatConfig) userMsg(errCode ErrorCode) string {
+	code := errCode.Code()
+	if c.translate != nil {
+		if msg, ok := c.translate(code); ok {
+			return msg
+		}
+	}
+	if msg := GetUserMsg(errCode); msg != "" {
+		return msg
+	}
+	if c.requireUserMsg {
+		slog.Warn("no user message for error code", "code", code.CodeStr())
+		return GenericUserMsg(code.HTTPCode())
+	}
+	return errCode.Error()
+}
+
+// formatErrorCode implements fmt.Formatter for the error types of this package, such as InternalErr and OpErrCode.
+// CodedError and StackCode do not have a Format method:
+// it would be promoted to the structs that embed them and would ignore an Error method they define.
+// Each exported type that embeds them defines its own Format instead.
+// %s and %v give Error() and %q quotes it.
+// %+v follows Error() with the code, the operation, the user message, and the stack trace, when they exist.
+// The Go syntax of %#v is left to the caller.
+func formatErrorCode(s fmt.State, verb rune, errCode ErrorCode) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, errCode.Error())
+			fmt.Fprintf(s, "\ncode: %s", errCode.Code().CodeStr())
+			if op := Operation(errCode); op != "" {
+				fmt.Fprintf(s, "\noperation: %s", op)
+			}
+			if msg := attachedUserMsg(errCode); msg != "" {
+				fmt.Fprintf(s, "\nuser message: %s", msg)
+			}
+			if stack := StackTrace(errCode); stack != nil {
+				fmt.Fprintf(s, "%+v", stack)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, errCode.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", errCode.Error())
+	}
+}
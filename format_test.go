@@ -0,0 +1,237 @@
+package errcode_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestRequireUserMsg(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	defaultMsgCode := errcode.NotFoundCode.Child("missing.defaultmsg").SetDefaultUserMsg("We could not find that")
+	for _, test := range []struct {
+		errCode  errcode.ErrorCode
+		msg      string
+		required string
+	}{
+		{errcode.NewInternalErr(errors.New("db password wrong")), "db password wrong", "Something went wrong"},
+		{errcode.NewInvalidInputErr(errors.New("bad")), "bad", "There was a problem with the request"},
+		{errcode.WithUserMsg("Try again", errcode.NewInvalidInputErr(errors.New("bad"))), "Try again", "Try again"},
+		{errcode.NewCodedError(errors.New("no row"), defaultMsgCode), "We could not find that", "We could not find that"},
+		{errcode.NewCodedError(errors.New("no row"), defaultMsgCode.Child("missing.defaultmsg.child")), "We could not find that", "We could not find that"},
+	} {
+		if msg := errcode.NewJSONFormat(test.errCode).Msg; msg != test.msg {
+			t.Errorf("expected %q, got %q", test.msg, msg)
+		}
+		if msg := errcode.NewJSONFormat(test.errCode, errcode.RequireUserMsg()).Msg; msg != test.required {
+			t.Errorf("expected %q, got %q", test.required, msg)
+		}
+	}
+	if !strings.Contains(logs.String(), "code=internal") {
+		t.Errorf("expected a warning with the code, got %s", logs.String())
+	}
+}
+
+func TestDefaultUserMsg(t *testing.T) {
+	notice := errcode.Severity("notice")
+	notice.SetDefaultUserMsg("Please try again later")
+	noticeCode := errcode.UnavailableCode.Child("unavailable.notice").SetSeverity(notice)
+	ownMsgCode := noticeCode.Child("unavailable.notice.own").SetDefaultUserMsg("The service is busy")
+
+	for _, test := range []struct {
+		errCode errcode.ErrorCode
+		msg     string
+	}{
+		{errcode.NewCodedError(errors.New("pool exhausted"), noticeCode), "Please try again later"},
+		{errcode.NewCodedError(errors.New("pool exhausted"), ownMsgCode), "The service is busy"},
+		{errcode.WithUserMsg("Retry", errcode.NewCodedError(errors.New("pool exhausted"), noticeCode)), "Retry"},
+		{errcode.NewInvalidInputErr(errors.New("bad")), ""},
+	} {
+		if msg := errcode.GetUserMsg(test.errCode); msg != test.msg {
+			t.Errorf("expected %q, got %q", test.msg, msg)
+		}
+	}
+	if msg := errcode.NewJSONFormat(errcode.NewCodedError(errors.New("pool exhausted"), noticeCode)).Msg; msg != "Please try again later" {
+		t.Errorf("expected the severity message, got %q", msg)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic when the severity message is set twice")
+		}
+	}()
+	notice.SetDefaultUserMsg("again")
+}
+
+func TestOthersOptions(t *testing.T) {
+	fieldErrs := errcode.NewFieldErrors(
+		errcode.NewFieldError("name", errcode.InvalidInputCode, "required"),
+		errcode.NewFieldError("email", errcode.InvalidInputCode, "invalid"),
+	)
+	err := errcode.Combine(
+		errcode.NewNotFoundErr(errors.New("no item")),
+		errcode.NewNotFoundErr(errors.New("no price")),
+		errcode.Op("validate")(fieldErrs),
+		errcode.NewGoneErr(errors.New("deleted")),
+	)
+	codes := func(formats []errcode.JSONFormat) string {
+		var strs []string
+		for _, format := range formats {
+			strs = append(strs, format.Code.String()+":"+format.Msg)
+		}
+		return strings.Join(strs, ",")
+	}
+
+	flat := errcode.NewJSONFormat(err)
+	if got := codes(flat.Others); got != "missing:no price,input:validate: name: required; email: invalid,input:required,input:invalid,missing.gone:deleted" {
+		t.Errorf("unexpected flat others %s", got)
+	}
+
+	nested := errcode.NewJSONFormat(err, errcode.NestOthers())
+	if got := codes(nested.Others); got != "missing:no price,input:validate: name: required; email: invalid,missing.gone:deleted" {
+		t.Errorf("unexpected nested others %s", got)
+	}
+	if got := codes(nested.Others[1].Others); got != "input:required,input:invalid" {
+		t.Errorf("unexpected nested members %s", got)
+	}
+
+	deduped := errcode.NewJSONFormat(err, errcode.DedupeOthers())
+	if got := codes(deduped.Others); got != "input:validate: name: required; email: invalid,missing.gone:deleted" {
+		t.Errorf("unexpected deduped others %s", got)
+	}
+
+	capped := errcode.NewJSONFormat(err, errcode.MaxOthers(2))
+	if len(capped.Others) != 2 || capped.OthersOmitted != 3 {
+		t.Errorf("unexpected capped others %d omitted %d", len(capped.Others), capped.OthersOmitted)
+	}
+}
+
+func TestOperationChain(t *testing.T) {
+	inner := errcode.Op("db.query")(errcode.NewNotFoundErr(errors.New("no row")))
+	err := errcode.Op("orders.get")(errcode.Op("orders.load")(errcode.Op("orders.load")(inner)))
+	group := errcode.Combine(err, errcode.Op("prices.get")(errcode.NewGoneErr(errors.New("deleted"))))
+
+	expected := "orders.get,orders.load,db.query,prices.get"
+	if ops := strings.Join(errcode.OperationChain(group), ","); ops != expected {
+		t.Errorf("expected %s, got %s", expected, ops)
+	}
+	format := errcode.NewJSONFormat(err, errcode.IncludeOperations())
+	if ops := strings.Join(format.Operations, ","); format.Operation != "orders.get" || ops != "orders.get,orders.load,db.query" {
+		t.Errorf("unexpected operations %s", ops)
+	}
+	if format := errcode.NewJSONFormat(inner, errcode.IncludeOperations()); format.Operations != nil {
+		t.Errorf("expected no operations for a single operation, got %v", format.Operations)
+	}
+	if format := errcode.NewJSONFormat(err); format.Operations != nil {
+		t.Errorf("expected no operations without the option, got %v", format.Operations)
+	}
+}
+
+func TestErrorCodePaths(t *testing.T) {
+	err := errcode.Combine(
+		errcode.NewNotFoundErr(errors.New("no item")),
+		// Op keeps the group from being flattened by Combine
+		errcode.Op("prices")(errcode.Combine(errcode.NewGoneErr(errors.New("deleted")), errcode.NewNotFoundErr(errors.New("no price")))),
+		errcode.NewNotFoundErr(errors.New("no stock")),
+	)
+	var paths []string
+	for _, found := range errcode.ErrorCodePaths(err) {
+		paths = append(paths, fmt.Sprintf("%s%v", found.ErrCode.Code().CodeStr(), found.Path))
+	}
+	if got := strings.Join(paths, ","); got != "missing[],missing.gone[1],missing[1 1],missing[2]" {
+		t.Errorf("unexpected paths %s", got)
+	}
+
+	var unique []string
+	for _, errCode := range errcode.UniqueErrorCodes(err) {
+		unique = append(unique, errCode.Code().CodeStr().String())
+	}
+	if got := strings.Join(unique, ","); got != "missing,missing.gone" {
+		t.Errorf("unexpected unique codes %s", got)
+	}
+
+	format := errcode.NewJSONFormat(err, errcode.IncludePaths())
+	if len(format.Others) != 3 || fmt.Sprint(format.Others[1].Path) != "[1 1]" {
+		t.Errorf("unexpected others %+v", format.Others)
+	}
+	var buf bytes.Buffer
+	if err := errcode.WriteJSON(&buf, err, errcode.IncludePaths()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"path":[1,1]`) {
+		t.Errorf("expected the path in %s", buf.String())
+	}
+	if format := errcode.NewJSONFormat(err); format.Others[0].Path != nil {
+		t.Errorf("expected no paths without the option, got %v", format.Others[0].Path)
+	}
+}
+
+func TestFormatVerbs(t *testing.T) {
+	internal := errcode.NewInternalErr(errors.New("db down"))
+	err := errcode.Op("items.get")(errcode.WithUserMsg("try again later", internal))
+	if got := fmt.Sprintf("%v", err); got != err.Error() {
+		t.Errorf("expected the message, got %s", got)
+	}
+	if got := fmt.Sprintf("%q", err); got != fmt.Sprintf("%q", err.Error()) {
+		t.Errorf("expected the quoted message, got %s", got)
+	}
+	detailed := fmt.Sprintf("%+v", err)
+	expected := err.Error() + "\ncode: internal\noperation: items.get\nuser message: try again later\n"
+	if !strings.HasPrefix(detailed, expected) {
+		t.Errorf("expected the details, got %s", detailed)
+	}
+	if !strings.Contains(detailed, "TestFormatVerbs") {
+		t.Errorf("expected the stack trace, got %s", detailed)
+	}
+	if got := fmt.Sprintf("%#v", errcode.Op("items.get")(errcode.NotFoundCode.Err("no item"))); !strings.HasPrefix(got, `OpErrCode{Operation: "items.get", Err: errcode.CodedError{`) {
+		t.Errorf("unexpected Go syntax %s", got)
+	}
+}
+
+func TestFormatConcrete(t *testing.T) {
+	internal := errcode.NewInternalErr(errors.New("boom"))
+	detailed := fmt.Sprintf("%+v", internal)
+	if !strings.HasPrefix(detailed, "boom\ncode: internal\n") {
+		t.Errorf("expected the code, got %s", detailed)
+	}
+	if !strings.Contains(detailed, "TestFormatConcrete") {
+		t.Errorf("expected the stack trace, got %s", detailed)
+	}
+	if got := fmt.Sprintf("%v", internal); got != "boom" {
+		t.Errorf("expected the message, got %s", got)
+	}
+	if got := fmt.Sprintf("%+v", errcode.NewNotFoundErr(errors.New("no item"))); !strings.HasPrefix(got, "no item\ncode: missing") {
+		t.Errorf("expected the code, got %s", got)
+	}
+	if got := fmt.Sprintf("%#v", errcode.NewNotFoundErr(errors.New("no item"))); !strings.HasPrefix(got, "NotFoundErr{CodedError: errcode.CodedError{") {
+		t.Errorf("unexpected Go syntax %s", got)
+	}
+}
+
+type overrideErr struct {
+	errcode.CodedError
+}
+
+func (e overrideErr) Error() string {
+	return "overridden"
+}
+
+func TestFormatEmbedded(t *testing.T) {
+	overridden := overrideErr{errcode.NewCodedError(errors.New("no item"), errcode.NotFoundCode)}
+	if got := fmt.Sprintf("%v", overridden); got != "overridden" {
+		t.Errorf("expected the Error of the embedding struct, got %s", got)
+	}
+	rateLimited := errcode.NewRateLimitErr(errors.New("slow down"), time.Minute)
+	if got := fmt.Sprintf("%#v", rateLimited); !strings.HasPrefix(got, "RateLimitErr{CodedError: ") || !strings.HasSuffix(got, "RetryAfter: 60000000000}") {
+		t.Errorf("expected the fields of the embedding struct, got %s", got)
+	}
+}
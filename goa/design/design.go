@@ -0,0 +1,67 @@
+// Package design declares goa errors from error codes in a goa design.
+// The error name is the CodeStr, which is the name given by ErrorCodeGoa at runtime,
+// so the design and the runtime error codes cannot drift apart.
+//
+//	var _ = API("calc", func() {
+//		design.API(errcode.NotFoundCode, errcode.InvalidInputCode)
+//	})
+//
+// Without codes, every code in errcode.DefaultRegistry is declared.
+package design
+
+import (
+	"github.com/gregwebs/errcode"
+	"goa.design/goa/v3/dsl"
+	"goa.design/goa/v3/expr"
+)
+
+// API declares the errors with Errors and their HTTP responses with HTTPResponses.
+// It must appear in an API expression so that the errors can be used by every service.
+func API(codes ...errcode.Code) {
+	codes = registeredCodes(codes)
+	Errors(codes...)
+	dsl.HTTP(func() {
+		HTTPResponses(codes...)
+	})
+}
+
+// Errors declares an error for each code with the goa Error DSL.
+// The error has the ErrorResult type and the Description of the code.
+// It is qualified as Timeout, Temporary, or Fault as ErrorCodeGoa is at runtime.
+// It must appear in an API, Service, or Method expression.
+func Errors(codes ...errcode.Code) {
+	for _, code := range registeredCodes(codes) {
+		code := code
+		dsl.Error(Name(code), expr.ErrorResult, code.Description(), func() {
+			if code.IsAncestor(errcode.TimeoutCode) {
+				dsl.Timeout()
+			}
+			if code.IsAncestor(errcode.UnavailableCode) {
+				dsl.Temporary()
+			}
+			if code.IsAncestor(errcode.InternalCode) {
+				dsl.Fault()
+			}
+		})
+	}
+}
+
+// HTTPResponses declares an HTTP response for the error of each code with the HTTPCode of the code.
+// It must appear in an HTTP expression.
+func HTTPResponses(codes ...errcode.Code) {
+	for _, code := range registeredCodes(codes) {
+		dsl.Response(Name(code), code.HTTPCode())
+	}
+}
+
+// Name gives the goa error name of a code.
+func Name(code errcode.Code) string {
+	return code.CodeStr().String()
+}
+
+func registeredCodes(codes []errcode.Code) []errcode.Code {
+	if len(codes) == 0 {
+		return errcode.DefaultRegistry().Codes()
+	}
+	return codes
+}
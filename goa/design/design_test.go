@@ -0,0 +1,56 @@
+package design_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/goa/design"
+	"goa.design/goa/v3/dsl"
+	"goa.design/goa/v3/expr"
+)
+
+func TestAPI(t *testing.T) {
+	root := expr.RunDSL(t, func() {
+		dsl.API("items", func() {
+			design.API(errcode.NotFoundCode, errcode.UnavailableCode, errcode.TimeoutGatewayCode)
+		})
+		dsl.Service("items", func() {
+			dsl.Method("get", func() {
+				dsl.HTTP(func() {
+					dsl.GET("/")
+				})
+			})
+		})
+	})
+
+	errs := make(map[string]*expr.ErrorExpr)
+	for _, errExpr := range root.Errors {
+		errs[errExpr.Name] = errExpr
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %d", len(errs))
+	}
+	if _, ok := errs["internal.unavailable"].Meta["goa:error:temporary"]; !ok {
+		t.Error("expected unavailable to be temporary")
+	}
+	if _, ok := errs["internal.unavailable"].Meta["goa:error:fault"]; !ok {
+		t.Error("expected unavailable to be a fault")
+	}
+	if _, ok := errs["timeout.gateway"].Meta["goa:error:timeout"]; !ok {
+		t.Error("expected the gateway timeout to be a timeout")
+	}
+	if len(errs["missing"].Meta) != 0 {
+		t.Errorf("expected no qualifiers for missing, got %v", errs["missing"].Meta)
+	}
+
+	statuses := make(map[string]int)
+	for _, response := range root.API.HTTP.Errors {
+		statuses[response.Name] = response.Response.StatusCode
+	}
+	if statuses["missing"] != 404 || statuses["internal.unavailable"] != 503 || statuses["timeout.gateway"] != 504 {
+		t.Errorf("unexpected HTTP responses %v", statuses)
+	}
+	if name := design.Name(errcode.NotFoundCode); name != "missing" {
+		t.Errorf("unexpected name %s", name)
+	}
+}
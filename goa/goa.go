@@ -5,7 +5,9 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gregwebs/errcode"
 	goahttp "goa.design/goa/v3/http"
@@ -27,11 +29,47 @@ func (ec ErrorCodeGoa) StatusCode() int {
 	httpCode := errcode.HTTPCode(ec.Code())
 	if httpCode == nil {
 		slog.Error("no HTTP Status Code", "code", ec.Code(), "error", ec.Error())
-		return http.StatusBadRequest
+		return errcode.DefaultHTTPStatus()
 	}
 	return *httpCode
 }
 
+// Timeout is true for TimeoutCode or a descendant.
+// This is the Timeout of a goa ServiceError.
+func (ec ErrorCodeGoa) Timeout() bool {
+	if serviceErr := ec.serviceError(); serviceErr != nil && serviceErr.Timeout {
+		return true
+	}
+	return ec.Code().IsAncestor(errcode.TimeoutCode)
+}
+
+// Temporary is true for UnavailableCode or a descendant.
+// This is the Temporary of a goa ServiceError.
+func (ec ErrorCodeGoa) Temporary() bool {
+	if serviceErr := ec.serviceError(); serviceErr != nil && serviceErr.Temporary {
+		return true
+	}
+	return ec.Code().IsAncestor(errcode.UnavailableCode)
+}
+
+// Fault is true for InternalCode or a descendant: the error is not the fault of the client.
+// This is the Fault of a goa ServiceError.
+func (ec ErrorCodeGoa) Fault() bool {
+	if serviceErr := ec.serviceError(); serviceErr != nil && serviceErr.Fault {
+		return true
+	}
+	return ec.Code().IsAncestor(errcode.InternalCode)
+}
+
+// serviceError gives the goa ServiceError that was converted, if any.
+func (ec ErrorCodeGoa) serviceError() *goalib.ServiceError {
+	var serviceErr *goalib.ServiceError
+	if errors.As(ec.err, &serviceErr) {
+		return serviceErr
+	}
+	return nil
+}
+
 func (ec ErrorCodeGoa) Code() errcode.Code {
 	return ec.errorCode.Code()
 }
@@ -72,7 +110,10 @@ func ErrorCodeToGoa(errCode errcode.ErrorCode) ErrorCodeGoa {
 	}
 }
 
-var codeCache map[string]errcode.Code
+var (
+	codeCacheMu sync.Mutex
+	codeCache   = make(map[string]errcode.Code)
+)
 
 func serviceErrorToHttpErr(goaErr *goalib.ServiceError) *goahttp.ErrorResponse {
 	return &goahttp.ErrorResponse{
@@ -121,9 +162,8 @@ func serviceErrorToCode(goaErr *goalib.ServiceError) errcode.Code {
 		case http.StatusBadRequest:
 			parentCode = &errcode.InvalidInputCode
 		}
-		if codeCache == nil {
-			codeCache = make(map[string]errcode.Code)
-		}
+		codeCacheMu.Lock()
+		defer codeCacheMu.Unlock()
 		code, okCode := codeCache[goaErr.Name]
 		if !okCode {
 			codeStr := errcode.CodeStr(goaErr.Name)
@@ -163,15 +203,8 @@ func ServiceErrorToErrorCode(err *goalib.ServiceError) ErrorCodeGoa {
 
 	// adjust GOA error mesages to be user readable
 	if errcode.GetUserMsg(err) == "" {
-		switch err.Name {
-		case "invalid_pattern":
-			errorForCode = PatternErr{err: err}
-		case "invalid_format":
-			errorForCode = FormatErr{err: err}
-		case "missing_field":
-			errorForCode = MissingFieldErr{err: err}
-		case "invalid_enum_value":
-			errorForCode = EnumErr{err: err}
+		if rewritten := rewrite(err); rewritten != nil {
+			errorForCode = rewritten
 		}
 	}
 	var errCode errcode.ErrorCode = errcode.NewCodedError(errorForCode, code)
@@ -211,7 +244,8 @@ func (pe EnumErr) Error() string {
 }
 
 func (pe EnumErr) GetUserMsg() string {
-	return userMsgInvalidSplit(pe.err.Message, " but got value ", "")
+	// The field may not be in the body, for example a query parameter
+	return userMsgInvalidSplit(strings.TrimPrefix(pe.err.Message, "value of "), " but got value ", "")
 }
 
 func (pe EnumErr) GetClientData() interface{} {
@@ -220,6 +254,130 @@ func (pe EnumErr) GetClientData() interface{} {
 
 // var _ errcode.HasClientData = PatternErr{}
 
+// RangeErr is an invalid_range error.
+// The user message gives the bound, for example "Foo must be at least 1".
+type RangeErr struct {
+	err *goalib.ServiceError
+}
+
+func (re RangeErr) Unwrap() error {
+	return re.err
+}
+
+func (re RangeErr) Error() string {
+	return re.err.Error()
+}
+
+func (re RangeErr) GetUserMsg() string {
+	return userMsgBound(re.err.Message, "be ")
+}
+
+func (re RangeErr) GetClientData() interface{} {
+	return fieldValueClientData(re.err)
+}
+
+// LengthErr is an invalid_length error.
+// The user message gives the bound, for example "Foo must have a length of at most 10".
+type LengthErr struct {
+	err *goalib.ServiceError
+}
+
+func (le LengthErr) Unwrap() error {
+	return le.err
+}
+
+func (le LengthErr) Error() string {
+	return le.err.Error()
+}
+
+func (le LengthErr) GetUserMsg() string {
+	return userMsgBound(strings.TrimPrefix(le.err.Message, "length of "), "have a length of ")
+}
+
+func (le LengthErr) GetClientData() interface{} {
+	return fieldValueClientData(le.err)
+}
+
+// FieldTypeErr is an invalid_field_type error.
+// The user message gives the expected type, for example "Foo must be a string".
+type FieldTypeErr struct {
+	err *goalib.ServiceError
+}
+
+func (fe FieldTypeErr) Unwrap() error {
+	return fe.err
+}
+
+func (fe FieldTypeErr) Error() string {
+	return fe.err.Error()
+}
+
+func (fe FieldTypeErr) GetUserMsg() string {
+	_, expected, found := strings.Cut(fe.err.Message, ", must be ")
+	if !found {
+		return ""
+	}
+	return fieldClientData(fe.err).Field + " must be " + expected
+}
+
+func (fe FieldTypeErr) GetClientData() interface{} {
+	data := FieldValueClientData{FieldClientData: fieldClientData(fe.err)}
+	if value, found := strings.CutPrefix(fe.err.Message, "invalid value "); found {
+		data.Value = unquoteValue(strings.Split(value, " for ")[0])
+	}
+	return data
+}
+
+// DecodePayloadErr is a decode_payload error: the request body could not be decoded.
+// If the decoding error is for a field of the JSON body, that field is given.
+type DecodePayloadErr struct {
+	err *goalib.ServiceError
+}
+
+func (de DecodePayloadErr) Unwrap() error {
+	return de.err
+}
+
+func (de DecodePayloadErr) Error() string {
+	return de.err.Error()
+}
+
+func (de DecodePayloadErr) GetUserMsg() string {
+	if field, fieldType := decodeField(de.err.Message); field != "" {
+		return field + " must be of type " + fieldType
+	}
+	return "The request body is invalid"
+}
+
+func (de DecodePayloadErr) GetClientData() interface{} {
+	data := fieldClientData(de.err)
+	if data.Field == "" {
+		data.Field, _ = decodeField(de.err.Message)
+	}
+	return data
+}
+
+// MissingPayloadErr is a missing_payload error: the request has no body.
+type MissingPayloadErr struct {
+	err *goalib.ServiceError
+}
+
+func (me MissingPayloadErr) Unwrap() error {
+	return me.err
+}
+
+func (me MissingPayloadErr) Error() string {
+	return me.err.Error()
+}
+
+func (me MissingPayloadErr) GetUserMsg() string {
+	return "The request body is missing"
+}
+
+func (me MissingPayloadErr) GetClientData() interface{} {
+	return fieldClientData(me.err)
+}
+
 type FieldClientData struct {
 	ID          string
 	Name        string
@@ -315,6 +473,61 @@ func fieldGotValueClientData(err *goalib.ServiceError) FieldValueClientData {
 	}
 }
 
+// fieldValueClientData gives the value after " but got value " of a message.
+// Unlike fieldGotValueClientData, a quoted value is unquoted and a length is removed.
+func fieldValueClientData(err *goalib.ServiceError) FieldValueClientData {
+	data := FieldValueClientData{FieldClientData: fieldClientData(err)}
+	if _, value, found := strings.Cut(err.Message, " but got value "); found {
+		if i := strings.LastIndex(value, " (len="); i >= 0 {
+			value = value[:i]
+		}
+		data.Value = unquoteValue(value)
+	}
+	return data
+}
+
+// unquoteValue unquotes a value formatted with %#v if it is a string.
+func unquoteValue(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
+
+// userMsgBound rewrites "body.Foo must be greater or equal than 1 but got value 0"
+// as "Foo must be at least 1" where "be " is the verb.
+func userMsgBound(msg string, verb string) string {
+	field, rest, found := strings.Cut(strings.TrimPrefix(msg, "body."), " must be ")
+	if !found {
+		return ""
+	}
+	bound, _, found := strings.Cut(rest, " but got value ")
+	if !found {
+		return ""
+	}
+	bound = strings.Replace(bound, "greater or equal than ", "at least ", 1)
+	bound = strings.Replace(bound, "lesser or equal than ", "at most ", 1)
+	return field + " must " + verb + bound
+}
+
+// decodeField gives the field and type of a JSON decoding error such as
+// "json: cannot unmarshal string into Go struct field Payload.count of type int".
+func decodeField(msg string) (string, string) {
+	_, field, found := strings.Cut(msg, "Go struct field ")
+	if !found {
+		return "", ""
+	}
+	field, fieldType, found := strings.Cut(field, " of type ")
+	if !found {
+		return "", ""
+	}
+	// Remove the name of the struct
+	if _, path, found := strings.Cut(field, "."); found {
+		field = path
+	}
+	return field, fieldType
+}
+
 func userMsgInvalidSplit(msgInput string, sep string, appendValue string) string {
 	msg := strings.TrimPrefix(msgInput, "value of body.")
 	msg = strings.TrimPrefix(msg, "body.")
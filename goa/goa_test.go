@@ -31,7 +31,7 @@ func TestErrorResponse(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected json marshal success, got %v", err)
 	}
-	expectedJSON := `{"code":"internal","msg":"wrapped: goa test","data":{}}`
+	expectedJSON := `{"code":"internal","msg":"wrapped: goa test","data":null}`
 	if string(jsonBytes) != expectedJSON {
 		t.Fatalf("expected %s, got %s", expectedJSON, string(jsonBytes))
 	}
@@ -120,4 +120,71 @@ func TestServiceErrorToErrorCodeUserMsg(t *testing.T) {
 	svcErr = goalib.MissingFieldError("Foo", "body").(*goalib.ServiceError)
 	AssertUserMsg(t, svcErr, "Foo is missing")
 	AssertUserMsgClientData(t, svcErr)
+
+	svcErr = goalib.InvalidEnumValueError("page", 3, []any{1, 2}).(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "page must be one of 1, 2")
+}
+
+func TestServiceErrorToErrorCodeUserMsgBounds(t *testing.T) {
+	svcErr := goalib.InvalidRangeError("body.Foo", 0, 1, true).(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "Foo must be at least 1")
+	AssertClientDataValue(t, svcErr, "0")
+
+	svcErr = goalib.InvalidRangeError("body.Foo", 11, 10, false).(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "Foo must be at most 10")
+
+	svcErr = goalib.InvalidLengthError("body.Foo", "abc", 3, 5, true).(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "Foo must have a length of at least 5")
+	AssertClientDataValue(t, svcErr, "abc")
+
+	svcErr = goalib.InvalidFieldTypeError("body.Foo", 3, "string").(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "Foo must be a string")
+	AssertClientDataValue(t, svcErr, "3")
+}
+
+func TestServiceErrorToErrorCodeUserMsgPayload(t *testing.T) {
+	svcErr := goalib.MissingPayloadError().(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "The request body is missing")
+
+	svcErr = goalib.DecodePayloadError("unexpected EOF").(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "The request body is invalid")
+
+	svcErr = goalib.DecodePayloadError("json: cannot unmarshal string into Go struct field CreateRequestBody.count of type int").(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "count must be of type int")
+	cd := errcode.ClientData(goa.ServiceErrorToErrorCode(svcErr)).(goa.FieldClientData)
+	if cd.Field != "count" || cd.Name != "decode_payload" {
+		t.Errorf("unexpected client data %#v", cd)
+	}
+}
+
+func AssertClientDataValue(t *testing.T, svcErr *goalib.ServiceError, value string) {
+	t.Helper()
+	cd := errcode.ClientData(goa.ServiceErrorToErrorCode(svcErr)).(goa.FieldValueClientData)
+	if cd.Field != "Foo" || cd.Value != value || cd.Name != svcErr.Name {
+		t.Errorf("unexpected client data %#v", cd)
+	}
+}
+
+func TestServiceErrorFlags(t *testing.T) {
+	for _, test := range []struct {
+		err       errcode.ErrorCode
+		timeout   bool
+		temporary bool
+		fault     bool
+	}{
+		{errcode.NewCodedError(errors.New("slow"), errcode.TimeoutGatewayCode), true, false, false},
+		{errcode.NewUnavailableErr(errors.New("down")), false, true, true},
+		{errcode.NewInternalErr(errors.New("bug")), false, false, true},
+		{errcode.NewNotFoundErr(errors.New("no item")), false, false, false},
+	} {
+		ecg := goa.ErrorCodeToGoa(test.err)
+		if ecg.Timeout() != test.timeout || ecg.Temporary() != test.temporary || ecg.Fault() != test.fault {
+			t.Errorf("unexpected flags for %s: timeout %t temporary %t fault %t", ecg.Code().CodeStr(), ecg.Timeout(), ecg.Temporary(), ecg.Fault())
+		}
+	}
+
+	converted := goa.ServiceErrorToErrorCode(goalib.NewServiceError(errors.New("retry"), "busy", false, true, false))
+	if !converted.Temporary() || converted.Timeout() {
+		t.Errorf("expected the flags of the ServiceError to be kept, got temporary %t timeout %t", converted.Temporary(), converted.Timeout())
+	}
 }
@@ -0,0 +1,123 @@
+package goa
+
+import (
+	"regexp"
+	"sync"
+
+	goalib "goa.design/goa/v3/pkg"
+)
+
+// RewriteRule makes a goa ServiceError readable by end users.
+// It gives an error that wraps the ServiceError and provides a user message (errcode.HasUserMsg)
+// and client data (errcode.HasClientData), such as a RewrittenErr.
+// It gives nil if it does not apply to the ServiceError.
+//
+// ServiceErrorToErrorCode uses the first rule that applies.
+type RewriteRule func(*goalib.ServiceError) error
+
+var (
+	rewriteRulesMu sync.RWMutex
+	rewriteRules   = DefaultRewriteRules()
+)
+
+// SetRewriteRules sets the rules used by ServiceErrorToErrorCode.
+// To keep the default behavior for other errors, add the DefaultRewriteRules after your own rules.
+//
+//	goa.SetRewriteRules(append([]goa.RewriteRule{myRule}, goa.DefaultRewriteRules()...)...)
+func SetRewriteRules(rules ...RewriteRule) {
+	rewriteRulesMu.Lock()
+	defer rewriteRulesMu.Unlock()
+	rewriteRules = rules
+}
+
+// DefaultRewriteRules gives the rules that are used unless SetRewriteRules is called.
+// There is a rule for each of the validation and decoding errors produced by goa,
+// giving for example PatternErr for invalid_pattern.
+func DefaultRewriteRules() []RewriteRule {
+	return []RewriteRule{
+		NameRule(goalib.InvalidPattern, func(err *goalib.ServiceError) error { return PatternErr{err: err} }),
+		NameRule(goalib.InvalidFormat, func(err *goalib.ServiceError) error { return FormatErr{err: err} }),
+		NameRule(goalib.MissingField, func(err *goalib.ServiceError) error { return MissingFieldErr{err: err} }),
+		NameRule(goalib.InvalidEnumValue, func(err *goalib.ServiceError) error { return EnumErr{err: err} }),
+		NameRule(goalib.InvalidRange, func(err *goalib.ServiceError) error { return RangeErr{err: err} }),
+		NameRule(goalib.InvalidLength, func(err *goalib.ServiceError) error { return LengthErr{err: err} }),
+		NameRule(goalib.InvalidFieldType, func(err *goalib.ServiceError) error { return FieldTypeErr{err: err} }),
+		NameRule("decode_payload", func(err *goalib.ServiceError) error { return DecodePayloadErr{err: err} }),
+		NameRule("missing_payload", func(err *goalib.ServiceError) error { return MissingPayloadErr{err: err} }),
+	}
+}
+
+// NameRule gives a rule that applies to the ServiceErrors with the given name.
+func NameRule(name string, rewrite func(*goalib.ServiceError) error) RewriteRule {
+	return func(err *goalib.ServiceError) error {
+		if err.Name != name {
+			return nil
+		}
+		return rewrite(err)
+	}
+}
+
+// RegexpRule gives a rule that applies to the ServiceErrors with the given name whose Message matches the regexp.
+// The user message is the template expanded with the submatches as with regexp.Regexp.Expand.
+// The client data is a FieldValueClientData:
+// a submatch named "field" replaces the Field and a submatch named "value" gives the Value.
+//
+//	goa.RegexpRule("invalid_range", regexp.MustCompile(`^body\.(?P<field>\S+) must be greater or equal than (?P<min>\S+)`), "${field} must be ${min} or more")
+func RegexpRule(name string, re *regexp.Regexp, template string) RewriteRule {
+	return NameRule(name, func(err *goalib.ServiceError) error {
+		match := re.FindStringSubmatchIndex(err.Message)
+		if match == nil {
+			return nil
+		}
+		data := FieldValueClientData{FieldClientData: fieldClientData(err)}
+		for i, group := range re.SubexpNames() {
+			if match[2*i] < 0 {
+				continue
+			}
+			switch group {
+			case "field":
+				data.Field = err.Message[match[2*i]:match[2*i+1]]
+			case "value":
+				data.Value = unquoteValue(err.Message[match[2*i]:match[2*i+1]])
+			}
+		}
+		userMsg := string(re.ExpandString(nil, template, err.Message, match))
+		return RewrittenErr{Err: err, UserMsg: userMsg, ClientData: data}
+	})
+}
+
+// RewrittenErr is a ServiceError with a user message and client data given by a RewriteRule.
+type RewrittenErr struct {
+	Err        *goalib.ServiceError
+	UserMsg    string
+	ClientData interface{}
+}
+
+func (re RewrittenErr) Unwrap() error {
+	return re.Err
+}
+
+func (re RewrittenErr) Error() string {
+	return re.Err.Error()
+}
+
+func (re RewrittenErr) GetUserMsg() string {
+	return re.UserMsg
+}
+
+func (re RewrittenErr) GetClientData() interface{} {
+	return re.ClientData
+}
+
+// rewrite applies the first rule that applies to the ServiceError.
+func rewrite(err *goalib.ServiceError) error {
+	rewriteRulesMu.RLock()
+	rules := rewriteRules
+	rewriteRulesMu.RUnlock()
+	for _, rule := range rules {
+		if rewritten := rule(err); rewritten != nil {
+			return rewritten
+		}
+	}
+	return nil
+}
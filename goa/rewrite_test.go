@@ -0,0 +1,40 @@
+package goa_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/goa"
+	"github.com/gregwebs/errors"
+	goalib "goa.design/goa/v3/pkg"
+)
+
+func TestRewriteRules(t *testing.T) {
+	defer goa.SetRewriteRules(goa.DefaultRewriteRules()...)
+	goa.SetRewriteRules(append([]goa.RewriteRule{
+		goa.RegexpRule("invalid_range", regexp.MustCompile(`^body\.(?P<field>\S+) must be greater or equal than (?P<min>\S+) but got value (?P<value>.+)$`), "${field} must be ${min} or more"),
+		goa.NameRule("decode_payload", func(err *goalib.ServiceError) error {
+			return goa.RewrittenErr{Err: err, UserMsg: "Send JSON"}
+		}),
+	}, goa.DefaultRewriteRules()...)...)
+
+	svcErr := goalib.InvalidRangeError("body.Foo", "abc", 2, true).(*goalib.ServiceError)
+	AssertUserMsg(t, svcErr, "Foo must be 2 or more")
+	AssertClientDataValue(t, svcErr, "abc")
+
+	AssertUserMsg(t, goalib.DecodePayloadError("unexpected EOF").(*goalib.ServiceError), "Send JSON")
+
+	// The regexp does not match a maximum, so the default rule applies
+	AssertUserMsg(t, goalib.InvalidRangeError("body.Foo", 11, 10, false).(*goalib.ServiceError), "Foo must be at most 10")
+	AssertUserMsg(t, goalib.MissingFieldError("Foo", "body").(*goalib.ServiceError), "Foo is missing")
+
+	goa.SetRewriteRules()
+	svcErr = goalib.MissingFieldError("Foo", "body").(*goalib.ServiceError)
+	if msg := errcode.GetUserMsg(goa.ServiceErrorToErrorCode(svcErr)); msg == "Foo is missing" {
+		t.Errorf("expected no rewriting without rules, got %s", msg)
+	}
+	if !errors.Is(goa.ServiceErrorToErrorCode(svcErr), svcErr) {
+		t.Error("expected the ServiceError to be kept")
+	}
+}
@@ -14,19 +14,33 @@
 package errcode
 
 import (
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"reflect"
+	"sort"
 
 	"github.com/gregwebs/errors"
 )
 
 // ErrorCodes return all errors (from an ErrorGroup) that are of interface ErrorCode.
 // It first calls the Errors function.
+//
+// The order is deterministic: it is a depth-first traversal.
+// An error comes first, followed by the errors found by unwrapping it,
+// followed by the members of any group (ErrorGroup or Unwrap() []error such as errors.Join) in that unwrap chain
+// in the order given by the group.
+// Nested groups are expanded in place.
+// A group that is built from a map must order its members itself: CombineLabeled orders them by label.
+//
+// An ErrorCode that is found by unwrapping an ErrorCode with the same code is not returned:
+// it is considered a layer of the same error.
 func ErrorCodes(err error) []ErrorCode {
 	errorCodes := make([]ErrorCode, 0)
-	errors.WalkDeep(err, func(err error) bool {
+	walkDeep(err, func(err error) bool {
 		if errcode, ok := err.(ErrorCode); ok {
 			// avoid duplicating codes
-			if len(errorCodes) == 0 || errorCodes[len(errorCodes)-1].Code().codeStr != errcode.Code().codeStr {
+			if !isDuplicateCode(errorCodes, errcode) {
 				errorCodes = append(errorCodes, errcode)
 			}
 		}
@@ -35,6 +49,180 @@ func ErrorCodes(err error) []ErrorCode {
 	return errorCodes
 }
 
+// UniqueErrorCodes gives the ErrorCodes with a different code.
+// Unlike ErrorCodes, an ErrorCode is left out whenever an earlier ErrorCode has the same code,
+// even when it is a different member of a group.
+func UniqueErrorCodes(err error) []ErrorCode {
+	seen := make(map[CodeStr]bool)
+	unique := make([]ErrorCode, 0)
+	for _, errCode := range ErrorCodes(err) {
+		if codeStr := errCode.Code().CodeStr(); !seen[codeStr] {
+			seen[codeStr] = true
+			unique = append(unique, errCode)
+		}
+	}
+	return unique
+}
+
+// CodePath is an ErrorCode found by ErrorCodePaths with the path to it.
+type CodePath struct {
+	ErrCode ErrorCode
+	// Path gives the index of the member of each group that was traversed to find the ErrorCode, outermost group first.
+	// It is empty for an ErrorCode that is found by unwrapping without traversing a group.
+	Path []int
+}
+
+// ErrorCodePaths gives the same ErrorCodes as ErrorCodes along with the path to each of them.
+func ErrorCodePaths(err error) []CodePath {
+	return errorCodePaths(err, nil)
+}
+
+// errorCodePaths is ErrorCodePaths that does not traverse the members of a group for which skipGroup is true.
+func errorCodePaths(err error, skipGroup func(error) bool) []CodePath {
+	var errorCodes []ErrorCode
+	paths := make([]CodePath, 0)
+	walkDeepPathSkip(err, nil, skipGroup, func(err error, path []int) bool {
+		if errcode, ok := err.(ErrorCode); ok {
+			if !isDuplicateCode(errorCodes, errcode) {
+				errorCodes = append(errorCodes, errcode)
+				paths = append(paths, CodePath{ErrCode: errcode, Path: path})
+			}
+		}
+		return false
+	})
+	return paths
+}
+
+// Unwrapped gives the ErrorCode of each member of the first group found by unwrapping the error,
+// such as a MultiErrCode, a JoinedErrCode or a BatchError.
+// Members are in the order of the group and nested groups are not expanded: use ErrorCodes for that.
+// As with CombineAll, a member that does not have an ErrorCode (as found by CodeChain) is converted with NewInternalErr.
+// Nil is returned if no group is found.
+func Unwrapped(err error) []ErrorCode {
+	for unErr := err; unErr != nil; unErr = errors.Unwrap(unErr) {
+		members := errors.Errors(unErr)
+		if members == nil {
+			continue
+		}
+		codes := make([]ErrorCode, 0, len(members))
+		for _, member := range members {
+			errCode := CodeChain(member)
+			if errCode == nil {
+				errCode = NewInternalErr(member)
+			}
+			codes = append(codes, errCode)
+		}
+		return codes
+	}
+	return nil
+}
+
+// walkDeep does a depth-first traversal of all errors.
+// It is like errors.WalkDeep but it also traverses groups that are found by unwrapping.
+// The visitor function can return true to end the traversal early
+// In that case, walkDeep will return true, otherwise false.
+func walkDeep(err error, visitor func(err error) bool) bool {
+	return walkDeepPath(err, nil, func(err error, _ []int) bool {
+		return visitor(err)
+	})
+}
+
+// walkDeepPath is walkDeep that also gives the visitor the indexes of the group members traversed to reach the error.
+// The visitor can retain the path: it is not modified by the traversal.
+func walkDeepPath(err error, path []int, visitor func(err error, path []int) bool) bool {
+	return walkDeepPathSkip(err, path, nil, visitor)
+}
+
+// walkDeepPathSkip is walkDeepPath that does not traverse the members of a group for which skipGroup is true.
+// A nil skipGroup traverses every group.
+func walkDeepPathSkip(err error, path []int, skipGroup func(error) bool, visitor func(err error, path []int) bool) bool {
+	for unErr := err; unErr != nil; unErr = errors.Unwrap(unErr) {
+		if visitor(unErr, path) {
+			return true
+		}
+	}
+	for unErr := err; unErr != nil; unErr = errors.Unwrap(unErr) {
+		if skipGroup != nil && skipGroup(unErr) {
+			continue
+		}
+		for i, member := range errors.Errors(unErr) {
+			memberPath := make([]int, len(path)+1)
+			copy(memberPath, path)
+			memberPath[len(path)] = i
+			if walkDeepPathSkip(member, memberPath, skipGroup, visitor) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findDeep gives the first value of type T found by walkDeep.
+// A value that is not an error is only checked itself.
+func findDeep[T any](v interface{}) (found T, ok bool) {
+	if found, ok = v.(T); ok {
+		return found, true
+	}
+	err, isErr := v.(error)
+	if !isErr {
+		return found, false
+	}
+	walkDeep(err, func(err error) bool {
+		found, ok = err.(T)
+		return ok
+	})
+	return found, ok
+}
+
+// isDuplicateCode checks if the ErrorCode is just a layer of an already found ErrorCode.
+// It is a layer if it has the same code and can be reached by unwrapping.
+// Different group members with the same code are not duplicates.
+func isDuplicateCode(found []ErrorCode, errCode ErrorCode) bool {
+	codeStr := errCode.Code().CodeStr()
+	for _, existing := range found {
+		if existing.Code().CodeStr() == codeStr && unwrapsTo(existing, errCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrapsTo checks if the target is found by unwrapping err.
+func unwrapsTo(err error, target error) bool {
+	for err != nil {
+		if equalErrors(err, target) {
+			return true
+		}
+		// These give their code from an ErrCode that was found by traversing the errors they wrap
+		switch wrapper := err.(type) {
+		case ChainContext:
+			if unwrapsTo(wrapper.ErrCode, target) {
+				return true
+			}
+		case JoinedErrCode:
+			if unwrapsTo(wrapper.ErrCode, target) {
+				return true
+			}
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// equalErrors compares errors with ==.
+// An uncomparable type, such as MultiErrCode, is compared with reflect.DeepEqual.
+// A comparable type can still hold an uncomparable value in an interface field,
+// such as the Data of ClientDataErrCode, so the values are checked rather than just the type.
+func equalErrors(err error, target error) bool {
+	if reflect.TypeOf(err) != reflect.TypeOf(target) {
+		return false
+	}
+	if !reflect.TypeOf(target).Comparable() || !reflect.ValueOf(err).Comparable() || !reflect.ValueOf(target).Comparable() {
+		return reflect.DeepEqual(err, target)
+	}
+	return err == target
+}
+
 // A MultiErrCode contains at least one ErrorCode and uses that to satisfy the ErrorCode and related interfaces
 // The Error method will produce a string of all the errors with a semi-colon separation.
 // Later code (such as a JSON response) needs to look for the ErrorGroup interface.
@@ -47,13 +235,29 @@ type MultiErrCode struct {
 // It will combine any other MultiErrCode into just one MultiErrCode.
 // This is "horizontal" composition.
 // If you want normal "vertical" composition use BuildChain.
+//
+// The members of an other that is a group are added in its place, and any other error is added as a member.
+// Nil others are skipped.
+// If initial is nil, the first of the others that is not nil is used in its place.
+// If every error is nil, the ErrCode of the MultiErrCode is nil and it must not be used as an error.
+// Use CombineAll when there may be no errors at all: it returns nil in that case.
 func Combine(initial ErrorCode, others ...ErrorCode) MultiErrCode {
+	for initial == nil && len(others) > 0 {
+		initial, others = others[0], others[1:]
+	}
 	var rest []error
 	if group, ok := initial.(errors.ErrorGroup); ok {
 		rest = group.Errors()
 	}
 	for _, other := range others {
-		rest = append(rest, errors.Errors(other)...)
+		if other == nil {
+			continue
+		}
+		if group := errors.Errors(other); group != nil {
+			rest = append(rest, group...)
+		} else {
+			rest = append(rest, other)
+		}
 	}
 	return MultiErrCode{
 		ErrCode: initial,
@@ -61,6 +265,168 @@ func Combine(initial ErrorCode, others ...ErrorCode) MultiErrCode {
 	}
 }
 
+// CombineAll combines errors with Combine without needing an initial ErrorCode.
+// This is convenient for accumulating errors in a loop.
+// Nil errors are skipped and nil is returned if there are no errors.
+// A single error is returned as its ErrorCode rather than as a MultiErrCode.
+// As with CombineLabeled, an error that does not have an ErrorCode (as found by CodeChain) is converted with NewInternalErr.
+func CombineAll(errs ...error) ErrorCode {
+	codes := make([]ErrorCode, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		errCode := CodeChain(err)
+		if errCode == nil {
+			errCode = NewInternalErr(err)
+		}
+		codes = append(codes, errCode)
+	}
+	switch len(codes) {
+	case 0:
+		return nil
+	case 1:
+		return codes[0]
+	default:
+		return Combine(codes[0], codes[1:]...)
+	}
+}
+
+// CombineWithPolicy combines errors like Combine, but the code is chosen by the policy rather than being the code of the first error.
+// The error with the chosen code comes first, followed by the others in their order.
+// A group is replaced by its members, so that the policy can choose any of the members.
+// A nil policy chooses the first code.
+// Nil errors are skipped and nil is returned if there are no errors.
+// A single error is returned as it is rather than as a MultiErrCode.
+//
+//	errcode.CombineWithPolicy(errcode.WorstHTTPStatus, notFoundErr, unavailableErr) // has UnavailableCode
+func CombineWithPolicy(policy CodePolicy, errCodes ...ErrorCode) ErrorCode {
+	var members []error
+	for _, errCode := range errCodes {
+		if errCode == nil {
+			continue
+		}
+		if group := errors.Errors(errCode); group != nil {
+			members = append(members, group...)
+		} else {
+			members = append(members, errCode)
+		}
+	}
+	// only a member that is an ErrorCode can give the code
+	var candidates []ErrorCode
+	var indexes []int
+	for i, member := range members {
+		if errCode, ok := member.(ErrorCode); ok {
+			candidates = append(candidates, errCode)
+			indexes = append(indexes, i)
+		}
+	}
+	switch {
+	case len(members) == 0:
+		return nil
+	case len(members) == 1 && len(candidates) == 1:
+		return candidates[0]
+	case len(candidates) == 0:
+		return JoinCodes(members...)
+	}
+	codes := make([]Code, len(candidates))
+	for i, candidate := range candidates {
+		codes[i] = candidate.Code()
+	}
+	chosen := 0
+	if policy != nil {
+		chosen = policy(codes)
+	}
+	rest := make([]error, 0, len(members)-1)
+	rest = append(rest, members[:indexes[chosen]]...)
+	rest = append(rest, members[indexes[chosen]+1:]...)
+	return MultiErrCode{ErrCode: candidates[chosen], rest: rest}
+}
+
+// JoinCodes joins errors with the semantics of the standard library errors.Join into a JoinedErrCode.
+// Nil errors are discarded and nil is returned if there are no errors.
+// The code is the code of the first error with an ErrorCode (see CodeChain) and ErrorCodes gives all of the codes.
+// If no error has an ErrorCode, the code is InternalCode as given by NewInternalErr.
+func JoinCodes(errs ...error) ErrorCode {
+	joined := JoinedErrCode{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		joined.errs = append(joined.errs, err)
+		if joined.ErrCode == nil {
+			joined.ErrCode = CodeChain(err)
+		}
+	}
+	if len(joined.errs) == 0 {
+		return nil
+	}
+	if joined.ErrCode == nil {
+		joined.ErrCode = NewInternalErr(stderrors.Join(joined.errs...))
+	}
+	return joined
+}
+
+// JoinedErrCode is an ErrorCode for errors joined by JoinCodes.
+// As with errors.Join, Error() gives the messages separated by newlines
+// and the errors are given by Unwrap() []error for errors.Is and errors.As.
+// ErrCode is the ErrorCode that gives the code.
+type JoinedErrCode struct {
+	ErrCode ErrorCode
+	errs    []error
+}
+
+// Error gives the messages of the errors separated by newlines.
+func (e JoinedErrCode) Error() string {
+	return stderrors.Join(e.errs...).Error()
+}
+
+// Unwrap gives the joined errors.
+func (e JoinedErrCode) Unwrap() []error {
+	return e.errs
+}
+
+// Code returns the Code of ErrCode.
+func (e JoinedErrCode) Code() Code {
+	return e.ErrCode.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e JoinedErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*JoinedErrCode)(nil) // assert implements interface
+
+// CombineLabeled combines errors that each have a label, for example the name of the subtask that produced the error.
+// The label is retained with LabeledErrCode and shows up in the label field of JSONFormat.
+// Members are ordered by their label.
+// An error that does not have an ErrorCode (as found by CodeChain) is converted with NewInternalErr.
+// Nil errors are skipped and nil is returned if there are no errors.
+func CombineLabeled(labeled map[string]error) ErrorCode {
+	labels := make([]string, 0, len(labeled))
+	for label, err := range labeled {
+		if err != nil {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	sort.Strings(labels)
+
+	codes := make([]ErrorCode, len(labels))
+	for i, label := range labels {
+		err := labeled[label]
+		errCode := CodeChain(err)
+		if errCode == nil {
+			errCode = NewInternalErr(err)
+		}
+		codes[i] = LabeledErrCode{Label: label, Err: errCode}
+	}
+	return Combine(codes[0], codes[1:]...)
+}
+
 var _ ErrorCode = (*MultiErrCode)(nil)         // assert implements interface
 var _ unwrapError = (*MultiErrCode)(nil)       // assert implements interface
 var _ errors.ErrorGroup = (*MultiErrCode)(nil) // assert implements interface
@@ -84,22 +450,42 @@ func (e MultiErrCode) Code() Code {
 	return e.ErrCode.Code()
 }
 
+// Is supports errors.Is with a CodeTarget (see CodeIs) or a sentinel ErrorCode.
+// Every member of the group is checked.
+func (e MultiErrCode) Is(target error) bool {
+	switch target.(type) {
+	case CodeTarget, ErrorCode:
+	default:
+		return false
+	}
+	for _, member := range e.Errors() {
+		if errors.Is(member, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // Unwrap fullfills the errors package Unwrap function
 func (e MultiErrCode) Unwrap() error {
 	return e.ErrCode
 }
 
 // CodeChain resolves wrapped errors down to the first ErrorCode.
+// An error that implements As (for errors.As) can expose an ErrorCode by assigning to a *ErrorCode target.
 // An error that is an ErrorGroup with multiple codes will have its error codes combined to a MultiErrCode.
 // If the given error is not an ErrorCode, a ContextChain will be returned with Top set to the given error.
 // This allows the return object to maintain a full Error() message.
+// If no ErrorCode is found, the classifiers added with RegisterClassifier are consulted.
 func CodeChain(errInput error) ErrorCode {
 	checkError := func(err error) ErrorCode {
 		if errCode, ok := err.(ErrorCode); ok {
 			return errCode
-		} else if eg, ok := err.(errors.ErrorGroup); ok {
+		} else if errCode := asErrorCode(err); errCode != nil {
+			return errCode
+		} else if members := errors.Errors(err); members != nil {
 			group := []ErrorCode{}
-			for _, errItem := range eg.Errors() {
+			for _, errItem := range members {
 				if itemCode := CodeChain(errItem); itemCode != nil {
 					group = append(group, itemCode)
 				}
@@ -128,6 +514,23 @@ func CodeChain(errInput error) ErrorCode {
 		err = errors.Unwrap(err)
 	}
 
+	if errCode := Classify(errInput); errCode != nil {
+		return ChainContext{errInput, errCode}
+	}
+	return nil
+}
+
+// asErrorCode gives the ErrorCode exposed by an error that implements As for errors.As.
+// The target is a pointer to an ErrorCode interface so that As can assign any ErrorCode to it.
+func asErrorCode(err error) ErrorCode {
+	asErr, ok := err.(interface{ As(interface{}) bool })
+	if !ok {
+		return nil
+	}
+	var errCode ErrorCode
+	if asErr.As(&errCode) && errCode != nil {
+		return errCode
+	}
 	return nil
 }
 
@@ -145,6 +548,11 @@ func (err ChainContext) Code() Code {
 	return err.ErrCode.Code()
 }
 
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (err ChainContext) Is(target error) bool {
+	return isCodeTarget(err.Code(), target)
+}
+
 // Error satisfies the Error interface
 func (err ChainContext) Error() string {
 	return err.Top.Error()
@@ -158,8 +566,33 @@ func (err ChainContext) Unwrap() error {
 	return err.ErrCode
 }
 
+// GetErrorCode gives the ErrorCode that was found in the chain.
+// A ChainContext of a ChainContext is resolved to the innermost ErrorCode.
+func (err ChainContext) GetErrorCode() ErrorCode {
+	errCode := err.ErrCode
+	for {
+		chain, ok := errCode.(ChainContext)
+		if !ok {
+			return errCode
+		}
+		errCode = chain.ErrCode
+	}
+}
+
+// AnnotatedMsg gives the message of Top, which includes the annotations added to the ErrorCode.
+func (err ChainContext) AnnotatedMsg() string {
+	return err.Top.Error()
+}
+
+// MarshalJSON gives the JSON of the JSONFormat of the error, as given by NewJSONFormat.
+// Without a user message, the Msg is the annotated message of Top rather than the message of ErrCode.
+func (err ChainContext) MarshalJSON() ([]byte, error) {
+	return MarshalJSONFormat(NewJSONFormat(err))
+}
+
 var _ ErrorCode = (*ChainContext)(nil)
 var _ unwrapError = (*ChainContext)(nil)
+var _ json.Marshaler = (*ChainContext)(nil)
 
 // Format implements the Formatter interface
 func (err ChainContext) Format(s fmt.State, verb rune) {
@@ -167,7 +600,7 @@ func (err ChainContext) Format(s fmt.State, verb rune) {
 	case 'v':
 		if s.Flag('+') {
 			fmt.Fprintf(s, "%+v\n", err.ErrCode)
-			if errors.HasStack(err.ErrCode) {
+			if HasStack(err.ErrCode) {
 				fmt.Fprintf(s, "%v", err.Top)
 			} else {
 				fmt.Fprintf(s, "%+v", err.Top)
@@ -192,7 +625,7 @@ func (e MultiErrCode) Format(s fmt.State, verb rune) {
 	case 'v':
 		if s.Flag('+') {
 			fmt.Fprintf(s, "%+v\n", e.ErrCode)
-			if errors.HasStack(e.ErrCode) {
+			if HasStack(e.ErrCode) {
 				for _, nextErr := range e.rest {
 					fmt.Fprintf(s, "%v", nextErr)
 				}
@@ -212,3 +645,42 @@ func (e MultiErrCode) Format(s fmt.State, verb rune) {
 		fmt.Fprintf(s, "%q\n", e.rest)
 	}
 }
+
+// HasCode checks if any ErrorCode in the error has exactly the given code.
+// This is analogous to errors.Is but for codes.
+// The error is unwrapped, including groups.
+// If no ErrorCode is found, the registered classifiers are consulted.
+func HasCode(err error, code Code) bool {
+	codeStr := code.CodeStr()
+	return anyCode(err, func(found Code) bool { return found.CodeStr() == codeStr })
+}
+
+// HasAncestor checks if any ErrorCode in the error has the given code or a descendant of it.
+// The error is unwrapped, including groups.
+// If no ErrorCode is found, the registered classifiers are consulted.
+func HasAncestor(err error, ancestor Code) bool {
+	return anyCode(err, func(found Code) bool { return found.IsAncestor(ancestor) })
+}
+
+func anyCode(err error, test func(Code) bool) bool {
+	if err == nil {
+		return false
+	}
+	foundCode := false
+	matched := walkDeep(err, func(err error) bool {
+		if errCode, ok := err.(ErrorCode); ok {
+			foundCode = true
+			return test(errCode.Code())
+		}
+		return false
+	})
+	if matched {
+		return true
+	}
+	if !foundCode {
+		if errCode := Classify(err); errCode != nil {
+			return test(errCode.Code())
+		}
+	}
+	return false
+}
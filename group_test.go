@@ -1,6 +1,8 @@
 package errcode_test
 
 import (
+	"encoding/json"
+	stderrors "errors"
 	"reflect"
 	"testing"
 
@@ -65,6 +67,30 @@ func TestErrorCodeChain(t *testing.T) {
 	// TODO: vertical composition
 }
 
+func TestChainContextJSON(t *testing.T) {
+	notFound := errcode.NewNotFoundErr(errors.New("no item"))
+	ann := errors.Wrap(notFound, "get item")
+	chain := errcode.CodeChain(errors.Wrap(ann, "handler")).(errcode.ChainContext)
+	if chain.GetErrorCode() != notFound {
+		t.Errorf("expected the ErrorCode, got %v", chain.GetErrorCode())
+	}
+	if msg := chain.AnnotatedMsg(); msg != "handler: get item: no item" {
+		t.Errorf("unexpected message %s", msg)
+	}
+	nested := errcode.ChainContext{Top: errors.Wrap(chain, "outer"), ErrCode: chain}
+	if nested.GetErrorCode() != notFound {
+		t.Errorf("expected the innermost ErrorCode, got %v", nested.GetErrorCode())
+	}
+
+	b, err := json.Marshal(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != `{"code":"missing","msg":"handler: get item: no item","data":null}` {
+		t.Errorf("unexpected JSON %s", got)
+	}
+}
+
 func AssertCodeChain(t *testing.T, input error, expected errcode.ErrorCode) {
 	t.Helper()
 	output := errcode.CodeChain(input)
@@ -72,3 +98,311 @@ func AssertCodeChain(t *testing.T, input error, expected errcode.ErrorCode) {
 		t.Errorf("ErrorCodeChain expected type %T value %#v%v\n				  got type %T value %#v%v", expected, expected, expected, output, output, output)
 	}
 }
+
+func TestCombineLabeled(t *testing.T) {
+	if errcode.CombineLabeled(nil) != nil {
+		t.Errorf("expected nil")
+	}
+	if errcode.CombineLabeled(map[string]error{"a": nil}) != nil {
+		t.Errorf("expected nil")
+	}
+
+	combined := errcode.CombineLabeled(map[string]error{
+		"fetch":  errcode.NewNotFoundErr(errors.New("no item")),
+		"delete": errcode.NewNotFoundErr(errors.New("no other item")),
+		"store":  errors.New("disk full"),
+	})
+	AssertCode(t, combined, errcode.NotFoundCode.CodeStr())
+	if label := errcode.Label(combined); label != "delete" {
+		t.Errorf("expected label delete, got %s", label)
+	}
+	ErrorEquals(t, combined, "delete: no other item; fetch: no item; store: disk full")
+
+	jsonFormat := errcode.NewJSONFormat(combined)
+	AssertLength(t, jsonFormat.Others, 2)
+	expected := []struct {
+		label string
+		code  errcode.CodeStr
+		msg   string
+	}{
+		{"fetch", errcode.NotFoundCode.CodeStr(), "fetch: no item"},
+		{"store", errcode.InternalCode.CodeStr(), "store: disk full"},
+	}
+	for i, other := range jsonFormat.Others {
+		if other.Label != expected[i].label || other.Code != expected[i].code || other.Msg != expected[i].msg {
+			t.Errorf("expected %v, got %v", expected[i], other)
+		}
+	}
+}
+
+func TestErrorCodesOrder(t *testing.T) {
+	notFound := errcode.NewNotFoundErr(errors.New("not found"))
+	invalid := errcode.NewInvalidInputErr(errors.New("invalid"))
+	internal := errcode.NewInternalErr(errors.New("internal"))
+	exists := errcode.NewAlreadyExistsErr(errors.New("exists"))
+	forbidden := errcode.NewForbiddenErr(errors.New("forbidden"))
+
+	assertOrder := func(err error, expected ...errcode.ErrorCode) {
+		t.Helper()
+		// repeat to catch any non-deterministic iteration
+		for i := 0; i < 20; i++ {
+			codes := errcode.ErrorCodes(err)
+			if len(codes) != len(expected) {
+				t.Fatalf("expected %d codes, got %v", len(expected), codes)
+			}
+			for j, code := range codes {
+				if code.Code().CodeStr() != expected[j].Code().CodeStr() {
+					t.Fatalf("at %d expected %v, got %v", j, expected[j].Code().CodeStr(), code.Code().CodeStr())
+				}
+			}
+		}
+	}
+
+	// stdlib errors.Join trees, nested joins are expanded in place
+	joined := stderrors.Join(notFound, stderrors.Join(invalid, exists), internal)
+	assertOrder(joined, notFound, invalid, exists, internal)
+
+	// Combine followed by its members
+	combined := errcode.Combine(forbidden, notFound, errcode.Combine(invalid, exists))
+	assertOrder(combined, forbidden, notFound, invalid, exists)
+	assertOrder(stderrors.Join(combined, internal), forbidden, notFound, invalid, exists, internal)
+
+	// map based groups are ordered by label
+	labeled := errcode.CombineLabeled(map[string]error{
+		"e": exists, "d": notFound, "c": invalid, "b": internal, "a": forbidden,
+	})
+	assertOrder(labeled, forbidden, internal, invalid, notFound, exists)
+}
+
+func TestHasCode(t *testing.T) {
+	notFound := errcode.NewNotFoundErr(errors.New("no item"))
+	exists := errcode.NewAlreadyExistsErr(errors.New("exists"))
+	group := stderrors.Join(errors.New("uncoded"), errors.Wrap(errcode.Combine(MinimalError{}, exists), "wrapped"))
+
+	for _, test := range []struct {
+		err      error
+		code     errcode.Code
+		has      bool
+		ancestor bool
+	}{
+		{nil, errcode.NotFoundCode, false, false},
+		{errors.New("uncoded"), errcode.NotFoundCode, false, false},
+		{notFound, errcode.NotFoundCode, true, true},
+		{errors.Wrap(notFound, "wrapped"), errcode.NotFoundCode, true, true},
+		{exists, errcode.StateCode, false, true},
+		{group, errcode.AlreadyExistsCode, true, true},
+		{group, errcode.StateCode, false, true},
+		{group, registeredCode, true, true},
+		{group, errcode.InvalidInputCode, false, true},
+		{group, errcode.NotFoundCode, false, false},
+	} {
+		if has := errcode.HasCode(test.err, test.code); has != test.has {
+			t.Errorf("HasCode %v %v expected %v", test.err, test.code.CodeStr(), test.has)
+		}
+		if has := errcode.HasAncestor(test.err, test.code); has != test.ancestor {
+			t.Errorf("HasAncestor %v %v expected %v", test.err, test.code.CodeStr(), test.ancestor)
+		}
+	}
+}
+
+func TestCombineNil(t *testing.T) {
+	combined := errcode.Combine(nil, nil, errcode.NewNotFoundErr(errors.New("no item")), nil, errcode.NewGoneErr(errors.New("deleted")))
+	AssertCode(t, combined, errcode.NotFoundCode.CodeStr())
+	ErrorEquals(t, combined, "no item; deleted")
+	AssertLength(t, combined.Errors(), 2)
+
+	// every error is nil: there is no ErrCode, whereas CombineAll gives nil
+	if empty := errcode.Combine(nil, nil); empty.ErrCode != nil {
+		t.Errorf("expected an empty MultiErrCode, got %#v", empty)
+	}
+}
+
+func TestCombineAll(t *testing.T) {
+	if errcode.CombineAll() != nil || errcode.CombineAll(nil, nil) != nil {
+		t.Errorf("expected nil")
+	}
+	single := errcode.CombineAll(nil, errcode.NewNotFoundErr(errors.New("no item")))
+	if _, ok := single.(errcode.NotFoundErr); !ok {
+		t.Errorf("expected the single ErrorCode, got %T", single)
+	}
+
+	var errs []error
+	for _, item := range []string{"a", "b"} {
+		errs = append(errs, errors.New("no "+item))
+	}
+	combined := errcode.CombineAll(append(errs, nil, errcode.NewGoneErr(errors.New("deleted")))...)
+	AssertCode(t, combined, errcode.InternalCode.CodeStr())
+	ErrorEquals(t, combined, "no a; no b; deleted")
+	AssertLength(t, errcode.ErrorCodes(combined), 3)
+}
+
+func TestJoinCodes(t *testing.T) {
+	if errcode.JoinCodes(nil, nil) != nil {
+		t.Error("expected nil")
+	}
+	notFound := errcode.NewNotFoundErr(errors.New("no item"))
+	gone := errcode.NewGoneErr(errors.New("deleted"))
+	conflict := errcode.NewConflictErr(errors.New("version"))
+	joined := errcode.JoinCodes(stderrors.Join(notFound, errors.New("uncoded")), errors.Wrap(gone, "fetch"), nil, conflict)
+	AssertCode(t, joined, errcode.NotFoundCode.CodeStr())
+	ErrorEquals(t, joined, "no item\nuncoded\nfetch: deleted\nversion")
+	if !stderrors.Is(joined, gone) || !stderrors.Is(joined, errcode.CodeIs(errcode.ConflictCode)) {
+		t.Error("expected the joined errors to be found")
+	}
+
+	var codes []errcode.CodeStr
+	for _, errCode := range errcode.ErrorCodes(joined) {
+		codes = append(codes, errCode.Code().CodeStr())
+	}
+	expected := []errcode.CodeStr{"missing", "missing.gone", "state.conflict"}
+	if !reflect.DeepEqual(codes, expected) {
+		t.Errorf("expected %v, got %v", expected, codes)
+	}
+	if format := errcode.NewJSONFormat(joined); len(format.Others) != 2 {
+		t.Errorf("unexpected others %v", format.Others)
+	}
+
+	uncoded := errcode.JoinCodes(errors.New("a"), errors.New("b"))
+	AssertCode(t, uncoded, errcode.InternalCode.CodeStr())
+	stdJoined := errcode.CodeChain(stderrors.Join(errors.New("a"), gone))
+	AssertCode(t, stdJoined, errcode.GoneCode.CodeStr())
+}
+
+// bridgeErr exposes an ErrorCode through As without being an ErrorCode itself
+type bridgeErr struct{ status int }
+
+func (e bridgeErr) Error() string { return "bridge" }
+
+func (e bridgeErr) As(target interface{}) bool {
+	if errCode, ok := target.(*errcode.ErrorCode); ok && e.status == 404 {
+		*errCode = errcode.NewNotFoundErr(e)
+		return true
+	}
+	return false
+}
+
+func TestCodeChainAs(t *testing.T) {
+	err := errors.Wrap(bridgeErr{status: 404}, "fetch")
+	AssertCode(t, errcode.CodeChain(err), errcode.NotFoundCode.CodeStr())
+	ErrorEquals(t, errcode.CodeChain(err), "fetch: bridge")
+	var errCode errcode.ErrorCode
+	if !stderrors.As(err, &errCode) || !errcode.IsNotFound(errCode) {
+		t.Errorf("expected errors.As to find the code, got %v", errCode)
+	}
+	if errcode.CodeChain(bridgeErr{status: 500}) != nil {
+		t.Error("expected no code")
+	}
+}
+
+func TestGroupAccessors(t *testing.T) {
+	plain := errcode.NewNotFoundErr(errors.New("no item"))
+	decorated := errcode.Op("prices.get")(errcode.WithUserMsg("No price", errcode.WithClientData("price-1", errcode.NewGoneErr(errors.New("deleted")))))
+	other := errcode.Op("stock.get")(errcode.WithClientData("stock-1", errcode.NewGoneErr(errors.New("deleted"))))
+
+	for _, err := range []errcode.ErrorCode{
+		errcode.Combine(plain, decorated, other),
+		errcode.JoinCodes(plain, decorated, other),
+		errcode.Combine(plain, errcode.JoinCodes(errors.New("untyped"), decorated), other),
+	} {
+		if op := errcode.Operation(err); op != "prices.get" {
+			t.Errorf("expected the operation of the first member with one, got %q", op)
+		}
+		if msg := errcode.GetUserMsg(err); msg != "No price" {
+			t.Errorf("expected the user message of the first member with one, got %q", msg)
+		}
+		if data := errcode.ClientData(err); data != "price-1" {
+			t.Errorf("expected the data of the first member with data, got %v", data)
+		}
+	}
+
+	first := errcode.Combine(other, decorated)
+	if op, data := errcode.OperationClientData(first); op != "stock.get" || data != "stock-1" {
+		t.Errorf("expected the first member, got %s %v", op, data)
+	}
+	if msg := errcode.GetUserMsg(errcode.Combine(plain)); msg != "" {
+		t.Errorf("expected no user message, got %q", msg)
+	}
+}
+
+func TestCombineWithPolicy(t *testing.T) {
+	notFound := errcode.NewNotFoundErr(errors.New("no item"))
+	conflict := errcode.NewConflictErr(errors.New("changed"))
+	unavailable := errcode.NewUnavailableErr(errors.New("db down"))
+	internal := errcode.NewInternalErr(errors.New("bug"))
+
+	for _, test := range []struct {
+		policy   errcode.CodePolicy
+		errCodes []errcode.ErrorCode
+		code     errcode.Code
+		msg      string
+	}{
+		{errcode.WorstHTTPStatus, []errcode.ErrorCode{notFound, unavailable, internal}, errcode.UnavailableCode, "db down; no item; bug"},
+		{errcode.FirstServerError, []errcode.ErrorCode{notFound, errcode.Combine(conflict, internal), unavailable}, errcode.InternalCode, "bug; no item; changed; db down"},
+		{errcode.FirstServerError, []errcode.ErrorCode{notFound, conflict}, errcode.NotFoundCode, "no item; changed"},
+		{errcode.MostSpecificCode, []errcode.ErrorCode{notFound, conflict, internal}, errcode.ConflictCode, "changed; no item; bug"},
+		{nil, []errcode.ErrorCode{nil, notFound, unavailable}, errcode.NotFoundCode, "no item; db down"},
+	} {
+		combined := errcode.CombineWithPolicy(test.policy, test.errCodes...)
+		if combined.Code().CodeStr() != test.code.CodeStr() || combined.Error() != test.msg {
+			t.Errorf("expected %s %q, got %s %q", test.code.CodeStr(), test.msg, combined.Code().CodeStr(), combined.Error())
+		}
+	}
+	if errcode.CombineWithPolicy(errcode.WorstHTTPStatus, nil) != nil {
+		t.Error("expected nil")
+	}
+	if single := errcode.CombineWithPolicy(errcode.WorstHTTPStatus, notFound); single != notFound {
+		t.Errorf("expected the single error, got %v", single)
+	}
+}
+
+func TestUnwrapped(t *testing.T) {
+	if errcode.Unwrapped(errcode.NewNotFoundErr(errors.New("no item"))) != nil {
+		t.Error("expected nil without a group")
+	}
+	inner := errcode.Combine(errcode.NewGoneErr(errors.New("deleted")), errcode.NewConflictErr(errors.New("version")))
+	combined := errcode.Op("items")(errcode.JoinCodes(errcode.NewNotFoundErr(errors.New("no item")), errors.New("uncoded"), errcode.Op("prices")(inner)))
+
+	var codes []errcode.CodeStr
+	for _, errCode := range errcode.Unwrapped(combined) {
+		codes = append(codes, errCode.Code().CodeStr())
+	}
+	expected := []errcode.CodeStr{"missing", "internal", "missing.gone"}
+	if !reflect.DeepEqual(codes, expected) {
+		t.Errorf("expected %v, got %v", expected, codes)
+	}
+}
+
+func TestCombineOthers(t *testing.T) {
+	first := errcode.NewNotFoundErr(errors.New("no item"))
+	second := errcode.NewNotFoundErr(errors.New("no other item"))
+	third := errcode.NewInvalidInputErr(errors.New("bad input"))
+
+	combined := errcode.Combine(first, second)
+	AssertLength(t, combined.Errors(), 2)
+	ErrorEquals(t, combined, "no item; no other item")
+
+	nested := errcode.Combine(third, combined)
+	AssertLength(t, nested.Errors(), 3)
+	ErrorEquals(t, nested, "bad input; no item; no other item")
+}
+
+func TestErrorCodesSameCode(t *testing.T) {
+	first := errcode.NewNotFoundErr(errors.New("no item"))
+	second := errcode.NewNotFoundErr(errors.New("no other item"))
+
+	// group members with the same code are different errors
+	errCodes := errcode.ErrorCodes(errcode.Combine(first, second))
+	AssertLength(t, errCodes, 2)
+	if errCodes[1] != errcode.ErrorCode(second) {
+		t.Errorf("expected the second member, got %v", errCodes[1])
+	}
+	AssertLength(t, errcode.UniqueErrorCodes(errcode.Combine(first, second)), 1)
+
+	// an ErrorCode that wraps another with the same code is a layer of the same error
+	AssertLength(t, errcode.ErrorCodes(errcode.Op("items.get")(first)), 1)
+	layered := errcode.ErrorCodes(errcode.Combine(errcode.NewInvalidInputErr(errors.New("bad input")), errcode.Op("items.get")(first)))
+	AssertLength(t, layered, 2)
+	if layered[1].Code() != errcode.NotFoundCode {
+		t.Errorf("expected the layered member once, got %v", layered)
+	}
+}
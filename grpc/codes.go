@@ -1,5 +1,7 @@
 // Package grpc attaches GRPC codes to the standard error codes.
 // It also provides helpers for integrating with GRPC.
+// The server interceptors convert the ErrorCodes returned by handlers to a GRPC status
+// and the client interceptors convert a received GRPC status back to an ErrorCode.
 //
 // Note that not all GRPC codes are mapped right now: you are welcome to contribute more.
 // Available mappings are documented here: https://cloud.google.com/apis/design/errors
@@ -15,13 +17,33 @@
 //	SetCode(errcode.AlreadyExistsCode, codes.AlreadyExists)
 //	SetCode(errcode.OutOfRangeCode, codes.OutOfRange)
 //	SetCode(errcode.UnimplementedCode, codes.Unimplemented)
+//	SetCode(errcode.CanceledCode, codes.Canceled)
+//	SetCode(errcode.DeadlineExceededCode, codes.DeadlineExceeded)
+//	SetCode(errcode.TimeoutCode, codes.DeadlineExceeded)
+//	SetCode(errcode.TooManyRequestsCode, codes.ResourceExhausted)
+//	SetCode(errcode.QuotaCode, codes.ResourceExhausted)
+//	SetCode(errcode.ConflictCode, codes.Aborted)
+//	SetCode(errcode.UnavailableCode, codes.Unavailable)
+//	SetCode(errcode.NotAcceptableCode, codes.InvalidArgument)
+//	SetCode(errcode.UnprocessableEntityCode, codes.FailedPrecondition)
+//
+// An application can change these mappings with OverrideCode and OverrideFromGRPC.
+// Because this package is initialized before any package that imports it,
+// an override in the init function or a package variable of the application always takes precedence.
 package grpc
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"github.com/gregwebs/errcode"
 	"github.com/gregwebs/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // StatusGRPC is the interface to a GRPC status code
@@ -47,9 +69,8 @@ func (wrapper codeStatus) Unwrap() error {
 	return wrapper.ErrorCode
 }
 
-var _ errcode.ErrorCode = (*codeStatus)(nil)     // assert implements interface
-var _ StatusGRPC = (*codeStatus)(nil)     // assert implements interface
-
+var _ errcode.ErrorCode = (*codeStatus)(nil) // assert implements interface
+var _ StatusGRPC = (*codeStatus)(nil)        // assert implements interface
 
 // WrapAsGRPC constructs a value that responds as both an ErrorCode and as a GRPC status
 func WrapAsGRPC(code errcode.ErrorCode) ErrorCodeStatus {
@@ -57,24 +78,114 @@ func WrapAsGRPC(code errcode.ErrorCode) ErrorCodeStatus {
 }
 
 // Status creates a GRPC Status object from an ErrorCode.
-// TODO: add more information in the details fields.
+// If the error has a Retry-After (see errcode.RetryDelay), it is added as a RetryInfo detail.
+// A retry time is converted to the delay from now.
+// Use StatusWithDetails to also add an ErrorInfo detail with the JSONFormat of the error.
 func Status(code errcode.ErrorCode) *status.Status {
-	return status.New(GetCode(code.Code()), code.Error())
+	st := status.New(GetCode(code.Code()), code.Error())
+	if retryAfter := errcode.RetryDelay(code, time.Now()); retryAfter > 0 {
+		if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+			st = withDetails
+		}
+	}
+	return st
+}
+
+// DrainUnaryServerInterceptor responds with errcode.NewDrainingErr while draining (see errcode.SetDraining).
+// Otherwise the request is given to the handler.
+func DrainUnaryServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if errcode.IsDraining() {
+		return nil, WrapAsGRPC(errcode.NewDrainingErr())
+	}
+	return handler(ctx, req)
+}
+
+// DrainStreamServerInterceptor responds with errcode.NewDrainingErr while draining (see errcode.SetDraining).
+// Otherwise the stream is given to the handler.
+func DrainStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if errcode.IsDraining() {
+		return WrapAsGRPC(errcode.NewDrainingErr())
+	}
+	return handler(srv, ss)
+}
+
+// RecoverUnaryServerInterceptor responds to a panic in the handler with an internal error from errcode.RecoverToErrorCode.
+// The stack trace of the error starts at the panic.
+func RecoverUnaryServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = WrapAsGRPC(errcode.RecoverToErrorCode(recovered))
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// RecoverStreamServerInterceptor responds to a panic in the handler with an internal error from errcode.RecoverToErrorCode.
+// The stack trace of the error starts at the panic.
+func RecoverStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = WrapAsGRPC(errcode.RecoverToErrorCode(recovered))
+		}
+	}()
+	return handler(srv, ss)
 }
 
 var grpcMetaData = make(errcode.MetaData)
 
+var (
+	fromGRPCMu sync.RWMutex
+	fromGRPC   = make(map[codes.Code]errcode.Code)
+)
+
 // SetCode adds a GRPC code to the meta data of a code.
 // The code can be retrieved with GRPCCode.
+// The first code set for a GRPC code is used when converting a GRPC status without details to an ErrorCode (see FromGRPCError).
 // Panic if the metadata is already set for the code.
 // Returns itself.
 func SetCode(code errcode.Code, grpcCode codes.Code) errcode.Code {
 	if err := code.SetMetaData(grpcMetaData, grpcCode); err != nil {
 		panic(errors.Wrap(err, "SetGRPC"))
 	}
+	fromGRPCMu.Lock()
+	defer fromGRPCMu.Unlock()
+	if _, ok := fromGRPC[grpcCode]; !ok {
+		fromGRPC[grpcCode] = code
+	}
 	return code
 }
 
+// OverrideCode sets the GRPC code even if it is already set, unlike SetCode.
+// For example, an application can give a code mapped by this package a different GRPC code.
+// The code is also used when converting a GRPC status without details to an ErrorCode
+// if no code was set for the GRPC code: use OverrideFromGRPC to change that.
+// Returns itself.
+func OverrideCode(code errcode.Code, grpcCode codes.Code) errcode.Code {
+	code.SetMetaDataForce(grpcMetaData, grpcCode)
+	fromGRPCMu.Lock()
+	defer fromGRPCMu.Unlock()
+	if _, ok := fromGRPC[grpcCode]; !ok {
+		fromGRPC[grpcCode] = code
+	}
+	return code
+}
+
+// OverrideFromGRPC sets the code used by FromGRPCError for a GRPC status without details.
+// Normally this is the first code set for the GRPC code with SetCode.
+func OverrideFromGRPC(grpcCode codes.Code, code errcode.Code) {
+	fromGRPCMu.Lock()
+	defer fromGRPCMu.Unlock()
+	fromGRPC[grpcCode] = code
+}
+
+// WithCode gives a function that sets the GRPC code with SetCode.
+// This is used in the With field of an errcode.CodeSpec.
+func WithCode(grpcCode codes.Code) func(errcode.Code) errcode.Code {
+	return func(code errcode.Code) errcode.Code {
+		return SetCode(code, grpcCode)
+	}
+}
+
 // GetCode retrieves the GRPC code for a code or its first ancestor with a GRPC code.
 // If none are specified, it defaults to Unkown (Code 2).
 // The return of this is a GRPC codes package Code, not an errcode.Code
@@ -96,4 +207,20 @@ func init() {
 	SetCode(errcode.AlreadyExistsCode, codes.AlreadyExists)
 	SetCode(errcode.OutOfRangeCode, codes.OutOfRange)
 	SetCode(errcode.UnimplementedCode, codes.Unimplemented)
+	SetCode(errcode.CanceledCode, codes.Canceled)
+	SetCode(errcode.DeadlineExceededCode, codes.DeadlineExceeded)
+	SetCode(errcode.TimeoutCode, codes.DeadlineExceeded)
+	SetCode(errcode.TooManyRequestsCode, codes.ResourceExhausted)
+	SetCode(errcode.QuotaCode, codes.ResourceExhausted)
+	SetCode(errcode.ConflictCode, codes.Aborted)
+	SetCode(errcode.UnavailableCode, codes.Unavailable)
+	SetCode(errcode.NotAcceptableCode, codes.InvalidArgument)
+	SetCode(errcode.UnprocessableEntityCode, codes.FailedPrecondition)
+	errcode.RegisterDocMapping("grpc", func(code errcode.Code) string {
+		// a code without a mapping is left out rather than documented with the default
+		if code.MetaDataFromAncestors(grpcMetaData) == nil {
+			return ""
+		}
+		return GetCode(code).String()
+	})
 }
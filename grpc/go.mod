@@ -2,14 +2,19 @@ module github.com/gregwebs/errcode/grpc
 
 require (
 	github.com/gregwebs/errcode v0.11.0
-	github.com/gregwebs/errors v1.0.0
+	github.com/gregwebs/errors v1.5.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98
 	google.golang.org/grpc v1.58.2
+	google.golang.org/protobuf v1.31.0
 )
 
 require (
 	github.com/golang/protobuf v1.5.3 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
-	google.golang.org/protobuf v1.31.0 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
 )
 
-go 1.18
+go 1.21.9
+
+replace github.com/gregwebs/errcode => ../
@@ -14,12 +14,19 @@
 package grpc_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/gregwebs/errcode"
 	"github.com/gregwebs/errcode/grpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpclib "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Test setting the HTTP code
@@ -45,6 +52,58 @@ func TestWrapAsGrpc(t *testing.T) {
 	AssertGRPCCode(t, err, codes.Internal)
 }
 
+func TestContextErrorCodes(t *testing.T) {
+	AssertGRPCCode(t, errcode.FromContextError(context.Canceled), codes.Canceled)
+	AssertGRPCCode(t, errcode.FromContextError(context.DeadlineExceeded), codes.DeadlineExceeded)
+}
+
+func TestWithCode(t *testing.T) {
+	defined := errcode.InvalidInputCode.DefineChildren(map[errcode.CodeStr]errcode.CodeSpec{
+		"grpcdefined": {With: []func(errcode.Code) errcode.Code{grpc.WithCode(codes.OutOfRange)}},
+	})
+	if grpcCode := grpc.GetCode(defined["input.grpcdefined"]); grpcCode != codes.OutOfRange {
+		t.Errorf("expected OutOfRange, got %v", grpcCode)
+	}
+	if mapped := errcode.NewCodeDoc(errcode.NotFoundCode).Mappings["grpc"]; mapped != "NotFound" {
+		t.Errorf("expected the grpc doc mapping, got %s", mapped)
+	}
+	if mapped, ok := errcode.NewCodeDoc(errcode.NewCode("grpcunmapped")).Mappings["grpc"]; ok {
+		t.Errorf("expected no doc mapping for an unmapped code, got %s", mapped)
+	}
+}
+
+func TestMappings(t *testing.T) {
+	for code, grpcCode := range map[errcode.Code]codes.Code{
+		errcode.UnavailableCode:         codes.Unavailable,
+		errcode.TimeoutGatewayCode:      codes.DeadlineExceeded,
+		errcode.TimeoutRequestCode:      codes.DeadlineExceeded,
+		errcode.NotAcceptableCode:       codes.InvalidArgument,
+		errcode.UnprocessableEntityCode: codes.FailedPrecondition,
+		errcode.TooManyRequestsCode:     codes.ResourceExhausted,
+		errcode.QuotaCode:               codes.ResourceExhausted,
+		errcode.QuotaStorageCode:        codes.ResourceExhausted,
+		errcode.QuotaRateCode:           codes.ResourceExhausted,
+	} {
+		if mapped := grpc.GetCode(code); mapped != grpcCode {
+			t.Errorf("expected %v for %s, got %v", grpcCode, code.CodeStr(), mapped)
+		}
+	}
+}
+
+func TestOverrideCode(t *testing.T) {
+	code := errcode.StateCode.Child("state.grpcoverride")
+	grpc.SetCode(code, codes.Aborted)
+	if grpc.OverrideCode(code, codes.FailedPrecondition); grpc.GetCode(code) != codes.FailedPrecondition {
+		t.Errorf("expected the override, got %v", grpc.GetCode(code))
+	}
+
+	grpc.OverrideFromGRPC(codes.DataLoss, code)
+	err := grpc.FromGRPCError(status.Error(codes.DataLoss, "lost"))
+	if errCode, ok := err.(errcode.ErrorCode); !ok || errCode.Code().CodeStr() != code.CodeStr() {
+		t.Errorf("expected the overridden code, got %#v", err)
+	}
+}
+
 func AssertGRPCCode(t *testing.T, code errcode.ErrorCode, grpcCode codes.Code) {
 	t.Helper()
 	expected := grpc.GetCode(code.Code())
@@ -52,3 +111,122 @@ func AssertGRPCCode(t *testing.T, code errcode.ErrorCode, grpcCode codes.Code) {
 		t.Errorf("excpected HTTP Code %v but got %v", grpcCode, expected)
 	}
 }
+
+func TestDrainUnaryServerInterceptor(t *testing.T) {
+	defer errcode.SetDraining(false)
+	errcode.SetDraining(true)
+	_, err := grpc.DrainUnaryServerInterceptor(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Error("expected the handler not to be called while draining")
+		return nil, nil
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", st.Code())
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a RetryInfo detail, got %v", st.Details())
+	}
+	if retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo); !ok || retryInfo.RetryDelay.AsDuration() != errcode.DefaultDrainRetryAfter {
+		t.Errorf("unexpected detail %v", st.Details()[0])
+	}
+}
+
+func TestStatusRetryAt(t *testing.T) {
+	err := errcode.WithRetryAt(time.Now().Add(time.Hour), errcode.NewUnavailableErr(fmt.Errorf("maintenance")))
+	st := grpc.Status(err)
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a RetryInfo detail, got %v", st.Details())
+	}
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	if delay := retryInfo.GetRetryDelay().AsDuration(); !ok || delay <= 59*time.Minute || delay > time.Hour {
+		t.Errorf("unexpected retry delay %v", delay)
+	}
+}
+
+func TestRecoverUnaryServerInterceptor(t *testing.T) {
+	_, err := grpc.RecoverUnaryServerInterceptor(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "panic: boom" {
+		t.Errorf("expected an internal error, got %v", st)
+	}
+	var panicErr errcode.PanicErr
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("expected a PanicErr, got %#v", err)
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	serverErr := errcode.Op("items.get").AddTo(errcode.WithClientData("item-1", errcode.NewNotFoundErr(fmt.Errorf("no item"))))
+	_, err := grpc.UnaryServerInterceptor(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, serverErr
+	})
+	if !errcode.IsNotFound(errcode.CodeChain(err)) {
+		t.Errorf("expected the server error to keep its code, got %v", err)
+	}
+	st := status.Convert(err)
+	if st.Code() != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", st.Code())
+	}
+
+	// The status as received by a client
+	received := status.ErrorProto(st.Proto())
+	clientErr := grpc.UnaryClientInterceptor(context.Background(), "/items/Get", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpclib.ClientConn, opts ...grpclib.CallOption) error {
+		return received
+	})
+	var remote errcode.RemoteErr
+	if !errors.As(clientErr, &remote) {
+		t.Fatalf("expected a RemoteErr, got %#v", clientErr)
+	}
+	if !errcode.IsNotFound(remote) || errcode.Operation(remote) != "items.get" || errcode.ClientData(remote) != "item-1" {
+		t.Errorf("unexpected client error %#v", remote)
+	}
+	if status.Code(clientErr) != codes.NotFound {
+		t.Errorf("expected the status to be kept, got %v", status.Code(clientErr))
+	}
+
+	plain := grpc.FromGRPCError(status.Error(codes.PermissionDenied, "denied"))
+	if errCode, ok := plain.(errcode.ErrorCode); !ok || !errCode.Code().IsAncestor(errcode.ForbiddenCode) {
+		t.Errorf("expected the code to be mapped back, got %#v", plain)
+	}
+	if err := grpc.FromGRPCError(status.Error(codes.Unknown, "unknown")); errcode.CodeChain(err) != nil {
+		t.Errorf("expected an unmapped status to be unchanged, got %#v", err)
+	}
+
+	uncoded := fmt.Errorf("uncoded")
+	if err := grpc.ToGRPCError(uncoded); err != uncoded {
+		t.Errorf("expected an error without a code to be unchanged, got %v", err)
+	}
+	wrapped := grpc.ToGRPCError(grpc.WrapAsGRPC(errcode.NewGoneErr(fmt.Errorf("deleted"))))
+	if _, ok := grpc.JSONFormatOf(status.Convert(wrapped)); !ok {
+		t.Error("expected WrapAsGRPC to be given the details")
+	}
+}
+
+type recvStream struct {
+	grpclib.ClientStream
+	err error
+}
+
+func (stream recvStream) RecvMsg(m interface{}) error {
+	return stream.err
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	received := status.ErrorProto(grpc.StatusWithDetails(errcode.NewGoneErr(fmt.Errorf("deleted"))).Proto())
+	stream, err := grpc.StreamClientInterceptor(context.Background(), nil, nil, "/items/List", func(ctx context.Context, desc *grpclib.StreamDesc, cc *grpclib.ClientConn, method string, opts ...grpclib.CallOption) (grpclib.ClientStream, error) {
+		return recvStream{err: received}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.RecvMsg(nil); !errcode.HasCode(err, errcode.GoneCode) {
+		t.Errorf("expected the received error to have its code, got %#v", err)
+	}
+	stream, _ = grpc.StreamClientInterceptor(context.Background(), nil, nil, "/items/List", func(ctx context.Context, desc *grpclib.StreamDesc, cc *grpclib.ClientConn, method string, opts ...grpclib.CallOption) (grpclib.ClientStream, error) {
+		return recvStream{err: io.EOF}, nil
+	})
+	if err := stream.RecvMsg(nil); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
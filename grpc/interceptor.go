@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/gregwebs/errcode"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ErrorInfoDomain is the Domain of the ErrorInfo detail added by StatusWithDetails.
+	// The Reason of the ErrorInfo is the CodeStr of the error.
+	ErrorInfoDomain = "errcode"
+	// MetadataJSONFormat is the metadata key of the ErrorInfo detail that holds the JSONFormat of the error.
+	MetadataJSONFormat = "errcode_json"
+)
+
+// ErrorInfo gives an ErrorInfo detail for an ErrorCode.
+// The Reason is the CodeStr and the JSONFormat is in the MetadataJSONFormat metadata.
+// This allows a client to reconstruct the ErrorCode with its data (see FromGRPCError).
+func ErrorInfo(errCode errcode.ErrorCode) *errdetails.ErrorInfo {
+	format := errcode.NewJSONFormat(errCode)
+	info := &errdetails.ErrorInfo{Reason: format.Code.String(), Domain: ErrorInfoDomain}
+	if bytes, err := json.Marshal(format); err == nil {
+		info.Metadata = map[string]string{MetadataJSONFormat: string(bytes)}
+	}
+	return info
+}
+
+// StatusWithDetails creates a GRPC Status object from an ErrorCode with Status
+// and adds the ErrorInfo detail.
+func StatusWithDetails(errCode errcode.ErrorCode) *status.Status {
+	st := Status(errCode)
+	if withDetails, err := st.WithDetails(ErrorInfo(errCode)); err == nil {
+		st = withDetails
+	}
+	return st
+}
+
+type detailedCodeStatus struct {
+	errcode.ErrorCode
+}
+
+func (wrapper detailedCodeStatus) GRPCStatus() *status.Status {
+	return StatusWithDetails(wrapper.ErrorCode)
+}
+
+func (wrapper detailedCodeStatus) Unwrap() error {
+	return wrapper.ErrorCode
+}
+
+var _ errcode.ErrorCode = (*detailedCodeStatus)(nil) // assert implements interface
+var _ StatusGRPC = (*detailedCodeStatus)(nil)        // assert implements interface
+
+// ToGRPCError converts an error with an ErrorCode (found with CodeChain) to an error with a GRPC status from StatusWithDetails.
+// The returned error is still an ErrorCode and the original error can be found with errors.Unwrap.
+// An error from WrapAsGRPC is converted as well so that it is given the details.
+// Another error that already has a GRPC status or an error that has no ErrorCode is returned unchanged.
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var grpcStatus StatusGRPC
+	if errors.As(err, &grpcStatus) {
+		if _, ok := grpcStatus.(codeStatus); !ok {
+			return err
+		}
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		return err
+	}
+	return detailedCodeStatus{errCode}
+}
+
+// FromGRPCError converts an error with a GRPC status to an ErrorCode.
+// If the status has the ErrorInfo detail of StatusWithDetails, an errcode.RemoteErr is returned.
+// Otherwise the GRPC code is mapped back to a code set with SetCode and marked with errcode.WithRemote.
+// An error without a GRPC status is returned unchanged.
+func FromGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+	if format, ok := JSONFormatOf(st); ok {
+		return errcode.NewRemoteErr(format, err)
+	}
+	fromGRPCMu.RLock()
+	code, ok := fromGRPC[st.Code()]
+	fromGRPCMu.RUnlock()
+	if !ok {
+		return err
+	}
+	return errcode.WithRemote(errcode.NewCodedError(err, code))
+}
+
+// JSONFormatOf decodes the JSONFormat in the ErrorInfo detail of a status added by StatusWithDetails.
+func JSONFormatOf(st *status.Status) (errcode.JSONFormat, bool) {
+	var format errcode.JSONFormat
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != ErrorInfoDomain {
+			continue
+		}
+		if err := json.Unmarshal([]byte(info.GetMetadata()[MetadataJSONFormat]), &format); err != nil || format.Code == "" {
+			return format, false
+		}
+		return format, true
+	}
+	return format, false
+}
+
+// UnaryServerInterceptor converts errors returned by handlers with ToGRPCError.
+// Handlers can then return an ErrorCode without calling Status.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	return resp, ToGRPCError(err)
+}
+
+// StreamServerInterceptor converts errors returned by handlers with ToGRPCError.
+// Handlers can then return an ErrorCode without calling Status.
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return ToGRPCError(handler(srv, ss))
+}
+
+// UnaryClientInterceptor converts received errors with FromGRPCError.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return FromGRPCError(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// StreamClientInterceptor converts received errors with FromGRPCError.
+// This includes the errors of sending and receiving messages, other than io.EOF.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, FromGRPCError(err)
+	}
+	return clientStream{stream}, nil
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (stream clientStream) SendMsg(m interface{}) error {
+	return fromStreamError(stream.ClientStream.SendMsg(m))
+}
+
+func (stream clientStream) RecvMsg(m interface{}) error {
+	return fromStreamError(stream.ClientStream.RecvMsg(m))
+}
+
+func fromStreamError(err error) error {
+	if err == io.EOF {
+		return err
+	}
+	return FromGRPCError(err)
+}
@@ -0,0 +1,130 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"net/http"
+
+	"github.com/gregwebs/errors"
+)
+
+var httpHeaderMetaData = make(MetaData)
+
+// SetHTTPHeaders adds HTTP response headers to the meta data.
+// The headers are also given for the descendants of the code.
+// For example, an application can give NotAuthenticatedCode a WWW-Authenticate header.
+// The headers are copied.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+//
+//	errcode.NotAuthenticatedCode.SetHTTPHeaders(http.Header{"Www-Authenticate": {`Bearer realm="api"`}})
+func (code Code) SetHTTPHeaders(header http.Header) Code {
+	if err := code.SetMetaData(httpHeaderMetaData, header.Clone()); err != nil {
+		panic(errors.Wrap(err, "SetHTTPHeaders"))
+	}
+	return code
+}
+
+// CodeHTTPHeaders retrieves the HTTP headers for a code or its first ancestor with HTTP headers.
+// If none are specified, it returns nil.
+func CodeHTTPHeaders(code Code) http.Header {
+	header := code.MetaDataFromAncestors(httpHeaderMetaData)
+	if header == nil {
+		return nil
+	}
+	return header.(http.Header).Clone()
+}
+
+// HasHTTPHeaders retrieves HTTP response headers for an error, such as the Allow header of a 405 response.
+// The headers should be retrieved with [HTTPHeaders].
+type HasHTTPHeaders interface {
+	GetHTTPHeaders() http.Header
+}
+
+// HTTPHeaders gives the HTTP response headers for an error.
+// These are the headers of its code (see SetHTTPHeaders)
+// and the headers of every layer that implements [HasHTTPHeaders], including the members of groups.
+// When the same header is given more than once, an outer layer replaces an inner layer and a layer replaces the code.
+// A layer of the error replaces a member of a group, and an earlier member replaces a later one.
+// The Retry-After header is not included: it is given by RetryAfter and RetryAt.
+// Returns nil if there are no headers.
+func HTTPHeaders(err error) http.Header {
+	var header http.Header
+	if errCode := CodeChain(err); errCode != nil {
+		header = CodeHTTPHeaders(errCode.Code())
+	}
+	var layers []http.Header
+	walkDeep(err, func(err error) bool {
+		if hasHeaders, ok := err.(HasHTTPHeaders); ok {
+			layers = append(layers, hasHeaders.GetHTTPHeaders())
+		}
+		return false
+	})
+	for i := len(layers) - 1; i >= 0; i-- {
+		for key, values := range layers[i] {
+			if header == nil {
+				header = make(http.Header)
+			}
+			header[key] = append([]string(nil), values...)
+		}
+	}
+	return header
+}
+
+// HTTPHeadersErrCode is an ErrorCode with HTTP response headers attached.
+// It is constructed by [WithHTTPHeaders].
+type HTTPHeadersErrCode struct {
+	Header http.Header
+	Err    ErrorCode
+}
+
+// WithHTTPHeaders attaches HTTP response headers to an ErrorCode.
+// Returns nil if err is nil.
+//
+//	errcode.WithHTTPHeaders(http.Header{"Allow": {"GET, HEAD"}}, err)
+func WithHTTPHeaders(header http.Header, err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	return HTTPHeadersErrCode{Header: header, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e HTTPHeadersErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e HTTPHeadersErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// GetHTTPHeaders satisfies the [HasHTTPHeaders] interface.
+func (e HTTPHeadersErrCode) GetHTTPHeaders() http.Header {
+	return e.Header
+}
+
+// Code returns the underlying Code of Err.
+func (e HTTPHeadersErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e HTTPHeadersErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*HTTPHeadersErrCode)(nil)      // assert implements interface
+var _ HasHTTPHeaders = (*HTTPHeadersErrCode)(nil) // assert implements interface
+var _ unwrapError = (*HTTPHeadersErrCode)(nil)    // assert implements interface
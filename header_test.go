@@ -0,0 +1,34 @@
+package errcode_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestHTTPHeaders(t *testing.T) {
+	methodCode := errcode.InvalidInputCode.Child("input.headers").SetHTTP(http.StatusMethodNotAllowed).SetHTTPHeaders(http.Header{"Allow": {"GET"}, "Cache-Control": {"no-cache"}})
+	childCode := methodCode.Child("input.headers.child")
+	if header := errcode.CodeHTTPHeaders(childCode); header.Get("Allow") != "GET" {
+		t.Errorf("expected the headers to be inherited, got %v", header)
+	}
+
+	err := errcode.WithHTTPHeaders(http.Header{"Allow": {"GET, HEAD"}},
+		errcode.Op("items").AddTo(errcode.WithHTTPHeaders(http.Header{"Allow": {"GET, POST"}, "Vary": {"Origin"}}, errcode.NewCodedError(errors.New("no put"), childCode))))
+	header := errcode.HTTPHeaders(errors.Wrap(err, "put"))
+	if header.Get("Allow") != "GET, HEAD" || header.Get("Vary") != "Origin" || header.Get("Cache-Control") != "no-cache" {
+		t.Errorf("unexpected headers %v", header)
+	}
+	if errcode.CodeHTTPHeaders(childCode).Get("Vary") != "" {
+		t.Error("expected the code headers to be unchanged")
+	}
+	group := errcode.Combine(errcode.NewNotFoundErr(errors.New("no item")), err)
+	if header := errcode.HTTPHeaders(group); header.Get("Allow") != "GET, HEAD" || header.Get("Vary") != "Origin" {
+		t.Errorf("expected the headers of the group member, got %v", header)
+	}
+	if header := errcode.HTTPHeaders(errcode.NewNotFoundErr(errors.New("no item"))); header != nil {
+		t.Errorf("expected no headers, got %v", header)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpclient decodes error responses from other services into ErrorCodes.
+// This is the client side of the httperr package:
+// the code, message, and data of the remote error are propagated.
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+)
+
+// MaxErrorBodySize is the most that is read from the body of an error response.
+const MaxErrorBodySize = 1 << 20
+
+// ResponseError is an error response from another service.
+// It is the transport error of the decoded ErrorCode.
+type ResponseError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Error gives the status.
+func (e ResponseError) Error() string {
+	return fmt.Sprintf("HTTP %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// ProblemDetails is an RFC 9457 (formerly RFC 7807) problem details response.
+// Code is the extension member used for the code of the error.
+type ProblemDetails struct {
+	Type     string          `json:"type,omitempty"`
+	Title    string          `json:"title,omitempty"`
+	Status   int             `json:"status,omitempty"`
+	Detail   string          `json:"detail,omitempty"`
+	Instance string          `json:"instance,omitempty"`
+	Code     errcode.CodeStr `json:"code,omitempty"`
+}
+
+// DecodeResponse gives an ErrorCode for a response with a non-2xx status.
+// A 2xx response gives nil and its body is not read.
+//
+// A problem details body, identified by its media type or its type, title, or detail members,
+// is returned as an errcode.RemoteErr:
+// its code is the code extension member, or else the code from errcode.FromHTTPStatus.
+// Otherwise the body is decoded as a JSONFormat, as written by the httperr package, and returned as an errcode.RemoteErr.
+// Any other body is given a code with errcode.FromHTTPStatus and marked with errcode.WithRemote.
+// A Retry-After header is retained (see errcode.RetryAfter).
+//
+// The body is read and replaced so that the caller can still read it and must still close it.
+func DecodeResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxErrorBodySize))
+	if err != nil {
+		return errcode.WithRemote(errcode.FromHTTPStatus(resp.StatusCode, err))
+	}
+	resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), resp.Body))
+
+	responseErr := ResponseError{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+	errCode := decodeBody(responseErr)
+	if retryAfter, ok := httperr.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && retryAfter > 0 {
+		return errcode.WithRetryAfter(retryAfter, errCode)
+	}
+	return errCode
+}
+
+// decodeBody checks for problem details first:
+// a problem details body can have a code extension member, so it would also decode as a JSONFormat.
+func decodeBody(responseErr ResponseError) errcode.ErrorCode {
+	var problem ProblemDetails
+	if err := json.Unmarshal(responseErr.Body, &problem); err == nil && isProblem(responseErr.Header, problem) {
+		code := problem.Code
+		if code == "" {
+			code = errcode.FromHTTPStatus(responseErr.StatusCode, responseErr).Code().CodeStr()
+		}
+		msg := problem.Detail
+		if msg == "" {
+			msg = problem.Title
+		}
+		return errcode.NewRemoteErr(errcode.JSONFormat{Code: code, Msg: msg, Data: problem}, responseErr)
+	}
+	var format errcode.JSONFormat
+	if err := json.Unmarshal(responseErr.Body, &format); err == nil && format.Code != "" {
+		return errcode.NewRemoteErr(format, responseErr)
+	}
+	return errcode.WithRemote(errcode.FromHTTPStatus(responseErr.StatusCode, responseErr))
+}
+
+// isProblem checks for the problem details media type or the members that a JSONFormat does not have.
+func isProblem(header http.Header, problem ProblemDetails) bool {
+	if mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && mediaType == "application/problem+json" {
+		return true
+	}
+	return problem.Type != "" || problem.Title != "" || problem.Detail != ""
+}
+
+// Do sends a request with the client and decodes an error response with DecodeResponse.
+// The body of an error response is closed.
+// A transport error is returned unchanged except that context errors are given codes with errcode.FromContextError.
+func Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		if errCode := errcode.FromContextError(err); errCode != nil {
+			return nil, errCode
+		}
+		return nil, err
+	}
+	if err := DecodeResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
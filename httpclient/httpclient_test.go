@@ -0,0 +1,81 @@
+package httpclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httpclient"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/limited":
+			httperr.Write(w, errcode.NewRateLimitErr(errors.New("slow down"), time.Minute))
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusConflict)
+			_, _ = io.WriteString(w, `{"type":"about:blank","title":"Conflict","detail":"version mismatch"}`)
+		case "/problem-code":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"title":"Not Found","detail":"no order","code":"missing"}`)
+		case "/plain":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, "down")
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer server.Close()
+
+	get := func(path string) error {
+		req, err := http.NewRequest("GET", server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := httpclient.Do(server.Client(), req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	err := get("/limited")
+	errCode := errcode.CodeChain(err)
+	if errCode == nil || errCode.Code().CodeStr() != errcode.TooManyRequestsCode.CodeStr() || err.Error() != "slow down" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if retryAfter := errcode.RetryAfter(err); retryAfter != time.Minute {
+		t.Errorf("expected the retry after to be retained, got %v", retryAfter)
+	}
+	var responseErr httpclient.ResponseError
+	if !errors.As(err, &responseErr) || responseErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the response error, got %v", err)
+	}
+
+	err = get("/problem")
+	if !errcode.IsConflict(err) || err.Error() != "version mismatch" {
+		t.Errorf("unexpected error %v", err)
+	}
+	err = get("/problem-code")
+	if !errcode.IsNotFound(err) || err.Error() != "no order" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if problem, ok := errcode.CodeChain(err).(errcode.RemoteErr).Format.Data.(httpclient.ProblemDetails); !ok || problem.Title != "Not Found" {
+		t.Errorf("expected the problem details as the data, got %v", err)
+	}
+	err = get("/plain")
+	if !errcode.IsUnavailable(err) || err.Error() != "HTTP 503 Service Unavailable" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := get("/ok"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
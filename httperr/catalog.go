@@ -0,0 +1,77 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httperr
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gregwebs/errcode"
+)
+
+// CodeDetail is the response of the catalog handler for a single code.
+// Sample is an example of an error response with the code.
+type CodeDetail struct {
+	errcode.CodeDoc
+	Sample errcode.JSONFormat `json:"sample"`
+}
+
+// CatalogHandler serves the documentation of the codes in a registry as JSON.
+// It is mounted at a prefix, for example /errors:
+//
+//   - GET /errors lists the CodeDoc of every code
+//   - GET /errors/{codestr} gives the CodeDetail of a code
+//
+// A CodeDoc without a DocURL is given the URL of its detail route.
+// Use the errcode.DocLinks format option with the same URL
+// so that error responses link back to the running service.
+//
+//	http.Handle("/errors/", httperr.CatalogHandler(errcode.DefaultRegistry(), "/errors"))
+func CatalogHandler(registry *errcode.Registry, prefix string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			err := fmt.Errorf("method %s is not allowed", r.Method)
+			return errcode.NewMethodNotAllowedErr(err, r.Method, http.MethodGet, http.MethodHead)
+		}
+		path := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		if path == "" {
+			codes := registry.Codes()
+			docs := make([]errcode.CodeDoc, len(codes))
+			for i, code := range codes {
+				docs[i] = catalogDoc(code, prefix)
+			}
+			WriteJSON(w, http.StatusOK, docs)
+			return nil
+		}
+		code, ok := registry.Find(errcode.CodeStr(path))
+		if !ok {
+			return errcode.NewNotFoundErr(fmt.Errorf("no such code %s", path))
+		}
+		WriteJSON(w, http.StatusOK, CodeDetail{
+			CodeDoc: catalogDoc(code, prefix),
+			Sample:  errcode.SampleJSONFormat(code),
+		})
+		return nil
+	})
+}
+
+func catalogDoc(code errcode.Code, prefix string) errcode.CodeDoc {
+	doc := errcode.NewCodeDoc(code)
+	if doc.DocURL == "" {
+		doc.DocURL = prefix + "/" + code.CodeStr().String()
+	}
+	return doc
+}
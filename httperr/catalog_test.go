@@ -0,0 +1,56 @@
+package httperr_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+)
+
+func TestCatalogHandler(t *testing.T) {
+	registry := errcode.NewRegistry()
+	registry.Register(errcode.NotFoundCode, errcode.GoneCode)
+	mux := http.NewServeMux()
+	mux.Handle("/errors/", httperr.CatalogHandler(registry, "/errors"))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/errors/", nil))
+	var docs []errcode.CodeDoc
+	if err := json.NewDecoder(rec.Body).Decode(&docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[1].DocURL != "/errors/missing.gone" {
+		t.Errorf("unexpected docs %v", docs)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/errors/missing.gone", nil))
+	var detail httperr.CodeDetail
+	if err := json.NewDecoder(rec.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.Code != "missing.gone" || detail.Parent != "missing" || detail.HTTP != 410 || detail.Sample.Code != "missing.gone" {
+		t.Errorf("unexpected detail %+v", detail)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/errors/nope", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/errors/", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("expected 405 allowing GET and HEAD, got %d %v", rec.Code, rec.Header())
+	}
+
+	format := errcode.NewJSONFormat(errcode.NewGoneErr(errors.New("deleted")), errcode.DocLinks("https://api.example.com/errors/"))
+	if format.Doc != "https://api.example.com/errors/missing.gone" {
+		t.Errorf("unexpected doc link %s", format.Doc)
+	}
+}
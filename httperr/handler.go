@@ -0,0 +1,172 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gregwebs/errcode"
+)
+
+type config struct {
+	onError                func(*http.Request, errcode.ErrorCode)
+	recorder               errcode.Recorder
+	formatOptions          []errcode.FormatOption
+	detectClientDisconnect bool
+	requestIDHeader        string
+	recoverPanics          bool
+}
+
+// Option configures NewHandler.
+type Option func(*config)
+
+func newConfig(opts []Option) config {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// OnError sets a function that is called with every error before it is written.
+// This is where errors are logged and recorded in metrics.
+// Use Code.ExcludedFromErrorRate to leave codes such as ClientCanceledCode out of error rates.
+func OnError(onError func(*http.Request, errcode.ErrorCode)) Option {
+	return func(c *config) {
+		c.onError = onError
+	}
+}
+
+// RecordTo stores every error in a Recorder before it is written,
+// instead of the Recorder set with errcode.SetRecorder.
+// The Source of the ErrorOccurrence is the request method and path.
+// Use an errcode.RingRecorder with RecentErrorsHandler for a simple error dashboard.
+func RecordTo(recorder errcode.Recorder) Option {
+	return func(c *config) {
+		c.recorder = recorder
+	}
+}
+
+// WithFormat sets the options given to NewJSONFormat, for example errcode.RequireUserMsg.
+func WithFormat(opts ...errcode.FormatOption) Option {
+	return func(c *config) {
+		c.formatOptions = append(c.formatOptions, opts...)
+	}
+}
+
+// DetectClientDisconnect gives ClientCanceledCode to an error when the client has disconnected.
+// A disconnect is detected by the request context being canceled.
+// Only an error without a code or with an internal or canceled code is changed:
+// these are normally caused by the canceled context.
+// Without this option, these errors would be reported as internal errors.
+func DetectClientDisconnect() Option {
+	return func(c *config) {
+		c.detectClientDisconnect = true
+	}
+}
+
+// RequestIDHeader gives errors the request ID from a request header, such as "X-Request-Id".
+// A request ID in the request context (see errcode.CtxWithRequestID) is preferred over the header.
+// An error that already has a request ID keeps it.
+// The request ID is set as the same header of the response and as the request_id of the JSON body,
+// so that clients can quote it in support tickets.
+//
+// Without this option, only the request ID in the request context is attached.
+func RequestIDHeader(header string) Option {
+	return func(c *config) {
+		c.requestIDHeader = header
+	}
+}
+
+// RecoverPanics responds to a panic in the handler with an internal error from errcode.RecoverToErrorCode.
+// The error is given to OnError and RecordTo, and its stack trace starts at the panic.
+// A panic with http.ErrAbortHandler is not recovered: it aborts the response as usual.
+func RecoverPanics() Option {
+	return func(c *config) {
+		c.recoverPanics = true
+	}
+}
+
+// NewHandler creates an http.Handler from a HandlerFunc.
+// A returned error is resolved with ErrorCode and written with Write, as with HandlerFunc.ServeHTTP.
+// This includes responding with errcode.NewDrainingErr while draining.
+// The options add behavior around the error.
+func NewHandler(fn HandlerFunc, opts ...Option) http.Handler {
+	c := newConfig(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if errcode.IsDraining() {
+			c.write(w, r, errcode.NewDrainingErr())
+			return
+		}
+		if c.recoverPanics {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					if recovered == http.ErrAbortHandler {
+						panic(recovered)
+					}
+					c.write(w, r, errcode.RecoverToErrorCode(recovered))
+				}
+			}()
+		}
+		if err := fn(w, r); err != nil {
+			c.write(w, r, err)
+		}
+	})
+}
+
+func (c config) write(w http.ResponseWriter, r *http.Request, err error) {
+	errCode := ErrorCode(err)
+	if c.detectClientDisconnect && clientDisconnected(r, errCode) {
+		// NewCodedError would keep the existing code
+		errCode = errcode.CodedError{GetCode: errcode.ClientCanceledCode, Err: errCode}
+	}
+	if errcode.RequestID(errCode) == "" {
+		if id := c.requestID(r); id != "" {
+			errCode = errcode.WithRequestID(id, errCode)
+		}
+	}
+	if c.requestIDHeader != "" {
+		if id := errcode.RequestID(errCode); id != "" {
+			w.Header().Set(c.requestIDHeader, id)
+		}
+	}
+	if c.onError != nil {
+		c.onError(r, errCode)
+	}
+	if c.recorder != nil {
+		c.recorder.Store(errcode.NewErrorOccurrence(errCode, requestSource(r)))
+	} else {
+		errcode.Record(errCode, requestSource(r))
+	}
+	writeErrorCode(w, errCode, c.formatOptions)
+}
+
+func (c config) requestID(r *http.Request) string {
+	if id := errcode.RequestIDFromCtx(r.Context()); id != "" {
+		return id
+	}
+	if c.requestIDHeader != "" {
+		return r.Header.Get(c.requestIDHeader)
+	}
+	return ""
+}
+
+func clientDisconnected(r *http.Request, errCode errcode.ErrorCode) bool {
+	if !errors.Is(r.Context().Err(), context.Canceled) {
+		return false
+	}
+	return errcode.IsInternal(errCode) || errcode.IsCanceled(errCode)
+}
@@ -0,0 +1,131 @@
+package httperr_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+)
+
+func TestDetectClientDisconnect(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		code errcode.Code
+	}{
+		{errors.Wrap(context.Canceled, "query"), errcode.ClientCanceledCode},
+		{errors.New("connection closed"), errcode.ClientCanceledCode},
+		{errcode.NewNotFoundErr(errors.New("no item")), errcode.NotFoundCode},
+	} {
+		var reported errcode.ErrorCode
+		handler := httperr.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+			return test.err
+		}, httperr.DetectClientDisconnect(), httperr.OnError(func(r *http.Request, errCode errcode.ErrorCode) {
+			reported = errCode
+		}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+		if reported.Code().CodeStr() != test.code.CodeStr() {
+			t.Errorf("expected %v, got %v", test.code, reported.Code())
+		}
+		if rec.Code != test.code.HTTPCode() {
+			t.Errorf("expected %d, got %d", test.code.HTTPCode(), rec.Code)
+		}
+	}
+	if !errcode.ClientCanceledCode.ExcludedFromErrorRate() || errcode.CanceledCode.ExcludedFromErrorRate() {
+		t.Error("expected only client cancellation to be excluded from error rates")
+	}
+}
+
+func TestNewHandlerWithoutDisconnect(t *testing.T) {
+	handler := httperr.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("uncoded")
+	}, httperr.WithFormat(errcode.RequireUserMsg()))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	if rec.Code != 500 {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	defer errcode.SetDraining(false)
+	errcode.SetDraining(true)
+	called := false
+	for _, handler := range []http.Handler{
+		httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { called = true; return nil }),
+		httperr.NewHandler(func(w http.ResponseWriter, r *http.Request) error { called = true; return nil }),
+		httperr.Drain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })),
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "5" {
+			t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+		}
+	}
+	if called {
+		t.Error("expected the handlers not to be called while draining")
+	}
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	handler := httperr.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+		return errcode.NewNotFoundErr(errors.New("no item"))
+	}, httperr.RequestIDHeader("X-Request-Id"))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if id := rec.Header().Get("X-Request-Id"); id != "req-1" {
+		t.Errorf("expected the response header req-1, got %s", id)
+	}
+	var body errcode.JSONFormat
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.RequestID != "req-1" {
+		t.Errorf("expected the request_id req-1, got %s", body.RequestID)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(errcode.CtxWithRequestID(req.Context(), "ctx-1")))
+	if id := rec.Header().Get("X-Request-Id"); id != "ctx-1" {
+		t.Errorf("expected the context request ID, got %s", id)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	var reported errcode.ErrorCode
+	handler := httperr.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}, httperr.RecoverPanics(), httperr.OnError(func(r *http.Request, errCode errcode.ErrorCode) {
+		reported = errCode
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+	if panicErr, ok := reported.(errcode.PanicErr); !ok || panicErr.Value != "boom" {
+		t.Errorf("expected a PanicErr, got %#v", reported)
+	}
+
+	abort := httperr.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	}, httperr.RecoverPanics())
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Error("expected http.ErrAbortHandler to be panicked again")
+		}
+	}()
+	abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -0,0 +1,171 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httperr writes errors as HTTP responses using error codes.
+// The ErrorCode of an error is resolved with CodeChain.
+// The HTTP status comes from the code's HTTP metadata and the body is the JSONFormat.
+// It is the basis for the web framework adapters in the webfw module.
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gregwebs/errcode"
+)
+
+// ErrorCode resolves the ErrorCode of an error with CodeChain.
+// An error without an ErrorCode is converted with NewInternalErr.
+// Returns nil for a nil error.
+func ErrorCode(err error) errcode.ErrorCode {
+	if err == nil {
+		return nil
+	}
+	if errCode := errcode.CodeChain(err); errCode != nil {
+		return errCode
+	}
+	return errcode.NewInternalErr(err)
+}
+
+// Response gives the HTTP status and the JSON body for an error.
+// The options are given to NewJSONFormat.
+func Response(err error, opts ...errcode.FormatOption) (int, errcode.JSONFormat) {
+	errCode := ErrorCode(err)
+	return errCode.Code().HTTPCode(), errcode.NewJSONFormat(errCode, opts...)
+}
+
+// Write writes the error as a JSON response.
+// The status and body are from Response and the headers are from SetHeaders.
+// The error is stored with errcode.Record, without a Source.
+func Write(w http.ResponseWriter, err error, opts ...errcode.FormatOption) {
+	errCode := ErrorCode(err)
+	errcode.Record(errCode, "")
+	writeErrorCode(w, errCode, opts)
+}
+
+// Record stores the error with errcode.Record in the Recorder set with errcode.SetRecorder.
+// The Source of the ErrorOccurrence is the request method and path.
+// The handlers of this package already do this.
+// Web framework adapters use this to record the same errors as the handlers.
+func Record(r *http.Request, err error) {
+	errcode.Record(ErrorCode(err), requestSource(r))
+}
+
+func requestSource(r *http.Request) string {
+	return r.Method + " " + r.URL.Path
+}
+
+// writeRequest writes the error as Write does but records the request as the Source.
+func writeRequest(w http.ResponseWriter, r *http.Request, err error) {
+	errCode := ErrorCode(err)
+	errcode.Record(errCode, requestSource(r))
+	writeErrorCode(w, errCode, nil)
+}
+
+// writeErrorCode serializes with errcode.WriteJSON so that the cost is observed.
+// The body is buffered so that a serialization error can still be given an internal error response.
+func writeErrorCode(w http.ResponseWriter, errCode errcode.ErrorCode, opts []errcode.FormatOption) {
+	SetHeaders(w.Header(), errCode)
+	var body bytes.Buffer
+	if err := errcode.WriteJSON(&body, errCode, opts...); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, errcode.NewJSONFormat(errcode.NewInternalErr(err), opts...))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(errCode.Code().HTTPCode())
+	_, _ = w.Write(body.Bytes())
+}
+
+// SetHeaders sets the response headers for an error.
+// The headers from errcode.HTTPHeaders are set, such as a WWW-Authenticate header for a 401.
+// The Retry-After header is set as an HTTP-date from errcode.RetryAt
+// or else as delta-seconds from errcode.RetryAfter.
+// Web framework adapters use this to set the same headers as Write.
+func SetHeaders(header http.Header, err error) {
+	for key, values := range errcode.HTTPHeaders(err) {
+		header[key] = values
+	}
+	if retryAt := errcode.RetryAt(err); !retryAt.IsZero() {
+		header.Set("Retry-After", retryAt.UTC().Format(http.TimeFormat))
+	} else if retryAfter := errcode.RetryAfter(err); retryAfter > 0 {
+		header.Set("Retry-After", strconv.FormatInt(errcode.RetryAfterSeconds(retryAfter), 10))
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header value as either delta-seconds or an HTTP-date.
+// It gives the delay from now; an HTTP-date that has passed gives zero.
+// False is returned if the value is empty or cannot be parsed.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := at.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// WriteJSON writes a JSON response with the given status.
+func WriteJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// HandlerFunc is an HTTP handler that can return an error.
+// A returned error is written with Write.
+//
+//	http.Handle("/item", httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+//		return errcode.NewNotFoundErr(errors.New("no item"))
+//	}))
+type HandlerFunc func(http.ResponseWriter, *http.Request) error
+
+// ServeHTTP satisfies the http.Handler interface
+// While draining (see errcode.SetDraining) the error from errcode.NewDrainingErr is written instead of calling the handler.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if errcode.IsDraining() {
+		writeRequest(w, r, errcode.NewDrainingErr())
+		return
+	}
+	if err := f(w, r); err != nil {
+		writeRequest(w, r, err)
+	}
+}
+
+// Drain responds with the error from errcode.NewDrainingErr while draining (see errcode.SetDraining).
+// Otherwise the request is given to the next handler.
+// HandlerFunc and NewHandler already do this.
+func Drain(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if errcode.IsDraining() {
+			writeRequest(w, r, errcode.NewDrainingErr())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+var _ http.Handler = HandlerFunc(nil) // assert implements interface
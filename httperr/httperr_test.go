@@ -0,0 +1,120 @@
+package httperr_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+)
+
+func TestHandlerFunc(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		status int
+		code   errcode.CodeStr
+		msg    string
+	}{
+		{errcode.NewNotFoundErr(errors.New("no item")), 404, "missing", "no item"},
+		{errors.Wrap(errcode.NewForbiddenErr(errors.New("no access")), "get"), 403, "auth.forbidden", "get: no access"},
+		{errors.New("uncoded"), 500, "internal", "uncoded"},
+	} {
+		handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return test.err
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != test.status {
+			t.Errorf("expected status %d, got %d", test.status, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("unexpected content type %s", ct)
+		}
+		var body errcode.JSONFormat
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Code != test.code || body.Msg != test.msg {
+			t.Errorf("unexpected body %v", body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected no content, got %d", rec.Code)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.Wrap(errcode.NewRateLimitErr(errors.New("slow down"), 30*time.Second), "list")
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 429 || rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestHTTPHeaders(t *testing.T) {
+	unauthorized := errcode.NotAuthenticatedCode.Child("auth.unauthenticated.headers").SetHTTPHeaders(http.Header{"Www-Authenticate": {`Bearer realm="api"`}})
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errcode.WithHTTPHeaders(http.Header{"Cache-Control": {"no-store"}}, errcode.NewCodedError(errors.New("no token"), unauthorized))
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 401 || rec.Header().Get("WWW-Authenticate") != `Bearer realm="api"` || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestRetryAtHeader(t *testing.T) {
+	maintenanceEnd := time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("EST", -5*60*60))
+	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errcode.WithRetryAt(maintenanceEnd, errcode.NewUnavailableErr(errors.New("maintenance")))
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	header := rec.Header().Get("Retry-After")
+	if rec.Code != 503 || header != "Wed, 02 Jan 2030 08:04:05 GMT" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+
+	now := maintenanceEnd.Add(-time.Minute)
+	if delay, ok := httperr.ParseRetryAfter(header, now); !ok || delay != time.Minute {
+		t.Errorf("unexpected delay %v", delay)
+	}
+	if delay, ok := httperr.ParseRetryAfter("30", now); !ok || delay != 30*time.Second {
+		t.Errorf("unexpected delay %v", delay)
+	}
+	if delay, ok := httperr.ParseRetryAfter(header, maintenanceEnd.Add(time.Hour)); !ok || delay != 0 {
+		t.Errorf("expected a passed date to give zero, got %v", delay)
+	}
+	if _, ok := httperr.ParseRetryAfter("soon", now); ok {
+		t.Error("expected an invalid value not to parse")
+	}
+}
+
+func TestWriteSerializationError(t *testing.T) {
+	unserializable := errcode.WithClientData(make(chan int), errcode.NewNotFoundErr(errors.New("no item")))
+	rec := httptest.NewRecorder()
+	httperr.Write(rec, unserializable, errcode.RequireUserMsg())
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected an internal error, got %d", rec.Code)
+	}
+	var body errcode.JSONFormat
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "internal" || body.Msg != "Something went wrong" {
+		t.Errorf("expected the serialization error to be hidden, got %v", body)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httperr
+
+import (
+	"net/http"
+
+	"github.com/gregwebs/errcode"
+)
+
+// RecentErrors is the response of RecentErrorsHandler.
+type RecentErrors struct {
+	Counts map[errcode.CodeStr]int   `json:"counts"`
+	Recent []errcode.ErrorOccurrence `json:"recent"`
+}
+
+// RecentErrorsHandler serves the errors kept by a RingRecorder as JSON.
+// The recent errors can be limited to codes and their descendants with the code query parameter:
+//
+//	GET /admin/errors?code=missing&code=state.conflict
+//
+// This exposes internal error messages and should only be mounted on an admin route.
+func RecentErrorsHandler(recorder *errcode.RingRecorder) http.Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var codes []errcode.CodeStr
+		for _, code := range r.URL.Query()["code"] {
+			codes = append(codes, errcode.CodeStr(code))
+		}
+		WriteJSON(w, http.StatusOK, RecentErrors{
+			Counts: recorder.Counts(),
+			Recent: recorder.Recent(codes...),
+		})
+		return nil
+	})
+}
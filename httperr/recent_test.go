@@ -0,0 +1,58 @@
+package httperr_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+)
+
+func TestRecentErrorsHandler(t *testing.T) {
+	recorder := errcode.NewRingRecorder(10)
+	handler := httperr.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/missing" {
+			return errcode.NewNotFoundErr(errors.New("no item"))
+		}
+		return errcode.NewConflictErr(errors.New("version mismatch"))
+	}, httperr.RecordTo(recorder))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/item", nil))
+
+	rec := httptest.NewRecorder()
+	httperr.RecentErrorsHandler(recorder).ServeHTTP(rec, httptest.NewRequest("GET", "/admin/errors?code=missing", nil))
+	var recent httperr.RecentErrors
+	if err := json.NewDecoder(rec.Body).Decode(&recent); err != nil {
+		t.Fatal(err)
+	}
+	if len(recent.Recent) != 1 || recent.Recent[0].Source != "GET /missing" || recent.Recent[0].Msg != "no item" {
+		t.Errorf("unexpected recent errors %v", recent.Recent)
+	}
+	if recent.Counts["missing"] != 1 || recent.Counts["state.conflict"] != 1 {
+		t.Errorf("unexpected counts %v", recent.Counts)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	recorder := errcode.NewRingRecorder(10)
+	errcode.SetRecorder(recorder)
+	defer errcode.SetRecorder(nil)
+
+	httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errcode.NewNotFoundErr(errors.New("no item"))
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/item", nil))
+	httperr.Write(httptest.NewRecorder(), errors.New("db down"))
+	httperr.Record(httptest.NewRequest("PUT", "/item", nil), errcode.NewConflictErr(errors.New("version mismatch")))
+
+	var sources []string
+	for _, occurrence := range recorder.Recent() {
+		sources = append(sources, string(occurrence.Code)+" "+occurrence.Source)
+	}
+	if expected := []string{"state.conflict PUT /item", "internal ", "missing GET /item"}; !reflect.DeepEqual(sources, expected) {
+		t.Errorf("expected %q, got %q", expected, sources)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httperr
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gregwebs/errcode"
+)
+
+// Routes responds with coded errors when the ServeMux has no route for a request,
+// instead of the plain text responses of the ServeMux:
+// a NotFoundCode error for an unknown path
+// and a MethodNotAllowedErr with the Allow header for a method that the path does not support.
+// Other requests, including the redirects of the ServeMux, are served by the ServeMux.
+// The options are the options of NewHandler.
+func Routes(mux *http.ServeMux, opts ...Option) http.Handler {
+	c := newConfig(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		// Run the error handler of the ServeMux to find out which error it is
+		rec := &routeRecorder{header: make(http.Header)}
+		handler.ServeHTTP(rec, r)
+		switch rec.status {
+		case http.StatusNotFound:
+			c.write(w, r, errcode.NewNotFoundErr(fmt.Errorf("no route for %s", r.URL.Path)))
+		case http.StatusMethodNotAllowed:
+			err := fmt.Errorf("method %s is not allowed for %s", r.Method, r.URL.Path)
+			c.write(w, r, errcode.NewMethodNotAllowedErr(err, r.Method, splitAllow(rec.header.Get("Allow"))...))
+		default:
+			rec.replay(w)
+		}
+	})
+}
+
+// AllowMethods responds with a MethodNotAllowedErr when the method of the request is not one of the methods.
+// This gives a route of a router without method matching the Allow header and the error of Routes.
+// Otherwise the request is given to the next handler.
+func AllowMethods(next http.Handler, methods ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, method := range methods {
+			if r.Method == method {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		err := fmt.Errorf("method %s is not allowed for %s", r.Method, r.URL.Path)
+		writeRequest(w, r, errcode.NewMethodNotAllowedErr(err, r.Method, methods...))
+	})
+}
+
+// NotImplemented responds with a NotImplementedRouteErr.
+// This is a placeholder for a route that is declared but not implemented yet.
+func NotImplemented() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeRequest(w, r, errcode.NewNotImplementedRouteErr(fmt.Errorf("%s %s is not implemented", r.Method, r.URL.Path)))
+	})
+}
+
+func splitAllow(allow string) []string {
+	if allow == "" {
+		return nil
+	}
+	methods := strings.Split(allow, ",")
+	for i, method := range methods {
+		methods[i] = strings.TrimSpace(method)
+	}
+	return methods
+}
+
+// routeRecorder records the response of the error handler of a ServeMux.
+type routeRecorder struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func (rec *routeRecorder) Header() http.Header { return rec.header }
+
+func (rec *routeRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *routeRecorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	rec.body = append(rec.body, b...)
+	return len(b), nil
+}
+
+func (rec *routeRecorder) replay(w http.ResponseWriter) {
+	for key, values := range rec.header {
+		w.Header()[key] = values
+	}
+	if rec.status != 0 {
+		w.WriteHeader(rec.status)
+	}
+	_, _ = w.Write(rec.body)
+}
@@ -0,0 +1,75 @@
+//go:debug httpmuxgo121=0
+
+package httperr_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+)
+
+func TestRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("item"))
+	})
+	mux.HandleFunc("POST /items/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("/later", httperr.NotImplemented())
+	var recorded []errcode.ErrorCode
+	handler := httperr.Routes(mux, httperr.OnError(func(_ *http.Request, errCode errcode.ErrorCode) {
+		recorded = append(recorded, errCode)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/items/7", nil))
+	if rec.Code != 200 || rec.Body.String() != "item" {
+		t.Errorf("expected the route to be served, got %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("DELETE", "/items/7", nil))
+	var body errcode.JSONFormat
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != 405 || rec.Header().Get("Allow") != "GET, HEAD, POST" || body.Code != errcode.MethodNotAllowedCode.CodeStr() {
+		t.Errorf("unexpected response %d %v %s", rec.Code, rec.Header(), rec.Body)
+	}
+	data, _ := body.Data.(map[string]interface{})
+	if data["method"] != "DELETE" || len(data["allow"].([]interface{})) != 3 {
+		t.Errorf("unexpected data %v", body.Data)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/nothing", nil))
+	if rec.Code != 404 || rec.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+	if len(recorded) != 2 {
+		t.Errorf("expected the route errors to be given to OnError, got %v", recorded)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/later", nil))
+	if rec.Code != 501 || !json.Valid(rec.Body.Bytes()) {
+		t.Errorf("unexpected response %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestAllowMethods(t *testing.T) {
+	handler := httperr.AllowMethods(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "GET", "HEAD")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Errorf("expected GET to be allowed, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("PUT", "/", nil))
+	if rec.Code != 405 || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+}
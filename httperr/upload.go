@@ -0,0 +1,78 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gregwebs/errcode"
+)
+
+// ClassifyUpload is an errcode.Classifier for errors from reading a request body or a multipart form:
+//
+//   - *http.MaxBytesError and multipart.ErrMessageTooLarge give a PayloadTooLargeErr
+//   - http.ErrNotMultipart gives an UnsupportedMediaTypeErr
+//   - http.ErrMissingFile, http.ErrMissingBoundary, and mime.ErrInvalidMediaParameter give an invalid input error
+//
+// Register it once to classify these errors everywhere:
+//
+//	errcode.RegisterClassifier(httperr.ClassifyUpload)
+func ClassifyUpload(err error) (errcode.ErrorCode, bool) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return errcode.NewPayloadTooLargeErr(err, maxBytesErr.Limit, 0), true
+	case errors.Is(err, multipart.ErrMessageTooLarge):
+		return errcode.NewPayloadTooLargeErr(err, 0, 0), true
+	case errors.Is(err, http.ErrNotMultipart):
+		return errcode.NewUnsupportedMediaTypeErr(err, "", "multipart/form-data"), true
+	case errors.Is(err, http.ErrMissingFile),
+		errors.Is(err, http.ErrMissingBoundary),
+		errors.Is(err, mime.ErrInvalidMediaParameter):
+		return errcode.NewInvalidInputErr(err), true
+	}
+	return nil, false
+}
+
+// CheckContentType gives an UnsupportedMediaTypeErr if the media type of the request Content-Type
+// is not one of the supported media types.
+// Parameters such as charset are ignored.
+// Returns nil if the media type is supported.
+func CheckContentType(r *http.Request, supported ...string) errcode.ErrorCode {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		for _, s := range supported {
+			if mediaType == s {
+				return nil
+			}
+		}
+		err = fmt.Errorf("unsupported media type %q", mediaType)
+	}
+	return errcode.NewUnsupportedMediaTypeErr(err, contentType, supported...)
+}
+
+// CheckFileSize gives a PayloadTooLargeErr if an uploaded file is larger than the limit.
+// Returns nil if the file is within the limit.
+func CheckFileSize(header *multipart.FileHeader, limit int64) errcode.ErrorCode {
+	if header.Size <= limit {
+		return nil
+	}
+	err := fmt.Errorf("file %s is %d bytes which is over the limit of %d", header.Filename, header.Size, limit)
+	return errcode.NewPayloadTooLargeErr(err, limit, header.Size)
+}
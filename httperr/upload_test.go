@@ -0,0 +1,72 @@
+package httperr_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+)
+
+func TestClassifyUpload(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("upload", "big.txt")
+	_, _ = part.Write(bytes.Repeat([]byte("x"), 100))
+	_ = writer.Close()
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Body = http.MaxBytesReader(rec, req.Body, 10)
+	err := req.ParseMultipartForm(1024)
+	errCode, ok := httperr.ClassifyUpload(err)
+	if !ok {
+		t.Fatalf("expected %v to be classified", err)
+	}
+	status, format := httperr.Response(errCode)
+	if status != 413 || format.Data != (errcode.PayloadTooLargeData{Limit: 10}) {
+		t.Errorf("unexpected response %d %+v", status, format)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	errCode, ok = httperr.ClassifyUpload(req.ParseMultipartForm(1024))
+	if !ok || errCode.Code().HTTPCode() != 415 {
+		t.Errorf("expected unsupported media type, got %v", errCode)
+	}
+
+	if _, ok := httperr.ClassifyUpload(errcode.NewNotFoundErr(http.ErrBodyNotAllowed)); ok {
+		t.Error("expected an unrelated error not to be classified")
+	}
+}
+
+func TestCheckContentType(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if errCode := httperr.CheckContentType(req, "application/json"); errCode != nil {
+		t.Errorf("expected no error, got %v", errCode)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	errCode := httperr.CheckContentType(req, "application/json")
+	expected := errcode.UnsupportedMediaTypeData{ContentType: "text/plain", Supported: []string{"application/json"}}
+	data, ok := errcode.ClientData(errCode).(errcode.UnsupportedMediaTypeData)
+	if !ok || data.ContentType != expected.ContentType || data.Supported[0] != expected.Supported[0] {
+		t.Errorf("expected %v, got %v", expected, errcode.ClientData(errCode))
+	}
+}
+
+func TestCheckFileSize(t *testing.T) {
+	header := &multipart.FileHeader{Filename: "a.png", Size: 20}
+	if errCode := httperr.CheckFileSize(header, 20); errCode != nil {
+		t.Errorf("expected no error, got %v", errCode)
+	}
+	errCode := httperr.CheckFileSize(header, 10)
+	if data := errcode.ClientData(errCode); data != (errcode.PayloadTooLargeData{Limit: 10, Received: 20}) {
+		t.Errorf("unexpected data %v", data)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/gregwebs/errcode/i18n
+
+go 1.21.9
+
+require (
+	github.com/gregwebs/errcode v0.11.0
+	github.com/gregwebs/errors v1.5.0
+	golang.org/x/text v0.14.0
+)
+
+replace github.com/gregwebs/errcode => ../
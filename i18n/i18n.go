@@ -0,0 +1,152 @@
+// Package i18n localizes the user messages of error codes.
+//
+// Messages are stored in a Catalog keyed by CodeStr and language tag.
+// A message registered for a code is inherited by its descendant codes.
+// The language to use is matched from the supported languages of the catalog
+// with golang.org/x/text/language.
+//
+//	catalog := i18n.NewCatalog(language.English)
+//	catalog.Set(language.English, errcode.NotFoundCode, "not found")
+//	catalog.Set(language.French, errcode.NotFoundCode, "introuvable")
+//	catalog.JSONFormat(errCode, r.Header.Get("Accept-Language"))
+package i18n
+
+import (
+	"sync"
+
+	"github.com/gregwebs/errcode"
+	"golang.org/x/text/language"
+)
+
+// Translator resolves a user message for a code.
+// The requested languages are given in order of preference.
+// The boolean return is false when there is no message.
+type Translator interface {
+	Translate(code errcode.Code, requested ...language.Tag) (string, bool)
+}
+
+// Catalog is a Translator that stores messages per code and language.
+// It is safe for concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	fallback language.Tag
+	tags     []language.Tag
+	matcher  language.Matcher
+	messages map[language.Tag]map[errcode.CodeStr]string
+}
+
+var _ Translator = (*Catalog)(nil) // assert implements interface
+
+// NewCatalog creates an empty Catalog.
+// The fallback language is used when no supported language matches the requested languages.
+func NewCatalog(fallback language.Tag) *Catalog {
+	return &Catalog{
+		fallback: fallback,
+		tags:     []language.Tag{fallback},
+		messages: make(map[language.Tag]map[errcode.CodeStr]string),
+	}
+}
+
+// DefaultCatalog is used by LocalizedJSONFormat.
+var DefaultCatalog = NewCatalog(language.English)
+
+// Set adds the message for a code in the given language.
+// Descendant codes without their own message use this message.
+func (c *Catalog) Set(tag language.Tag, code errcode.Code, msg string) {
+	c.SetMessages(tag, map[errcode.CodeStr]string{code.CodeStr(): msg})
+}
+
+// SetMessages adds messages for multiple codes in the given language.
+func (c *Catalog) SetMessages(tag language.Tag, messages map[errcode.CodeStr]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	existing, ok := c.messages[tag]
+	if !ok {
+		existing = make(map[errcode.CodeStr]string, len(messages))
+		c.messages[tag] = existing
+		if tag != c.fallback {
+			c.tags = append(c.tags, tag)
+		}
+		c.matcher = nil
+	}
+	for codeStr, msg := range messages {
+		existing[codeStr] = msg
+	}
+}
+
+// Match gives the supported language that best matches the requested languages.
+// If none match, the fallback language is given.
+func (c *Catalog) Match(requested ...language.Tag) language.Tag {
+	c.mu.Lock()
+	if c.matcher == nil {
+		c.matcher = language.NewMatcher(c.tags)
+	}
+	matcher := c.matcher
+	tags := c.tags
+	c.mu.Unlock()
+
+	_, index, confidence := matcher.Match(requested...)
+	if confidence == language.No {
+		return c.fallback
+	}
+	return tags[index]
+}
+
+// Translate satisfies the Translator interface.
+// The requested languages are matched against the supported languages.
+// If the code has no message, the messages of its ancestors are used.
+// If the matched language has no message, the fallback language is tried.
+func (c *Catalog) Translate(code errcode.Code, requested ...language.Tag) (string, bool) {
+	matched := c.Match(requested...)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if msg, ok := lookup(c.messages[matched], code); ok {
+		return msg, true
+	}
+	if matched != c.fallback {
+		return lookup(c.messages[c.fallback], code)
+	}
+	return "", false
+}
+
+func lookup(messages map[errcode.CodeStr]string, code errcode.Code) (string, bool) {
+	if messages == nil {
+		return "", false
+	}
+	for {
+		if msg, ok := messages[code.CodeStr()]; ok {
+			return msg, true
+		}
+		if code.Parent == nil {
+			return "", false
+		}
+		code = *code.Parent
+	}
+}
+
+// JSONFormat creates an errcode.JSONFormat with the user messages localized by the Catalog.
+// See LocalizeJSONFormat.
+func (c *Catalog) JSONFormat(errCode errcode.ErrorCode, lang string, opts ...errcode.FormatOption) errcode.JSONFormat {
+	return LocalizeJSONFormat(c, errCode, lang, opts...)
+}
+
+// LocalizedJSONFormat uses DefaultCatalog to create an errcode.JSONFormat with localized user messages.
+func LocalizedJSONFormat(errCode errcode.ErrorCode, lang string, opts ...errcode.FormatOption) errcode.JSONFormat {
+	return DefaultCatalog.JSONFormat(errCode, lang, opts...)
+}
+
+// LocalizeJSONFormat creates an errcode.JSONFormat with the user messages resolved by the Translator.
+// lang is parsed as an Accept-Language header value, so it can be a single language tag or a weighted list.
+// The messages of Others and of the items of a BatchError are localized as well, each from its own code.
+// When there is no localized message, the message from errcode.NewJSONFormat is kept.
+// The options are given to errcode.NewJSONFormat.
+func LocalizeJSONFormat(translator Translator, errCode errcode.ErrorCode, lang string, opts ...errcode.FormatOption) errcode.JSONFormat {
+	tags, _, err := language.ParseAcceptLanguage(lang)
+	if err != nil {
+		tags = nil
+	}
+	translate := errcode.TranslateUserMsg(func(code errcode.Code) (string, bool) {
+		return translator.Translate(code, tags...)
+	})
+	return errcode.NewJSONFormat(errCode, append(opts, translate)...)
+}
@@ -0,0 +1,86 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/i18n"
+	"github.com/gregwebs/errors"
+	"golang.org/x/text/language"
+)
+
+func TestLocalizeJSONFormat(t *testing.T) {
+	catalog := i18n.NewCatalog(language.English)
+	catalog.Set(language.English, errcode.NotFoundCode, "not found")
+	catalog.Set(language.French, errcode.NotFoundCode, "introuvable")
+	catalog.Set(language.French, errcode.InvalidInputCode, "entrée invalide")
+
+	notFound := errcode.NewNotFoundErr(errors.New("missing row"))
+	for _, test := range []struct {
+		lang string
+		msg  string
+	}{
+		{"fr", "introuvable"},
+		{"fr-CH, en;q=0.5", "introuvable"},
+		{"en-US", "not found"},
+		{"de", "not found"},
+		{"", "not found"},
+		{"not a language!", "not found"},
+	} {
+		if msg := catalog.JSONFormat(notFound, test.lang).Msg; msg != test.msg {
+			t.Errorf("lang %s: expected %s, got %s", test.lang, test.msg, msg)
+		}
+	}
+
+	// the fallback language does not have the message: keep the original
+	invalid := errcode.NewInvalidInputErr(errors.New("bad input"))
+	if msg := catalog.JSONFormat(invalid, "en").Msg; msg != "bad input" {
+		t.Errorf("expected original message, got %s", msg)
+	}
+
+	// descendant codes inherit and Others are localized
+	combined := errcode.Combine(errcode.NewAlreadyExistsErr(errors.New("exists")), notFound)
+	format := catalog.JSONFormat(combined, "fr")
+	if format.Msg != "exists; missing row" {
+		t.Errorf("expected original message, got %s", format.Msg)
+	}
+	if len(format.Others) != 1 || format.Others[0].Msg != "introuvable" {
+		t.Errorf("expected localized others, got %v", format.Others)
+	}
+}
+
+func TestLocalizeJSONFormatOthers(t *testing.T) {
+	catalog := i18n.NewCatalog(language.English)
+	catalog.Set(language.French, errcode.NotFoundCode, "introuvable")
+	catalog.Set(language.French, errcode.InvalidInputCode, "entrée invalide")
+	catalog.Set(language.French, errcode.AlreadyExistsCode, "existe déjà")
+
+	batch := errcode.NewBatchError(nil)
+	batch.Add("a", errcode.NewNotFoundErr(errors.New("no item a")))
+	grouped := errcode.Combine(
+		errcode.NewAlreadyExistsErr(errors.New("exists")),
+		errcode.Op("items.save")(batch),
+		errcode.NewAlreadyExistsErr(errors.New("exists again")),
+		errcode.NewInvalidInputErr(errors.New("bad input")),
+	)
+	format := catalog.JSONFormat(grouped, "fr", errcode.DedupeOthers())
+	if format.Msg != "existe déjà" {
+		t.Errorf("unexpected message %s", format.Msg)
+	}
+	for _, other := range format.Others {
+		expected := map[errcode.CodeStr]string{
+			errcode.NotFoundCode.CodeStr():     "introuvable",
+			errcode.InvalidInputCode.CodeStr(): "entrée invalide",
+		}[other.Code]
+		if other.Msg != expected {
+			t.Errorf("expected %q for %s, got %q", expected, other.Code, other.Msg)
+		}
+	}
+	if len(format.Others) != 2 {
+		t.Errorf("expected the batch and the invalid input as others, got %v", format.Others)
+	}
+	items := format.Others[0].Data.(map[string]errcode.JSONFormat)
+	if items["a"].Msg != "introuvable" {
+		t.Errorf("expected the batch item to be localized, got %v", items)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package interop translates the error codes of third-party services into codes.
+// A Mapping is a table from the codes of a vendor to codes.
+// There are ready-made mappings for AWS error codes, Google API canonical codes, and Stripe error types.
+// Teams can create their own mappings with NewMapping and extend the ready-made ones with Set.
+//
+// A registered Mapping with an Extract function is also an errcode Classifier,
+// so that CodeChain gives the vendor error a code:
+//
+//	interop.Register(interop.AWS)
+//	errcode.CodeChain(err) // NotFoundCode for a NoSuchKey error from the AWS SDK
+//
+// A code that is not from an error, for example from a JSON response, is translated with Translate.
+package interop
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gregwebs/errcode"
+)
+
+// Mapping translates the codes of a vendor to codes.
+// It is safe for concurrent use.
+type Mapping struct {
+	// Name identifies the vendor for Lookup and Translate.
+	Name string
+	// Extract gives the vendor code of an error. It may be nil when the codes do not come from errors.
+	Extract func(error) (string, bool)
+
+	mu    sync.RWMutex
+	codes map[string]errcode.Code
+}
+
+// NewMapping creates a Mapping from a table of vendor codes.
+// The extract function may be nil.
+func NewMapping(name string, extract func(error) (string, bool), codes map[string]errcode.Code) *Mapping {
+	m := &Mapping{Name: name, Extract: extract, codes: make(map[string]errcode.Code, len(codes))}
+	for vendorCode, code := range codes {
+		m.codes[vendorCode] = code
+	}
+	return m
+}
+
+// Set maps a vendor code to a code, replacing an existing mapping.
+// Returns itself.
+func (m *Mapping) Set(vendorCode string, code errcode.Code) *Mapping {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes[vendorCode] = code
+	return m
+}
+
+// Code gives the code for a vendor code.
+func (m *Mapping) Code(vendorCode string) (errcode.Code, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	code, ok := m.codes[vendorCode]
+	return code, ok
+}
+
+// Classify gives the error the code of its vendor code as found by Extract.
+// It satisfies the errcode.Classifier signature.
+func (m *Mapping) Classify(err error) (errcode.ErrorCode, bool) {
+	if m.Extract == nil {
+		return nil, false
+	}
+	vendorCode, ok := m.Extract(err)
+	if !ok {
+		return nil, false
+	}
+	code, ok := m.Code(vendorCode)
+	if !ok {
+		return nil, false
+	}
+	return errcode.NewCodedError(err, code), true
+}
+
+var (
+	mappingsMu sync.RWMutex
+	mappings   = make(map[string]*Mapping)
+)
+
+// Register makes the mappings available to Lookup and Translate.
+// A Mapping with an Extract function is registered as an errcode Classifier.
+// Panic if a Mapping with the same Name is already registered.
+// This is normally done once during program initialization.
+func Register(ms ...*Mapping) {
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	for _, m := range ms {
+		if _, ok := mappings[m.Name]; ok {
+			panic(fmt.Sprintf("interop: the mapping %s is already registered", m.Name))
+		}
+		mappings[m.Name] = m
+		if m.Extract != nil {
+			errcode.RegisterClassifier(m.Classify)
+		}
+	}
+}
+
+// Lookup gives a registered Mapping by its Name.
+func Lookup(name string) (*Mapping, bool) {
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+	m, ok := mappings[name]
+	return m, ok
+}
+
+// Translate gives an ErrorCode for the error with the code that the registered Mapping of the vendor gives for the vendor code.
+// Returns nil if the vendor is not registered or the vendor code is not mapped.
+//
+//	if errCode := interop.Translate("stripe", body.Error.Type, err); errCode != nil {
+//		return errCode
+//	}
+func Translate(vendor string, vendorCode string, err error) errcode.ErrorCode {
+	m, ok := Lookup(vendor)
+	if !ok {
+		return nil
+	}
+	code, ok := m.Code(vendorCode)
+	if !ok {
+		return nil
+	}
+	return errcode.NewCodedError(err, code)
+}
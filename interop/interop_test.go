@@ -0,0 +1,73 @@
+package interop_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/interop"
+)
+
+type awsError struct{ code string }
+
+func (e awsError) Error() string     { return e.code + ": the request failed" }
+func (e awsError) ErrorCode() string { return e.code }
+
+func TestAWSClassifier(t *testing.T) {
+	interop.Register(interop.AWS)
+	err := fmt.Errorf("get object: %w", awsError{"NoSuchKey"})
+	errCode := errcode.CodeChain(err)
+	if errCode == nil || errCode.Code().CodeStr() != errcode.NotFoundCode.CodeStr() {
+		t.Fatalf("expected a not found code, got %v", errCode)
+	}
+	if classified, ok := interop.AWS.Classify(err); !ok || !errors.Is(classified, err) {
+		t.Errorf("expected the vendor error to be wrapped, got %v", classified)
+	}
+
+	if errCode, ok := interop.AWS.Classify(awsError{"UnknownException"}); ok {
+		t.Errorf("expected an unmapped code to not be classified, got %v", errCode)
+	}
+	if _, ok := interop.AWS.Classify(errors.New("plain")); ok {
+		t.Errorf("expected an error without a vendor code to not be classified")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	interop.Register(interop.Google, interop.Stripe)
+	err := errors.New("card declined")
+	if errCode := interop.Translate("stripe", "card_error", err); errCode == nil || errCode.Code().CodeStr() != errcode.PaymentRequiredCode.CodeStr() {
+		t.Errorf("expected a payment required code, got %v", errCode)
+	}
+	if errCode := interop.Translate("google", "RESOURCE_EXHAUSTED", err); errCode == nil || errCode.Code().CodeStr() != errcode.TooManyRequestsCode.CodeStr() {
+		t.Errorf("expected a too many requests code, got %v", errCode)
+	}
+	if errCode := interop.Translate("google", "NOPE", err); errCode != nil {
+		t.Errorf("expected an unmapped code to give nil, got %v", errCode)
+	}
+	if errCode := interop.Translate("azure", "NotFound", err); errCode != nil {
+		t.Errorf("expected an unregistered vendor to give nil, got %v", errCode)
+	}
+}
+
+func TestCustomMapping(t *testing.T) {
+	quotaCode := errcode.TooManyRequestsCode.Child("ratelimit.interop_quota")
+	mapping := interop.NewMapping("acme", nil, map[string]errcode.Code{"E404": errcode.NotFoundCode})
+	mapping.Set("E429", quotaCode)
+	interop.Register(mapping)
+
+	found, ok := interop.Lookup("acme")
+	if !ok || found != mapping {
+		t.Fatalf("expected the registered mapping, got %v", found)
+	}
+	if code, ok := found.Code("E429"); !ok || code.CodeStr() != quotaCode.CodeStr() {
+		t.Errorf("expected the quota code, got %v", code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected registering a duplicate name to panic")
+		}
+	}()
+	interop.Register(interop.NewMapping("acme", nil, nil))
+}
@@ -0,0 +1,106 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interop
+
+import (
+	"errors"
+
+	"github.com/gregwebs/errcode"
+)
+
+// AWS maps the error codes of AWS services.
+// Its Extract function finds an error with an ErrorCode() string method, as the APIError of the AWS SDK for Go v2 has,
+// or with a Code() string method, as the awserr.Error of v1 has.
+var AWS = NewMapping("aws", awsErrorCode, map[string]errcode.Code{
+	"AccessDenied":                    errcode.ForbiddenCode,
+	"AccessDeniedException":           errcode.ForbiddenCode,
+	"UnauthorizedOperation":           errcode.ForbiddenCode,
+	"UnrecognizedClientException":     errcode.NotAuthenticatedCode,
+	"InvalidClientTokenId":            errcode.NotAuthenticatedCode,
+	"ExpiredToken":                    errcode.NotAuthenticatedCode,
+	"ExpiredTokenException":           errcode.NotAuthenticatedCode,
+	"NotFound":                        errcode.NotFoundCode,
+	"NoSuchKey":                       errcode.NotFoundCode,
+	"NoSuchBucket":                    errcode.NotFoundCode,
+	"ResourceNotFoundException":       errcode.NotFoundCode,
+	"ValidationError":                 errcode.InvalidInputCode,
+	"ValidationException":             errcode.InvalidInputCode,
+	"InvalidParameterValue":           errcode.InvalidInputCode,
+	"InvalidParameterException":       errcode.InvalidInputCode,
+	"MissingParameter":                errcode.InvalidInputCode,
+	"EntityTooLarge":                  errcode.PayloadTooLargeCode,
+	"BucketAlreadyExists":             errcode.AlreadyExistsCode,
+	"ResourceAlreadyExistsException":  errcode.AlreadyExistsCode,
+	"ConflictException":               errcode.ConflictCode,
+	"ResourceInUseException":          errcode.ConflictCode,
+	"ConditionalCheckFailedException": errcode.PreconditionFailedCode,
+	"PreconditionFailed":              errcode.PreconditionFailedCode,
+	"Throttling":                      errcode.TooManyRequestsCode,
+	"ThrottlingException":             errcode.TooManyRequestsCode,
+	"TooManyRequestsException":        errcode.TooManyRequestsCode,
+	"RequestLimitExceeded":            errcode.TooManyRequestsCode,
+	"SlowDown":                        errcode.TooManyRequestsCode,
+	"RequestTimeout":                  errcode.TimeoutRequestCode,
+	"RequestTimeoutException":         errcode.TimeoutRequestCode,
+	"ServiceUnavailable":              errcode.UnavailableCode,
+	"ServiceUnavailableException":     errcode.UnavailableCode,
+	"InternalError":                   errcode.InternalCode,
+	"InternalFailure":                 errcode.InternalCode,
+	"InternalServerError":             errcode.InternalCode,
+	"NotImplemented":                  errcode.UnimplementedCode,
+})
+
+func awsErrorCode(err error) (string, bool) {
+	var v2 interface{ ErrorCode() string }
+	if errors.As(err, &v2) {
+		return v2.ErrorCode(), true
+	}
+	var v1 interface{ Code() string }
+	if errors.As(err, &v1) {
+		return v1.Code(), true
+	}
+	return "", false
+}
+
+// Google maps the canonical codes of Google APIs, as given by the status field of a JSON error response.
+// These are the names of the GRPC codes: for errors with a GRPC status use the grpc package of errcode instead.
+var Google = NewMapping("google", nil, map[string]errcode.Code{
+	"CANCELLED":           errcode.CanceledCode,
+	"UNKNOWN":             errcode.InternalCode,
+	"INVALID_ARGUMENT":    errcode.InvalidInputCode,
+	"DEADLINE_EXCEEDED":   errcode.DeadlineExceededCode,
+	"NOT_FOUND":           errcode.NotFoundCode,
+	"ALREADY_EXISTS":      errcode.AlreadyExistsCode,
+	"PERMISSION_DENIED":   errcode.ForbiddenCode,
+	"RESOURCE_EXHAUSTED":  errcode.TooManyRequestsCode,
+	"FAILED_PRECONDITION": errcode.PreconditionFailedCode,
+	"ABORTED":             errcode.ConflictCode,
+	"OUT_OF_RANGE":        errcode.OutOfRangeCode,
+	"UNIMPLEMENTED":       errcode.UnimplementedCode,
+	"INTERNAL":            errcode.InternalCode,
+	"UNAVAILABLE":         errcode.UnavailableCode,
+	"DATA_LOSS":           errcode.InternalCode,
+	"UNAUTHENTICATED":     errcode.NotAuthenticatedCode,
+})
+
+// Stripe maps Stripe-style error types, as given by the type field of a JSON error response.
+var Stripe = NewMapping("stripe", nil, map[string]errcode.Code{
+	"api_error":             errcode.InternalCode,
+	"api_connection_error":  errcode.UnavailableCode,
+	"authentication_error":  errcode.NotAuthenticatedCode,
+	"card_error":            errcode.PaymentRequiredCode,
+	"idempotency_error":     errcode.ConflictCode,
+	"invalid_request_error": errcode.InvalidInputCode,
+	"rate_limit_error":      errcode.TooManyRequestsCode,
+})
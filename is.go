@@ -0,0 +1,61 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+// CodeTarget is an error target for matching codes with errors.Is.
+// It is constructed with CodeIs.
+//
+// The ErrorCode types of this package match a CodeTarget with their code or an ancestor of it.
+// An ErrorCode defined elsewhere can support this by embedding CodedError or by implementing Is with Matches.
+//
+// The ErrorCode types of this package also match a target that is an ErrorCode with exactly the same code.
+// This allows comparing with a sentinel error:
+//
+//	var ErrNoItem = errcode.NewNotFoundErr(errors.New("no item"))
+//	errors.Is(err, ErrNoItem) // true for any error with NotFoundCode, but not a descendant
+//
+// Only a CodeTarget matches the descendants of its code.
+type CodeTarget struct {
+	Code Code
+}
+
+// CodeIs gives a target for errors.Is that matches the code and its descendants:
+//
+//	errors.Is(err, errcode.CodeIs(errcode.NotFoundCode))
+func CodeIs(code Code) CodeTarget {
+	return CodeTarget{Code: code}
+}
+
+// Error satisfies the error interface so that a CodeTarget can be given to errors.Is.
+func (t CodeTarget) Error() string {
+	return "errcode target " + t.Code.CodeStr().String()
+}
+
+// Matches checks if the code is the target code or a descendant of it.
+func (t CodeTarget) Matches(code Code) bool {
+	return code.IsAncestor(t.Code)
+}
+
+// isCodeTarget implements the Is method of the ErrorCode types.
+// A target that is an ErrorCode, such as a sentinel error, is matched when it has the same CodeStr.
+// Unlike a CodeTarget, it does not match a descendant code.
+func isCodeTarget(code Code, target error) bool {
+	switch t := target.(type) {
+	case CodeTarget:
+		return t.Matches(code)
+	case ErrorCode:
+		return code.CodeStr() == t.Code().CodeStr()
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package errcode_test
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestCodeIs(t *testing.T) {
+	err := errors.Wrap(errcode.Op("fetch")(errcode.NewGoneErr(errors.New("deleted"))), "get item")
+	if !stderrors.Is(err, errcode.CodeIs(errcode.GoneCode)) || !stderrors.Is(err, errcode.CodeIs(errcode.NotFoundCode)) {
+		t.Error("expected the code and its ancestor to match")
+	}
+	if stderrors.Is(err, errcode.CodeIs(errcode.InternalCode)) {
+		t.Error("expected another code not to match")
+	}
+	if stderrors.Is(errcode.NewNotFoundErr(errors.New("missing")), errcode.CodeIs(errcode.GoneCode)) {
+		t.Error("expected a descendant target not to match")
+	}
+
+	group := errcode.Combine(errcode.NewInvalidInputErr(errors.New("bad")), errcode.NewInternalErr(errors.New("oops")))
+	if !stderrors.Is(group, errcode.CodeIs(errcode.InternalCode)) {
+		t.Error("expected a group member to match")
+	}
+	if stderrors.Is(errors.New("plain"), errcode.CodeIs(errcode.InternalCode)) {
+		t.Error("expected an error without a code not to match")
+	}
+}
+
+var errNoItem = errcode.NewNotFoundErr(errors.New("no item"))
+
+func TestIsSentinel(t *testing.T) {
+	err := errors.Wrap(errcode.WithUserMsg("Missing", errcode.NewNotFoundErr(errors.New("no row"))), "get item")
+	if !stderrors.Is(err, errNoItem) {
+		t.Error("expected the same code to match the sentinel")
+	}
+	gone := errors.Wrap(errcode.WithUserMsg("Gone", errcode.NewGoneErr(errors.New("deleted"))), "get item")
+	if stderrors.Is(gone, errNoItem) {
+		t.Error("expected a descendant code not to match the sentinel")
+	}
+	if !stderrors.Is(gone, errcode.CodeIs(errcode.NotFoundCode)) {
+		t.Error("expected a descendant code to match CodeIs")
+	}
+	if stderrors.Is(errNoItem, errcode.NewGoneErr(errors.New("deleted"))) {
+		t.Error("expected an ancestor code not to match")
+	}
+	if stderrors.Is(errcode.NewInvalidInputErr(errors.New("bad")), errNoItem) {
+		t.Error("expected another code not to match")
+	}
+	group := errcode.Combine(errcode.NewInvalidInputErr(errors.New("bad")), errcode.NewNotFoundErr(errors.New("missing")))
+	if !stderrors.Is(group, errNoItem) {
+		t.Error("expected a group member to match the sentinel")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonrpc converts between ErrorCodes and JSON-RPC 2.0 error objects.
+//
+// The numeric JSON-RPC code of a code is set with SetCode.
+// The JSONFormat of an ErrorCode is sent as the "data" member of the error object.
+// This allows a client to reconstruct the ErrorCode with its data.
+//
+// The init function performs the mapping and is reproduced here:
+//
+//	SetCode(errcode.InternalCode, CodeInternalError)
+//	SetCode(errcode.InvalidInputCode, CodeInvalidParams)
+//	SetCode(errcode.UnimplementedCode, CodeMethodNotFound)
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/gregwebs/errcode"
+	pkgerrors "github.com/gregwebs/errors"
+)
+
+// The error codes defined by the JSON-RPC 2.0 specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is the start of the range reserved for implementation-defined server errors.
+	// It is used for a code without a JSON-RPC code.
+	CodeServerError = -32000
+)
+
+var jsonRPCMetaData = make(errcode.MetaData)
+
+var (
+	fromJSONRPCMu sync.RWMutex
+	fromJSONRPC   = make(map[int]errcode.Code)
+)
+
+// SetCode adds a JSON-RPC code to the meta data of a code.
+// The code can be retrieved with GetCode.
+// The first code set for a JSON-RPC code is used when converting an error object without data to an ErrorCode.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func SetCode(code errcode.Code, jsonRPCCode int) errcode.Code {
+	if err := code.SetMetaData(jsonRPCMetaData, jsonRPCCode); err != nil {
+		panic(pkgerrors.Wrap(err, "SetJSONRPC"))
+	}
+	fromJSONRPCMu.Lock()
+	defer fromJSONRPCMu.Unlock()
+	if _, ok := fromJSONRPC[jsonRPCCode]; !ok {
+		fromJSONRPC[jsonRPCCode] = code
+	}
+	return code
+}
+
+// WithCode gives a function that sets the JSON-RPC code with SetCode.
+// This is used in the With field of an errcode.CodeSpec.
+func WithCode(jsonRPCCode int) func(errcode.Code) errcode.Code {
+	return func(code errcode.Code) errcode.Code {
+		return SetCode(code, jsonRPCCode)
+	}
+}
+
+// GetCode retrieves the JSON-RPC code for a code or its first ancestor with a JSON-RPC code.
+// If none are specified, it defaults to CodeServerError.
+func GetCode(code errcode.Code) int {
+	jsonRPCCode := code.MetaDataFromAncestors(jsonRPCMetaData)
+	if jsonRPCCode == nil {
+		return CodeServerError
+	}
+	return jsonRPCCode.(int)
+}
+
+// Error is a JSON-RPC 2.0 error object.
+// Data is the JSONFormat of the error when it is created by ToJSONRPCError,
+// but it can be any JSON in an error object from another server.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error gives the JSON-RPC code and message.
+func (e *Error) Error() string {
+	return "jsonrpc error " + strconv.Itoa(e.Code) + ": " + e.Message
+}
+
+// ToJSONRPCError converts an error to a JSON-RPC error object.
+// The ErrorCode of the error is resolved with CodeChain.
+// An error without an ErrorCode is converted with NewInternalErr.
+// The message is the user message of the JSONFormat and the data is the JSONFormat.
+// The options are given to NewJSONFormat.
+// Returns nil for a nil error.
+func ToJSONRPCError(err error, opts ...errcode.FormatOption) *Error {
+	if err == nil {
+		return nil
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		errCode = errcode.NewInternalErr(err)
+	}
+	format := errcode.NewJSONFormat(errCode, opts...)
+	rpcErr := &Error{Code: GetCode(errCode.Code()), Message: format.Msg}
+	if data, jsonErr := errcode.MarshalJSONFormat(format); jsonErr == nil {
+		rpcErr.Data = data
+	}
+	return rpcErr
+}
+
+// FromJSONRPCError converts an error with a JSON-RPC error object (found with errors.As) to an ErrorCode.
+// If the data is a JSONFormat, an errcode.RemoteErr is returned.
+// Otherwise the JSON-RPC code is mapped back to a code set with SetCode and marked with errcode.WithRemote.
+// An error that is not a JSON-RPC error object is returned unchanged.
+func FromJSONRPCError(err error) error {
+	var rpcErr *Error
+	if err == nil || !errors.As(err, &rpcErr) {
+		return err
+	}
+	if format, ok := DataJSONFormat(rpcErr); ok {
+		return errcode.NewRemoteErr(format, err)
+	}
+	fromJSONRPCMu.RLock()
+	code, ok := fromJSONRPC[rpcErr.Code]
+	fromJSONRPCMu.RUnlock()
+	if !ok {
+		return err
+	}
+	return errcode.WithRemote(errcode.NewCodedError(err, code))
+}
+
+// DataJSONFormat decodes the data of a JSON-RPC error object as a JSONFormat.
+func DataJSONFormat(rpcErr *Error) (errcode.JSONFormat, bool) {
+	var format errcode.JSONFormat
+	if len(rpcErr.Data) == 0 {
+		return format, false
+	}
+	if err := json.Unmarshal(rpcErr.Data, &format); err != nil || format.Code == "" {
+		return format, false
+	}
+	return format, true
+}
+
+func init() {
+	SetCode(errcode.InternalCode, CodeInternalError)
+	SetCode(errcode.InvalidInputCode, CodeInvalidParams)
+	SetCode(errcode.UnimplementedCode, CodeMethodNotFound)
+	errcode.RegisterDocMapping("jsonrpc", func(code errcode.Code) string {
+		// a code without a mapping is left out rather than documented with the default
+		if code.MetaDataFromAncestors(jsonRPCMetaData) == nil {
+			return ""
+		}
+		return strconv.Itoa(GetCode(code))
+	})
+}
@@ -0,0 +1,65 @@
+package jsonrpc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/jsonrpc"
+	"github.com/gregwebs/errors"
+)
+
+func TestRoundTrip(t *testing.T) {
+	err := errcode.Op("items.get").AddTo(errcode.NewInvalidInputErr(errors.New("bad id")))
+	rpcErr := jsonrpc.ToJSONRPCError(err)
+	if rpcErr.Code != jsonrpc.CodeInvalidParams || rpcErr.Message != "items.get: bad id" {
+		t.Errorf("unexpected error object %+v", rpcErr)
+	}
+	body, jsonErr := json.Marshal(rpcErr)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	var received jsonrpc.Error
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatal(err)
+	}
+	errCode := errcode.CodeChain(jsonrpc.FromJSONRPCError(&received))
+	if errCode == nil || errCode.Code().CodeStr() != errcode.InvalidInputCode.CodeStr() {
+		t.Fatalf("expected the invalid input code, got %v", errCode)
+	}
+	if op := errcode.Operation(errCode); op != "items.get" {
+		t.Errorf("expected operation items.get, got %v", op)
+	}
+
+	if rpcErr := jsonrpc.ToJSONRPCError(errcode.NewNotFoundErr(errors.New("no item"))); rpcErr.Code != jsonrpc.CodeServerError {
+		t.Errorf("expected a server error for an unmapped code, got %d", rpcErr.Code)
+	}
+	if jsonrpc.ToJSONRPCError(nil) != nil {
+		t.Error("expected nil")
+	}
+}
+
+func TestDocMapping(t *testing.T) {
+	if mapped := errcode.NewCodeDoc(errcode.InvalidInputCode).Mappings["jsonrpc"]; mapped != "-32602" {
+		t.Errorf("expected the jsonrpc doc mapping, got %s", mapped)
+	}
+	if mapped, ok := errcode.NewCodeDoc(errcode.NotFoundCode).Mappings["jsonrpc"]; ok {
+		t.Errorf("expected no doc mapping for an unmapped code, got %s", mapped)
+	}
+}
+
+func TestFromJSONRPCError(t *testing.T) {
+	err := jsonrpc.FromJSONRPCError(&jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: "no method", Data: json.RawMessage(`"details"`)})
+	errCode, ok := err.(errcode.ErrorCode)
+	if !ok || errCode.Code() != errcode.UnimplementedCode {
+		t.Fatalf("expected the unimplemented code, got %v", err)
+	}
+	unknown := &jsonrpc.Error{Code: -1, Message: "custom"}
+	if jsonrpc.FromJSONRPCError(unknown) != error(unknown) {
+		t.Error("expected an unmapped code to be unchanged")
+	}
+	plain := errors.New("plain")
+	if jsonrpc.FromJSONRPCError(plain) != plain {
+		t.Error("expected a plain error to be unchanged")
+	}
+}
@@ -0,0 +1,174 @@
+// Package k8s attaches Kubernetes status reasons to the standard error codes.
+// It converts between ErrorCodes and the StatusError of k8s.io/apimachinery,
+// so that controllers and operators use codes and K8s tooling such as apierrors.IsNotFound still works.
+//
+// The CodeStr of an ErrorCode is sent as a cause of the status with the type CauseTypeCode.
+// This allows a client to reconstruct the ErrorCode when the code is registered.
+//
+// The init function performs the mapping and is reproduced here:
+//
+//	SetReason(errcode.InternalCode, metav1.StatusReasonInternalError)
+//	SetReason(errcode.UnavailableCode, metav1.StatusReasonServiceUnavailable)
+//	SetReason(errcode.NotFoundCode, metav1.StatusReasonNotFound)
+//	SetReason(errcode.GoneCode, metav1.StatusReasonGone)
+//	SetReason(errcode.ConflictCode, metav1.StatusReasonConflict)
+//	SetReason(errcode.StateCode, metav1.StatusReasonConflict)
+//	SetReason(errcode.AlreadyExistsCode, metav1.StatusReasonAlreadyExists)
+//	SetReason(errcode.UnprocessableEntityCode, metav1.StatusReasonInvalid)
+//	SetReason(errcode.InvalidInputCode, metav1.StatusReasonBadRequest)
+//	SetReason(errcode.NotAcceptableCode, metav1.StatusReasonNotAcceptable)
+//	SetReason(errcode.MethodNotAllowedCode, metav1.StatusReasonMethodNotAllowed)
+//	SetReason(errcode.PayloadTooLargeCode, metav1.StatusReasonRequestEntityTooLarge)
+//	SetReason(errcode.UnsupportedMediaTypeCode, metav1.StatusReasonUnsupportedMediaType)
+//	SetReason(errcode.TooManyRequestsCode, metav1.StatusReasonTooManyRequests)
+//	SetReason(errcode.NotAuthenticatedCode, metav1.StatusReasonUnauthorized)
+//	SetReason(errcode.ForbiddenCode, metav1.StatusReasonForbidden)
+//	SetReason(errcode.TimeoutCode, metav1.StatusReasonTimeout)
+package k8s
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/gregwebs/errcode"
+	pkgerrors "github.com/gregwebs/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CauseTypeCode is the type of the cause of a status that holds the CodeStr of the error.
+const CauseTypeCode metav1.CauseType = "errcode"
+
+var reasonMetaData = make(errcode.MetaData)
+
+var (
+	fromReasonMu sync.RWMutex
+	fromReason   = make(map[metav1.StatusReason]errcode.Code)
+)
+
+// SetReason adds a Kubernetes status reason to the meta data of a code.
+// The reason can be retrieved with GetReason.
+// The first code set for a reason is used when converting a status without a CauseTypeCode cause to an ErrorCode.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func SetReason(code errcode.Code, reason metav1.StatusReason) errcode.Code {
+	if err := code.SetMetaData(reasonMetaData, reason); err != nil {
+		panic(pkgerrors.Wrap(err, "SetReason"))
+	}
+	fromReasonMu.Lock()
+	defer fromReasonMu.Unlock()
+	if _, ok := fromReason[reason]; !ok {
+		fromReason[reason] = code
+	}
+	return code
+}
+
+// WithReason gives a function that sets the status reason with SetReason.
+// This is used in the With field of an errcode.CodeSpec.
+func WithReason(reason metav1.StatusReason) func(errcode.Code) errcode.Code {
+	return func(code errcode.Code) errcode.Code {
+		return SetReason(code, reason)
+	}
+}
+
+// GetReason retrieves the status reason for a code or its first ancestor with a reason.
+// If none are specified, it defaults to StatusReasonUnknown.
+func GetReason(code errcode.Code) metav1.StatusReason {
+	reason := code.MetaDataFromAncestors(reasonMetaData)
+	if reason == nil {
+		return metav1.StatusReasonUnknown
+	}
+	return reason.(metav1.StatusReason)
+}
+
+// ToStatusError converts an error with an ErrorCode (found with CodeChain) to an *apierrors.StatusError.
+// The message is the user message of the JSONFormat, the status code is the HTTP code,
+// and the CodeStr is attached as a CauseTypeCode cause.
+// The StatusError does not wrap the original error: log the original error before converting it.
+// An error that already has a Kubernetes status or has no ErrorCode is returned unchanged.
+func ToStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return err
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		return err
+	}
+	format := errcode.NewJSONFormat(errCode)
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    int32(errCode.Code().HTTPCode()),
+		Reason:  GetReason(errCode.Code()),
+		Message: format.Msg,
+		Details: &metav1.StatusDetails{
+			Causes: []metav1.StatusCause{{Type: CauseTypeCode, Message: format.Code.String()}},
+		},
+	}}
+}
+
+// FromStatusError converts an error with a Kubernetes status to an ErrorCode.
+// A registered code in a CauseTypeCode cause is used first,
+// then the code set with SetReason for the reason,
+// and otherwise the code for the status code as given by errcode.FromHTTPStatus.
+// The ErrorCode is marked with errcode.WithRemote.
+// An error without a Kubernetes status is returned unchanged.
+func FromStatusError(err error) error {
+	var apiStatus apierrors.APIStatus
+	if err == nil || !errors.As(err, &apiStatus) {
+		return err
+	}
+	status := apiStatus.Status()
+	if status.Details != nil {
+		for _, cause := range status.Details.Causes {
+			if cause.Type != CauseTypeCode {
+				continue
+			}
+			if code, ok := errcode.DefaultRegistry().Find(errcode.CodeStr(cause.Message)); ok {
+				return errcode.WithRemote(errcode.NewCodedError(err, code))
+			}
+		}
+	}
+	fromReasonMu.RLock()
+	code, ok := fromReason[status.Reason]
+	fromReasonMu.RUnlock()
+	if ok {
+		return errcode.WithRemote(errcode.NewCodedError(err, code))
+	}
+	httpStatus := int(status.Code)
+	if httpStatus == 0 {
+		httpStatus = http.StatusInternalServerError
+	}
+	return errcode.WithRemote(errcode.FromHTTPStatus(httpStatus, err))
+}
+
+func init() {
+	SetReason(errcode.InternalCode, metav1.StatusReasonInternalError)
+	SetReason(errcode.UnavailableCode, metav1.StatusReasonServiceUnavailable)
+	SetReason(errcode.NotFoundCode, metav1.StatusReasonNotFound)
+	SetReason(errcode.GoneCode, metav1.StatusReasonGone)
+	SetReason(errcode.ConflictCode, metav1.StatusReasonConflict)
+	SetReason(errcode.StateCode, metav1.StatusReasonConflict)
+	SetReason(errcode.AlreadyExistsCode, metav1.StatusReasonAlreadyExists)
+	SetReason(errcode.UnprocessableEntityCode, metav1.StatusReasonInvalid)
+	SetReason(errcode.InvalidInputCode, metav1.StatusReasonBadRequest)
+	SetReason(errcode.NotAcceptableCode, metav1.StatusReasonNotAcceptable)
+	SetReason(errcode.MethodNotAllowedCode, metav1.StatusReasonMethodNotAllowed)
+	SetReason(errcode.PayloadTooLargeCode, metav1.StatusReasonRequestEntityTooLarge)
+	SetReason(errcode.UnsupportedMediaTypeCode, metav1.StatusReasonUnsupportedMediaType)
+	SetReason(errcode.TooManyRequestsCode, metav1.StatusReasonTooManyRequests)
+	SetReason(errcode.NotAuthenticatedCode, metav1.StatusReasonUnauthorized)
+	SetReason(errcode.ForbiddenCode, metav1.StatusReasonForbidden)
+	SetReason(errcode.TimeoutCode, metav1.StatusReasonTimeout)
+	errcode.RegisterDocMapping("k8s", func(code errcode.Code) string {
+		// a code without a mapping is left out rather than documented with the default
+		if code.MetaDataFromAncestors(reasonMetaData) == nil {
+			return ""
+		}
+		return string(GetReason(code))
+	})
+}
@@ -0,0 +1,69 @@
+package k8s_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	errk8s "github.com/gregwebs/errcode/k8s"
+	"github.com/gregwebs/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestToStatusError(t *testing.T) {
+	err := errk8s.ToStatusError(errcode.NewNotFoundErr(errors.New("no widget")))
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected a not found status, got %v", err)
+	}
+	status := err.(apierrors.APIStatus).Status()
+	if status.Code != 404 || len(status.Details.Causes) != 1 || status.Details.Causes[0].Message != "missing" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if !apierrors.IsTooManyRequests(errk8s.ToStatusError(errcode.NewCodedError(errors.New("slow down"), errcode.TooManyRequestsCode))) {
+		t.Error("expected a too many requests status")
+	}
+
+	plain := errors.New("plain")
+	if errk8s.ToStatusError(plain) != plain {
+		t.Error("expected an error without a code to be unchanged")
+	}
+	if errk8s.GetReason(errcode.CanceledCode) != metav1.StatusReasonUnknown {
+		t.Error("expected an unknown reason for a code without a reason")
+	}
+}
+
+func TestFromStatusError(t *testing.T) {
+	widgets := schema.GroupResource{Group: "example.com", Resource: "widgets"}
+	tests := []struct {
+		name string
+		err  error
+		code errcode.Code
+	}{
+		{"round trip", errk8s.ToStatusError(errcode.NewCodedError(errors.New("gone"), errcode.GoneCode)), errcode.GoneCode},
+		{"reason", apierrors.NewAlreadyExists(widgets, "w1"), errcode.AlreadyExistsCode},
+		{"conflict", apierrors.NewConflict(widgets, "w1", errors.New("modified")), errcode.ConflictCode},
+		{"forbidden", apierrors.NewForbidden(widgets, "w1", errors.New("rbac")), errcode.ForbiddenCode},
+		{"status code", apierrors.NewGenericServerResponse(418, "get", widgets, "w1", "", 0, false), errcode.InvalidInputCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errCode := errcode.CodeChain(errk8s.FromStatusError(tt.err))
+			if errCode == nil || errCode.Code().CodeStr() != tt.code.CodeStr() {
+				t.Errorf("expected the code %s, got %v", tt.code.CodeStr(), errCode)
+			}
+		})
+	}
+
+	plain := errors.New("plain")
+	if errk8s.FromStatusError(plain) != plain {
+		t.Error("expected an error without a status to be unchanged")
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	err := errk8s.ToStatusError(errcode.NewMethodNotAllowedErr(errors.New("no delete"), "DELETE", "GET"))
+	if !apierrors.IsMethodNotSupported(err) {
+		t.Errorf("expected a method not allowed status, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+// HasLabel retrieves a label that identifies where an error came from within a group of errors.
+// For example, the label can be the name of a subtask in a batch.
+//
+// The label should be retrieved with [Label].
+// Labels are normally attached with [CombineLabeled] which uses [LabeledErrCode].
+type HasLabel interface {
+	GetLabel() string
+}
+
+// Label will return a label string if it exists.
+// It checks recursively for the [HasLabel] interface.
+// Otherwise it will return the zero value (empty) string.
+func Label(v interface{}) string {
+	if hasLabel, ok := v.(HasLabel); ok {
+		return hasLabel.GetLabel()
+	}
+	if un, ok := v.(unwrapError); ok {
+		return Label(un.Unwrap())
+	}
+	return ""
+}
+
+// LabeledErrCode is an ErrorCode with a Label field attached.
+// It is constructed by [CombineLabeled].
+type LabeledErrCode struct {
+	Label string
+	Err   ErrorCode
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e LabeledErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error prefixes the label to the underlying Err Error.
+func (e LabeledErrCode) Error() string {
+	return e.Label + ": " + e.Err.Error()
+}
+
+// GetLabel satisfies the [HasLabel] interface.
+func (e LabeledErrCode) GetLabel() string {
+	return e.Label
+}
+
+// Code returns the underlying Code of Err.
+func (e LabeledErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e LabeledErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*LabeledErrCode)(nil)   // assert implements interface
+var _ HasLabel = (*LabeledErrCode)(nil)    // assert implements interface
+var _ unwrapError = (*LabeledErrCode)(nil) // assert implements interface
@@ -0,0 +1,12 @@
+// Command errcodelint checks the definition and use of error codes.
+// See the lint package for the checks.
+package main
+
+import (
+	"github.com/gregwebs/errcode/lint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(lint.Analyzer)
+}
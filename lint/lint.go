@@ -0,0 +1,302 @@
+// Package lint provides an analyzer that checks the definition and use of error codes.
+// The errcodelint command runs it:
+//
+//	go install github.com/gregwebs/errcode/lint/cmd/errcodelint
+//	errcodelint ./...
+//
+// It reports:
+//   - NewCode called with a dotted string: a code with a parent is created with Child
+//   - Child called with a dotted string that does not match the parent code
+//   - codes created outside of a package level variable or an init function, except in tests
+//   - the same CodeStr defined twice, in the package or in one of its dependencies
+//   - HTTP handlers that return an error without a code, which is given to clients as an internal error
+package lint
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const errcodePath = "github.com/gregwebs/errcode"
+
+// Analyzer checks the definition and use of error codes.
+var Analyzer = &analysis.Analyzer{
+	Name:      "errcodelint",
+	Doc:       "check the definition and use of error codes",
+	Run:       run,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(codeFact)},
+}
+
+// codeFact records the full CodeStr of a package level Code variable.
+type codeFact struct{ CodeStr string }
+
+func (*codeFact) AFact() {}
+
+func (f *codeFact) String() string { return "code " + f.CodeStr }
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	checker := codeChecker{pass: pass, codes: make(map[string]token.Pos)}
+	for _, fact := range pass.AllObjectFacts() {
+		if code, ok := fact.Fact.(*codeFact); ok && fact.Object.Pkg() != pass.Pkg {
+			checker.imported = append(checker.imported, importedCode{code.CodeStr, fact.Object})
+		}
+	}
+	// the facts are not ordered: report the first definition
+	sort.Slice(checker.imported, func(i, j int) bool {
+		a, b := checker.imported[i].object, checker.imported[j].object
+		if a.Pkg().Path() != b.Pkg().Path() {
+			return a.Pkg().Path() < b.Pkg().Path()
+		}
+		return a.Pos() < b.Pos()
+	})
+
+	// package level variables are visited in initialization order so that a parent is known before its children
+	for _, init := range pass.TypesInfo.InitOrder {
+		if codeStr, call, ok := checker.codeStr(init.Rhs); ok && len(init.Lhs) == 1 {
+			checker.define(init.Lhs[0], codeStr, call.Pos())
+		}
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil), (*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			fn := calledFunc(pass.TypesInfo, n)
+			if !isCodeConstructor(fn) {
+				return true
+			}
+			// the errcode package creates codes for its callers and tests create codes for themselves
+			if !atInit(stack) && pass.Pkg.Path() != errcodePath && !strings.HasSuffix(pass.Fset.File(n.Pos()).Name(), "_test.go") {
+				pass.Reportf(n.Pos(), "code created outside of a package level variable or an init function")
+			}
+			if arg, ok := constantString(pass.TypesInfo, n); ok && fn.Name() == "NewCode" && strings.Contains(arg, ".") {
+				pass.Reportf(n.Pos(), "NewCode called with the dotted string %q: create a code with a parent with Child", arg)
+			}
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkHandler(pass, pass.TypesInfo.Defs[n.Name].Type().(*types.Signature), n.Body)
+			}
+		case *ast.FuncLit:
+			checkHandler(pass, pass.TypesInfo.Types[n].Type.(*types.Signature), n.Body)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+type importedCode struct {
+	codeStr string
+	object  types.Object
+}
+
+type codeChecker struct {
+	pass     *analysis.Pass
+	codes    map[string]token.Pos
+	imported []importedCode
+}
+
+// define records the code of a package level variable and reports a duplicate CodeStr.
+func (c codeChecker) define(v *types.Var, codeStr string, pos token.Pos) {
+	if existing, ok := c.codes[codeStr]; ok {
+		c.pass.Reportf(pos, "duplicate code %s: it is already defined at %s", codeStr, c.pass.Fset.Position(existing))
+	} else {
+		for _, imported := range c.imported {
+			if imported.codeStr == codeStr {
+				c.pass.Reportf(pos, "duplicate code %s: it is already defined by %s.%s", codeStr, imported.object.Pkg().Path(), imported.object.Name())
+				break
+			}
+		}
+	}
+	c.codes[codeStr] = pos
+	c.pass.ExportObjectFact(v, &codeFact{CodeStr: codeStr})
+}
+
+// codeStr gives the full CodeStr of an expression that creates a code with NewCode or Child.
+// A method of Code that gives a Code, such as SetHTTP, keeps the CodeStr of its receiver.
+// A dotted string given to Child that does not match the parent code is reported.
+func (c codeChecker) codeStr(expr ast.Expr) (string, *ast.CallExpr, bool) {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return "", nil, false
+	}
+	fn := calledFunc(c.pass.TypesInfo, call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != errcodePath {
+		return "", nil, false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	switch {
+	case recv == nil && fn.Name() == "NewCode":
+		arg, ok := constantString(c.pass.TypesInfo, call)
+		return arg, call, ok
+	case recv != nil && isCode(recv.Type()):
+		selector, ok := astutil.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return "", nil, false
+		}
+		parent, ok := c.receiverCodeStr(selector.X)
+		if fn.Name() != "Child" {
+			return parent, call, ok && isCode(fn.Type().(*types.Signature).Results().At(0).Type())
+		}
+		arg, argOK := constantString(c.pass.TypesInfo, call)
+		if !ok || !argOK {
+			return "", call, false
+		}
+		paths := strings.Split(arg, ".")
+		if len(paths) > 1 && paths[len(paths)-2] != lastPath(parent) {
+			c.pass.Reportf(call.Pos(), "Child called with %q but the parent code is %s", arg, parent)
+		}
+		return parent + "." + paths[len(paths)-1], call, true
+	}
+	return "", nil, false
+}
+
+// receiverCodeStr gives the CodeStr of a package level variable or of a code created in place.
+func (c codeChecker) receiverCodeStr(expr ast.Expr) (string, bool) {
+	var ident *ast.Ident
+	switch x := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		ident = x
+	case *ast.SelectorExpr:
+		ident = x.Sel
+	default:
+		codeStr, _, ok := c.codeStr(expr)
+		return codeStr, ok
+	}
+	v, ok := c.pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return "", false
+	}
+	var fact codeFact
+	if !c.pass.ImportObjectFact(v, &fact) {
+		return "", false
+	}
+	return fact.CodeStr, true
+}
+
+func lastPath(codeStr string) string {
+	return codeStr[strings.LastIndex(codeStr, ".")+1:]
+}
+
+// checkHandler reports an HTTP handler that returns an error created without a code.
+// A handler is a function with the signature of httperr.HandlerFunc.
+func checkHandler(pass *analysis.Pass, sig *types.Signature, body *ast.BlockStmt) {
+	if !isHandler(sig) {
+		return
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) != 1 {
+				return true
+			}
+			call, ok := astutil.Unparen(n.Results[0]).(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if fn := calledFunc(pass.TypesInfo, call); fn != nil && isBareErrorConstructor(fn) {
+				pass.Reportf(call.Pos(), "handler returns an error without a code from %s.%s: it is given to clients as an internal error", fn.Pkg().Name(), fn.Name())
+			}
+		}
+		return true
+	})
+}
+
+func isHandler(sig *types.Signature) bool {
+	if sig.Params().Len() != 2 || sig.Results().Len() != 1 {
+		return false
+	}
+	return isNamed(sig.Params().At(0).Type(), "net/http", "ResponseWriter") &&
+		isNamed(sig.Params().At(1).Type(), "net/http", "Request") &&
+		types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+func isBareErrorConstructor(fn *types.Func) bool {
+	if fn.Pkg() == nil || fn.Type().(*types.Signature).Recv() != nil {
+		return false
+	}
+	switch fn.Pkg().Path() {
+	case "errors", "github.com/gregwebs/errors", "github.com/pkg/errors":
+		return fn.Name() == "New" || fn.Name() == "Errorf"
+	case "fmt":
+		return fn.Name() == "Errorf"
+	}
+	return false
+}
+
+func isCodeConstructor(fn *types.Func) bool {
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != errcodePath {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	return (recv == nil && fn.Name() == "NewCode") || (recv != nil && isCode(recv.Type()) && fn.Name() == "Child")
+}
+
+// atInit checks if the node is run at package initialization:
+// it is in the declaration of a package level variable or in an init function.
+func atInit(stack []ast.Node) bool {
+	for _, n := range stack {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return n.Recv == nil && n.Name.Name == "init"
+		case *ast.FuncLit:
+			return false
+		}
+	}
+	return true
+}
+
+func calledFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := astutil.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := info.Uses[ident].(*types.Func)
+	return fn
+}
+
+func constantString(info *types.Info, call *ast.CallExpr) (string, bool) {
+	if len(call.Args) != 1 {
+		return "", false
+	}
+	value := info.Types[call.Args[0]].Value
+	if value == nil || value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(value), true
+}
+
+func isCode(t types.Type) bool {
+	return isNamed(t, errcodePath, "Code")
+}
+
+func isNamed(t types.Type, pkgPath string, name string) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
@@ -0,0 +1,12 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode/lint"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), lint.Analyzer, "codes", "handlers")
+}
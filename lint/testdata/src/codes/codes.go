@@ -0,0 +1,22 @@
+package codes
+
+import "github.com/gregwebs/errcode"
+
+var (
+	ItemCode        = errcode.NewCode("item").SetHTTP(400)    // want ItemCode:"code item"
+	ItemMissingCode = ItemCode.Child("item.missing")          // want ItemMissingCode:"code item.missing"
+	ItemLockedCode  = errcode.NewCode("lock").Child("locked") // want ItemLockedCode:"code lock.locked"
+	DuplicateCode   = errcode.NewCode("item")                 // want DuplicateCode:"code item" `duplicate code item: it is already defined at`
+	WrongParentCode = ItemMissingCode.Child("item.gone")      // want WrongParentCode:"code item.missing.gone" `Child called with "item.gone" but the parent code is item.missing`
+	DottedCode      = errcode.NewCode("item.dotted")          // want DottedCode:"code item.dotted" `NewCode called with the dotted string "item.dotted"`
+)
+
+var initCode errcode.Code
+
+func init() {
+	initCode = errcode.NewCode("init")
+}
+
+func lookup(name string) errcode.Code {
+	return errcode.NewCode(errcode.CodeStr(name)) // want `code created outside of a package level variable or an init function`
+}
@@ -0,0 +1,29 @@
+// Package errcode is a stub of the errcode API that the analyzer checks.
+package errcode
+
+type CodeStr string
+
+type Code struct {
+	codeStr CodeStr
+	Parent  *Code
+}
+
+func NewCode(codeRep CodeStr) Code { return Code{codeStr: codeRep} }
+
+func (code Code) Child(childStr CodeStr) Code { return Code{codeStr: childStr, Parent: &code} }
+
+func (code Code) SetHTTP(status int) Code { return code }
+
+type ErrorCode interface {
+	error
+	Code() Code
+}
+
+type CodedError struct {
+	GetCode Code
+	Err     error
+}
+
+func (e CodedError) Error() string { return e.Err.Error() }
+
+func (e CodedError) Code() Code { return e.GetCode }
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"codes"
+
+	"github.com/gregwebs/errcode"
+)
+
+var ItemCode = errcode.NewCode("item") // want ItemCode:"code item" `duplicate code item: it is already defined by codes.ItemCode`
+
+var MissingCode = codes.ItemCode.Child("missing") // want MissingCode:"code item.missing" `duplicate code item.missing: it is already defined by codes.ItemMissingCode`
+
+func getItem(w http.ResponseWriter, r *http.Request) error {
+	if r.URL.Query().Get("id") == "" {
+		return errors.New("no id") // want `handler returns an error without a code from errors.New`
+	}
+	if r.Method != http.MethodGet {
+		return fmt.Errorf("method %s", r.Method) // want `handler returns an error without a code from fmt.Errorf`
+	}
+	return errcode.CodedError{GetCode: MissingCode, Err: errors.New("no item")}
+}
+
+func notHandler() error {
+	return errors.New("not a handler")
+}
+
+var _ = func(w http.ResponseWriter, r *http.Request) error {
+	return errors.New("closure") // want `handler returns an error without a code from errors.New`
+}
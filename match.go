@@ -0,0 +1,63 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"path"
+	"strings"
+)
+
+// Match checks the CodeStr against a glob-like pattern of dot-separated segments.
+// A "**" segment matches zero or more segments.
+// Other segments are matched against one segment with path.Match,
+// so "*" matches any one segment.
+// A malformed pattern does not match.
+//
+//	CodeStr("input.invalid").Match("input.*")      // true
+//	CodeStr("input").Match("input.*")              // false
+//	CodeStr("timeout").Match("timeout.**")         // true
+//	CodeStr("timeout.db.read").Match("timeout.**") // true
+func (str CodeStr) Match(pattern string) bool {
+	return matchSegments(strings.Split(pattern, "."), strings.Split(str.String(), "."))
+}
+
+func matchSegments(patterns []string, segments []string) bool {
+	for len(patterns) > 0 {
+		pattern := patterns[0]
+		if pattern == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(patterns[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern, segments[0]); err != nil || !matched {
+			return false
+		}
+		patterns = patterns[1:]
+		segments = segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// MatchCode checks if the code of any ErrorCode in the error matches the pattern with CodeStr.Match.
+// As with HasAncestor, the error is unwrapped, including groups, and classified when no ErrorCode is found.
+// An error without an ErrorCode does not match.
+func MatchCode(err error, pattern string) bool {
+	return anyCode(err, func(code Code) bool { return code.CodeStr().Match(pattern) })
+}
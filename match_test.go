@@ -0,0 +1,46 @@
+package errcode_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestCodeStrMatch(t *testing.T) {
+	for _, test := range []struct {
+		codeStr errcode.CodeStr
+		pattern string
+		match   bool
+	}{
+		{"input.invalid", "input.*", true},
+		{"input", "input.*", false},
+		{"input.invalid.field", "input.*", false},
+		{"timeout", "timeout.**", true},
+		{"timeout.db.read", "timeout.**", true},
+		{"timeouts", "timeout.**", false},
+		{"internal.timeout.db", "**.timeout.**", true},
+		{"internal.unavailable", "internal.un*", true},
+		{"internal.unavailable", "internal.[", false},
+		{"input", "input", true},
+	} {
+		if match := test.codeStr.Match(test.pattern); match != test.match {
+			t.Errorf("expected %s matching %s to be %v", test.codeStr, test.pattern, test.match)
+		}
+	}
+}
+
+func TestMatchCode(t *testing.T) {
+	err := errors.Wrap(errcode.NewGoneErr(errors.New("deleted")), "load")
+	if !errcode.MatchCode(err, "missing.**") || errcode.MatchCode(err, "input.**") {
+		t.Error("unexpected match")
+	}
+	// every ErrorCode of a group is checked, as with HasAncestor
+	grouped := errcode.Combine(errcode.NewUnavailableErr(errors.New("down")), errcode.NewGoneErr(errors.New("deleted")))
+	if !errcode.MatchCode(grouped, "missing.gone") || errcode.MatchCode(grouped, "input.**") {
+		t.Error("unexpected match of a group")
+	}
+	if errcode.MatchCode(errors.New("plain"), "**") || errcode.MatchCode(nil, "**") {
+		t.Error("expected an error without a code not to match")
+	}
+}
@@ -16,6 +16,8 @@ package errcode
 import (
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gregwebs/errors"
 )
@@ -23,20 +25,43 @@ import (
 // MetaData is used in a pattern for attaching meta data to codes and inheriting it from a parent.
 // See MetaDataFromAncestors.
 // This is used to attach an HTTP code to a Code as meta data.
+//
+// MetaData is safe for concurrent use when it is only accessed through
+// SetMetaData, GetMetaData and MetaDataFromAncestors.
 type MetaData map[CodeStr]interface{}
 
+// metaDataMu guards all MetaData.
+// Codes can be set up concurrently from init functions in multiple packages or dynamically at runtime.
+var metaDataMu sync.RWMutex
+
 // MetaDataFromAncestors looks for meta data starting at the current code.
 // If not found, it traverses up the hierarchy
 // by looking for the first ancestor with the given metadata key.
 // This is used in the HTTPCode implementation to inherit the HTTP Code from ancestors.
 func (code Code) MetaDataFromAncestors(metaData MetaData) interface{} {
-	if existing, ok := metaData[code.CodeStr()]; ok {
-		return existing
-	}
-	if code.Parent == nil {
-		return nil
+	metaDataMu.RLock()
+	defer metaDataMu.RUnlock()
+	for current := &code; current != nil; current = current.Parent {
+		if existing, ok := lookupMetaData(metaData, current.CodeStr()); ok {
+			return existing
+		}
 	}
-	return (*code.Parent).MetaDataFromAncestors(metaData)
+	return nil
+}
+
+// GetMetaData gets the meta data for the code without looking at ancestors.
+// This is used to implement meta data that is not inherited.
+// A code from Rebase gets the meta data of the code it was rebased from.
+func (code Code) GetMetaData(metaData MetaData) interface{} {
+	return getMetaData(metaData, code)
+}
+
+// getMetaData gets the meta data for the code without looking at ancestors.
+func getMetaData(metaData MetaData, code Code) interface{} {
+	metaDataMu.RLock()
+	defer metaDataMu.RUnlock()
+	existing, _ := lookupMetaData(metaData, code.CodeStr())
+	return existing
 }
 
 type existingCodeError struct {
@@ -51,6 +76,8 @@ func (e existingCodeError) Error() string {
 // SetMetaData is used to implement meta data setters such as SetHTTPCode.
 // Return an error if the metadata is already set.
 func (code Code) SetMetaData(metaData MetaData, item interface{}) error {
+	metaDataMu.Lock()
+	defer metaDataMu.Unlock()
 	if existingCode, ok := metaData[code.CodeStr()]; ok {
 		return existingCodeError{
 			existingMetaData: existingCode,
@@ -61,6 +88,31 @@ func (code Code) SetMetaData(metaData MetaData, item interface{}) error {
 	return nil
 }
 
+// SetMetaDataForce sets the meta data even if it is already set.
+// This is for intentional reconfiguration, such as an application overriding the defaults of a library.
+// Use ReplaceMetaData to retrieve the previous value.
+// Setting nil removes the meta data so that it is inherited from an ancestor again.
+func (code Code) SetMetaDataForce(metaData MetaData, item interface{}) {
+	ReplaceMetaData(metaData, code, item)
+}
+
+// ReplaceMetaData sets the meta data for the code and returns the previous value.
+// The previous value is nil if the meta data was not set.
+// Ancestors are not consulted: an inherited value is not returned.
+// Returning the previous value allows a test to restore it:
+// restoring nil removes the meta data, so that it is inherited from an ancestor again.
+func ReplaceMetaData(metaData MetaData, code Code, item interface{}) interface{} {
+	metaDataMu.Lock()
+	defer metaDataMu.Unlock()
+	previous := metaData[code.CodeStr()]
+	if item == nil {
+		delete(metaData, code.CodeStr())
+	} else {
+		metaData[code.CodeStr()] = item
+	}
+	return previous
+}
+
 var httpMetaData = make(MetaData)
 
 // SetHTTP adds an HTTP code to the meta data.
@@ -74,6 +126,14 @@ func (code Code) SetHTTP(httpCode int) Code {
 	return code
 }
 
+// OverrideHTTP sets the HTTP code even if it is already set, unlike SetHTTP.
+// For example, an application can give a library code a different HTTP code.
+// Returns itself.
+func (code Code) OverrideHTTP(httpCode int) Code {
+	code.SetMetaDataForce(httpMetaData, httpCode)
+	return code
+}
+
 // HTTPCode retrieves the HTTP code for a code or its first ancestor with an HTTP code.
 // If none are specified, it returns nil
 func HTTPCode(code Code) *int {
@@ -86,11 +146,31 @@ func HTTPCode(code Code) *int {
 }
 
 // HTTPCode retrieves the HTTP code for a code or its first ancestor with an HTTP code.
-// If none are specified, it defaults to 400 BadRequest
+// If none are specified, it defaults to DefaultHTTPStatus, which is 400 BadRequest unless changed with SetDefaultHTTPStatus.
+// Use the HTTPCode function to know whether an HTTP code was specified.
 func (code Code) HTTPCode() int {
 	httpCode := HTTPCode(code)
 	if httpCode == nil {
-		return http.StatusBadRequest
+		return DefaultHTTPStatus()
 	}
 	return *httpCode
 }
+
+// defaultHTTPStatus is zero until SetDefaultHTTPStatus is called: DefaultHTTPStatus then gives 400.
+// Codes are created by package variables, so the zero value must already mean the default.
+var defaultHTTPStatus atomic.Int32
+
+// SetDefaultHTTPStatus sets the HTTP status given by Code.HTTPCode for a code without an HTTP code.
+// The default is 400 BadRequest. An application that only uses codes created from the codes of this package will always have an HTTP code.
+// Setting it to 500 InternalServerError stops an unmapped code from being reported as a client error.
+func SetDefaultHTTPStatus(status int) {
+	defaultHTTPStatus.Store(int32(status))
+}
+
+// DefaultHTTPStatus gives the HTTP status set by SetDefaultHTTPStatus.
+func DefaultHTTPStatus() int {
+	if status := defaultHTTPStatus.Load(); status != 0 {
+		return int(status)
+	}
+	return http.StatusBadRequest
+}
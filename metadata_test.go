@@ -0,0 +1,99 @@
+package errcode_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestMetaDataConcurrent(t *testing.T) {
+	parent := errcode.NewCode("concurrentmeta").SetHTTP(418)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := parent.Child(errcode.CodeStr(fmt.Sprintf("concurrentmeta.child%d", i))).SetDescription("child")
+			if child.HTTPCode() != 418 || child.GetMetaData(errcode.MetaData{}) != nil {
+				t.Errorf("unexpected metadata for %v", child)
+			}
+			_ = parent.HTTPCode()
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestOverrideMetaData(t *testing.T) {
+	code := errcode.NewCode("overridemeta").SetHTTP(400)
+	child := code.Child("overridemeta.child")
+	if code.OverrideHTTP(409).HTTPCode() != 409 || child.HTTPCode() != 409 {
+		t.Errorf("expected the override to be inherited, got %d", child.HTTPCode())
+	}
+
+	metaData := make(errcode.MetaData)
+	if previous := errcode.ReplaceMetaData(metaData, child, "first"); previous != nil {
+		t.Errorf("expected no previous value, got %v", previous)
+	}
+	if previous := errcode.ReplaceMetaData(metaData, child, "second"); previous != "first" {
+		t.Errorf("expected the previous value, got %v", previous)
+	}
+	if err := child.SetMetaData(metaData, "third"); err == nil {
+		t.Error("expected SetMetaData to fail when set")
+	}
+	child.SetMetaDataForce(metaData, "third")
+	if value := child.GetMetaData(metaData); value != "third" {
+		t.Errorf("unexpected value %v", value)
+	}
+}
+
+func TestRestoreMetaData(t *testing.T) {
+	metaData := make(errcode.MetaData)
+	parent := errcode.NewCode("restoremeta")
+	child := parent.Child("restoremeta.child")
+	if err := parent.SetMetaData(metaData, "parent"); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.SetMetaData(metaData, "child"); err != nil {
+		t.Fatal(err)
+	}
+	previous := errcode.ReplaceMetaData(metaData, child, "replaced")
+	if value := child.MetaDataFromAncestors(metaData); value != "replaced" {
+		t.Errorf("expected the replaced value, got %v", value)
+	}
+	errcode.ReplaceMetaData(metaData, child, previous)
+	if value := child.MetaDataFromAncestors(metaData); value != "child" {
+		t.Errorf("expected the restored value, got %v", value)
+	}
+
+	// restoring a value that was not set inherits from the parent again
+	unset := parent.Child("restoremeta.unset")
+	previous = errcode.ReplaceMetaData(metaData, unset, "replaced")
+	errcode.ReplaceMetaData(metaData, unset, previous)
+	if value := unset.MetaDataFromAncestors(metaData); value != "parent" {
+		t.Errorf("expected the parent value, got %v", value)
+	}
+	if err := unset.SetMetaData(metaData, "set"); err != nil {
+		t.Errorf("expected SetMetaData to succeed after restoring, got %v", err)
+	}
+}
+
+func TestDefaultHTTPStatus(t *testing.T) {
+	unmapped := errcode.NewCode("unmappedhttp")
+	if status := unmapped.HTTPCode(); status != http.StatusBadRequest {
+		t.Errorf("expected 400 by default, got %d", status)
+	}
+	errcode.SetDefaultHTTPStatus(http.StatusInternalServerError)
+	defer errcode.SetDefaultHTTPStatus(http.StatusBadRequest)
+	if status := unmapped.HTTPCode(); status != http.StatusInternalServerError {
+		t.Errorf("expected the default status, got %d", status)
+	}
+	if errcode.HTTPCode(unmapped) != nil {
+		t.Error("expected no HTTP code to be specified")
+	}
+	if status := errcode.NotFoundCode.HTTPCode(); status != http.StatusNotFound {
+		t.Errorf("expected the specified status, got %d", status)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import "sync/atomic"
+
+// mountOrigins maps the CodeStr of a rebased code to the CodeStr of the code it was rebased from.
+// It is guarded by metaDataMu.
+var mountOrigins = make(map[CodeStr]CodeStr)
+
+// hasMounts avoids looking up mountOrigins when nothing was rebased.
+var hasMounts atomic.Bool
+
+// Rebase gives the code with its whole hierarchy placed under a new parent.
+// For example, the code "input.invalid" rebased under "payments" is "payments.input.invalid".
+// This allows a library that defines its own code tree to be mounted under the namespace of an application.
+//
+// The rebased codes keep the meta data of the codes they were rebased from,
+// unless meta data is set on a rebased code directly.
+// A rebased code is also a descendant of the ancestors of the original code for IsAncestor,
+// so that for example IsNotFound is still true.
+// The rebased codes are registered in the DefaultRegistry.
+func (code Code) Rebase(newParent Code) Code {
+	var chain []Code
+	for current := &code; current != nil; current = current.Parent {
+		chain = append(chain, *current)
+	}
+	rebased := newParent
+	metaDataMu.Lock()
+	for i := len(chain) - 1; i >= 0; i-- {
+		parent := rebased
+		rebased = Code{codeStr: chain[i].codeStr, Parent: &parent}
+		mountOrigins[rebased.CodeStr()] = chain[i].CodeStr()
+	}
+	metaDataMu.Unlock()
+	hasMounts.Store(true)
+	for current := &rebased; current != nil && current.CodeStr() != newParent.CodeStr(); current = current.Parent {
+		defaultRegistry.Register(*current)
+	}
+	return rebased
+}
+
+// MountOrigin gives the CodeStr of the code that the code was rebased from with Rebase.
+// Returns false if the code was not rebased.
+func (code Code) MountOrigin() (CodeStr, bool) {
+	if !hasMounts.Load() {
+		return "", false
+	}
+	metaDataMu.RLock()
+	defer metaDataMu.RUnlock()
+	origin, ok := mountOrigins[code.CodeStr()]
+	return origin, ok
+}
+
+// lookupMetaData looks up meta data for the CodeStr and then for the codes it was rebased from.
+// metaDataMu must be held.
+func lookupMetaData(metaData MetaData, codeStr CodeStr) (interface{}, bool) {
+	for {
+		if existing, ok := metaData[codeStr]; ok {
+			return existing, true
+		}
+		if !hasMounts.Load() {
+			return nil, false
+		}
+		origin, ok := mountOrigins[codeStr]
+		if !ok {
+			return nil, false
+		}
+		codeStr = origin
+	}
+}
+
+// Mount is a code tree that was rebased under a new parent with Registry.Mount.
+type Mount struct {
+	root    Code
+	mounted Code
+}
+
+// Mount rebases a code and its registered descendants under a new parent with Rebase.
+// The Mount translates the codes of errors from the original tree.
+//
+//	payments := errcode.NewCode("payments")
+//	mount := errcode.DefaultRegistry().Mount(paymentslib.RootCode, payments)
+//	return mount.Apply(paymentslib.Charge(ctx))
+func (r *Registry) Mount(root Code, newParent Code) Mount {
+	for _, code := range r.Codes() {
+		if code.IsAncestor(root) {
+			code.Rebase(newParent)
+		}
+	}
+	return Mount{root: root, mounted: root.Rebase(newParent)}
+}
+
+// Root gives the rebased root code.
+func (m Mount) Root() Code {
+	return m.mounted
+}
+
+// Code gives the rebased code of a code from the original tree.
+// A code that is not in the original tree is returned unchanged.
+func (m Mount) Code(code Code) Code {
+	if code.CodeStr() == m.root.CodeStr() {
+		return m.mounted
+	}
+	if code.Parent == nil || !code.IsAncestor(m.root) {
+		return code
+	}
+	parent := m.Code(*code.Parent)
+	return Code{codeStr: code.codeStr, Parent: &parent}
+}
+
+// Apply gives the error the rebased code of its code.
+// An error with a code that is not in the original tree is returned unchanged.
+// Returns nil if err is nil.
+func (m Mount) Apply(err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	code := err.Code()
+	mounted := m.Code(code)
+	if mounted.CodeStr() == code.CodeStr() {
+		return err
+	}
+	return CodedError{GetCode: mounted, Err: err}
+}
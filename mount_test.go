@@ -0,0 +1,52 @@
+package errcode_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestRebase(t *testing.T) {
+	libRoot := errcode.NotFoundCode.Child("missing.lib")
+	libItem := libRoot.Child("missing.lib.item").SetDefaultUserMsg("No such item")
+	libGone := libRoot.Child("missing.lib.gone").SetHTTP(http.StatusGone)
+	payments := errcode.NewCode("payments")
+
+	rebased := libItem.Rebase(payments)
+	if rebased.CodeStr() != "payments.missing.lib.item" {
+		t.Errorf("unexpected code %s", rebased.CodeStr())
+	}
+	if origin, ok := rebased.MountOrigin(); !ok || origin != libItem.CodeStr() {
+		t.Errorf("unexpected origin %s", origin)
+	}
+	if rebased.HTTPCode() != http.StatusNotFound || rebased.DefaultUserMsg() != "No such item" {
+		t.Errorf("expected the meta data to be kept, got %d %s", rebased.HTTPCode(), rebased.DefaultUserMsg())
+	}
+	if !rebased.IsAncestor(errcode.NotFoundCode) || !rebased.IsAncestor(payments) {
+		t.Error("expected the original and the new ancestors")
+	}
+	if code, ok := errcode.DefaultRegistry().Lookup("payments.missing"); !ok || code.HTTPCode() != http.StatusNotFound {
+		t.Error("expected the rebased ancestors to be registered")
+	}
+
+	mount := errcode.DefaultRegistry().Mount(libRoot, payments)
+	if mount.Root().CodeStr() != "payments.missing.lib" {
+		t.Errorf("unexpected root %s", mount.Root().CodeStr())
+	}
+	if _, ok := errcode.DefaultRegistry().Lookup("payments.missing.lib.gone"); !ok {
+		t.Error("expected the descendants to be mounted")
+	}
+	err := mount.Apply(errcode.NewCodedError(errors.New("deleted"), libGone))
+	if err.Code().CodeStr() != "payments.missing.lib.gone" || err.Code().HTTPCode() != http.StatusGone {
+		t.Errorf("unexpected code %s", err.Code().CodeStr())
+	}
+	if !errcode.IsNotFound(err) || !errors.Is(err, errcode.CodeIs(libGone)) {
+		t.Error("expected the original code to still match")
+	}
+	other := errcode.NewInvalidInputErr(errors.New("bad"))
+	if mount.Apply(other) != errcode.ErrorCode(other) {
+		t.Error("expected a code outside the tree to be unchanged")
+	}
+}
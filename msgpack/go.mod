@@ -0,0 +1,15 @@
+module github.com/gregwebs/errcode/msgpack
+
+go 1.21.9
+
+replace github.com/gregwebs/errcode => ../
+
+require (
+	github.com/gregwebs/errcode v0.11.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/gregwebs/errors v1.5.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+)
@@ -0,0 +1,53 @@
+// Package msgpack encodes ErrorCodes as MessagePack for binary transports.
+//
+// The payload is the JSONFormat of the error with the same field names as its JSON.
+// Unmarshal decodes a payload back into an ErrorCode (an errcode.RemoteErr).
+package msgpack
+
+import (
+	"bytes"
+
+	"github.com/gregwebs/errcode"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the media type of a MessagePack payload.
+const ContentType = "application/msgpack"
+
+// Marshal encodes the JSONFormat of the ErrorCode as MessagePack.
+// The options are given to NewJSONFormat.
+func Marshal(errCode errcode.ErrorCode, opts ...errcode.FormatOption) ([]byte, error) {
+	return MarshalFormat(errcode.NewJSONFormat(errCode, opts...))
+}
+
+// MarshalFormat encodes a JSONFormat as MessagePack.
+// The fields are named by their json tags.
+func MarshalFormat(format errcode.JSONFormat) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalFormat decodes a JSONFormat from MessagePack.
+// Maps in the Data are decoded as map[string]interface{}, as with JSON.
+func UnmarshalFormat(data []byte) (errcode.JSONFormat, error) {
+	var format errcode.JSONFormat
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	err := dec.Decode(&format)
+	return format, err
+}
+
+// Unmarshal decodes a payload from Marshal into an ErrorCode.
+// The ErrorCode is an errcode.RemoteErr so that the code, message, and data are propagated.
+func Unmarshal(data []byte) (errcode.ErrorCode, error) {
+	format, err := UnmarshalFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	return errcode.NewRemoteErr(format, nil), nil
+}
@@ -0,0 +1,19 @@
+module github.com/gregwebs/errcode/nats
+
+go 1.21.9
+
+require (
+	github.com/gregwebs/errcode v0.11.0
+	github.com/nats-io/nats.go v1.36.0
+)
+
+require (
+	github.com/gregwebs/errors v1.5.0 // indirect
+	github.com/klauspost/compress v1.17.2 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.18.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+)
+
+replace github.com/gregwebs/errcode => ../
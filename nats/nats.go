@@ -0,0 +1,112 @@
+// Package nats sends ErrorCodes in NATS request/reply messages.
+//
+// A responder replies with NewErrorMsg or Respond.
+// The reply has the HeaderCode header and the JSONFormat as its payload.
+// The NATS micro service error headers are also set so that other NATS clients see an error.
+//
+// A requester converts a reply back to an error with FromMsg,
+// or uses Request which also gives codes to NATS errors.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/gregwebs/errcode"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// HeaderCode is the header of a reply that holds the CodeStr of the error.
+	HeaderCode = "Errcode-Code"
+	// HeaderServiceError is the NATS micro service header with the error message.
+	HeaderServiceError = "Nats-Service-Error"
+	// HeaderServiceErrorCode is the NATS micro service header with the error status.
+	// The HTTP code of the code is used.
+	HeaderServiceErrorCode = "Nats-Service-Error-Code"
+)
+
+// NewErrorMsg creates a reply message for an error.
+// The ErrorCode is resolved with CodeChain and an error without one is given InternalCode.
+// The payload is the JSONFormat created with the given options.
+func NewErrorMsg(err error, opts ...errcode.FormatOption) (*nats.Msg, error) {
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		errCode = errcode.NewInternalErr(err)
+	}
+	format := errcode.NewJSONFormat(errCode, opts...)
+	data, jsonErr := errcode.MarshalJSONFormat(format)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+	msg := nats.NewMsg("")
+	msg.Data = data
+	msg.Header.Set(HeaderCode, format.Code.String())
+	msg.Header.Set(HeaderServiceError, format.Msg)
+	msg.Header.Set(HeaderServiceErrorCode, strconv.Itoa(errCode.Code().HTTPCode()))
+	return msg, nil
+}
+
+// Respond replies to a request with an error message created by NewErrorMsg.
+func Respond(request *nats.Msg, err error, opts ...errcode.FormatOption) error {
+	reply, msgErr := NewErrorMsg(err, opts...)
+	if msgErr != nil {
+		return msgErr
+	}
+	return request.RespondMsg(reply)
+}
+
+// FromMsg gives the error of a reply message created by NewErrorMsg as an errcode.RemoteErr.
+// A reply without the HeaderCode header is not an error and gives nil.
+// If the payload is not a JSONFormat, the RemoteErr is created from the headers.
+func FromMsg(msg *nats.Msg) error {
+	if msg == nil || msg.Header == nil {
+		return nil
+	}
+	codeStr := msg.Header.Get(HeaderCode)
+	if codeStr == "" {
+		return nil
+	}
+	var format errcode.JSONFormat
+	if err := json.Unmarshal(msg.Data, &format); err != nil || format.Code == "" {
+		format = errcode.JSONFormat{
+			Code: errcode.CodeStr(codeStr),
+			Msg:  msg.Header.Get(HeaderServiceError),
+		}
+	}
+	return errcode.NewRemoteErr(format, nil)
+}
+
+// FromNATSError gives a code to an error of a NATS request.
+// A request without a responder is given UnavailableCode
+// and a timeout is given DeadlineExceededCode.
+// Other errors are returned unchanged.
+func FromNATSError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, nats.ErrNoResponders):
+		return errcode.NewCodedError(err, errcode.UnavailableCode)
+	case errors.Is(err, nats.ErrTimeout):
+		return errcode.NewCodedError(err, errcode.DeadlineExceededCode)
+	}
+	if errCode := errcode.FromContextError(err); errCode != nil {
+		return errCode
+	}
+	return err
+}
+
+// Request sends a request and waits for the reply.
+// An error reply is converted with FromMsg and a NATS error with FromNATSError.
+func Request(ctx context.Context, conn *nats.Conn, subject string, data []byte) (*nats.Msg, error) {
+	reply, err := conn.RequestWithContext(ctx, subject, data)
+	if err != nil {
+		return nil, FromNATSError(err)
+	}
+	if err := FromMsg(reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
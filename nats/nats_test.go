@@ -0,0 +1,56 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	errnats "github.com/gregwebs/errcode/nats"
+	"github.com/nats-io/nats.go"
+)
+
+func TestErrorMsg(t *testing.T) {
+	msg, err := errnats.NewErrorMsg(errcode.NewRateLimitErr(errors.New("slow down"), 2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header.Get(errnats.HeaderCode) != "ratelimit" || msg.Header.Get(errnats.HeaderServiceErrorCode) != "429" {
+		t.Errorf("unexpected headers %v", msg.Header)
+	}
+
+	remoteErr := errnats.FromMsg(msg)
+	if remoteErr == nil || remoteErr.Error() != "slow down" {
+		t.Errorf("unexpected error %v", remoteErr)
+	}
+	errCode := errcode.CodeChain(remoteErr)
+	if errCode.Code().CodeStr() != errcode.TooManyRequestsCode.CodeStr() {
+		t.Errorf("unexpected code %v", errCode.Code())
+	}
+	if data, ok := errcode.ClientData(errCode).(map[string]interface{}); !ok || data["retry_after"] != float64(2) {
+		t.Errorf("unexpected data %v", errcode.ClientData(errCode))
+	}
+
+	if err := errnats.FromMsg(&nats.Msg{Data: []byte("ok")}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	headerOnly := nats.NewMsg("")
+	headerOnly.Header.Set(errnats.HeaderCode, "missing")
+	headerOnly.Header.Set(errnats.HeaderServiceError, "no item")
+	if err := errnats.FromMsg(headerOnly); !errcode.IsNotFound(err) || err.Error() != "no item" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestFromNATSError(t *testing.T) {
+	if err := errnats.FromNATSError(nats.ErrNoResponders); !errcode.IsUnavailable(err) {
+		t.Errorf("expected unavailable, got %v", err)
+	}
+	if err := errnats.FromNATSError(nats.ErrTimeout); !errcode.IsTimeout(err) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if err := errnats.FromNATSError(context.Canceled); !errcode.IsCanceled(err) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+}
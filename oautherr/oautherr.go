@@ -0,0 +1,152 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oautherr maps the error strings of OAuth 2.0 (RFC 6749) and of bearer tokens (RFC 6750) to codes.
+// The codes of this package are under AuthCode, and builtin codes are given the error strings that apply to them.
+// WriteError writes the spec-compliant body of an auth endpoint:
+//
+//	{"error": "invalid_grant", "error_description": "The authorization code expired"}
+//
+// An application can map its own codes with SetError.
+package oautherr
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/gregwebs/errors"
+)
+
+var errorMetaData = make(errcode.MetaData)
+
+var (
+	fromErrorMu sync.RWMutex
+	fromError   = make(map[string]errcode.Code)
+)
+
+// SetError adds an OAuth error string to the meta data of a code.
+// The error string can be retrieved with GetError.
+// The first code set for an error string is used by Code.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func SetError(code errcode.Code, oauthError string) errcode.Code {
+	if err := code.SetMetaData(errorMetaData, oauthError); err != nil {
+		panic(errors.Wrap(err, "SetError"))
+	}
+	fromErrorMu.Lock()
+	defer fromErrorMu.Unlock()
+	if _, ok := fromError[oauthError]; !ok {
+		fromError[oauthError] = code
+	}
+	return code
+}
+
+// GetError retrieves the OAuth error string for a code or its first ancestor with an error string.
+// If none are specified, it defaults to server_error for a 5xx HTTP code and to invalid_request otherwise.
+func GetError(code errcode.Code) string {
+	if oauthError := code.MetaDataFromAncestors(errorMetaData); oauthError != nil {
+		return oauthError.(string)
+	}
+	if code.HTTPCode() >= http.StatusInternalServerError {
+		return "server_error"
+	}
+	return "invalid_request"
+}
+
+// Code gives the code for an OAuth error string.
+func Code(oauthError string) (errcode.Code, bool) {
+	fromErrorMu.RLock()
+	defer fromErrorMu.RUnlock()
+	code, ok := fromError[oauthError]
+	return code, ok
+}
+
+var (
+	// InvalidRequestCode is the invalid_request error: the request is malformed.
+	InvalidRequestCode = SetError(errcode.AuthCode.Child("auth.invalid_request").SetHTTP(http.StatusBadRequest), "invalid_request")
+	// InvalidClientCode is the invalid_client error: client authentication failed.
+	InvalidClientCode = SetError(errcode.NotAuthenticatedCode.Child("auth.unauthenticated.invalid_client").
+				SetHTTPHeaders(http.Header{"Www-Authenticate": {"Basic"}}), "invalid_client")
+	// InvalidGrantCode is the invalid_grant error: the authorization grant or refresh token is invalid, expired, or revoked.
+	InvalidGrantCode = SetError(errcode.AuthCode.Child("auth.invalid_grant").SetHTTP(http.StatusBadRequest), "invalid_grant")
+	// UnauthorizedClientCode is the unauthorized_client error: the client may not use the grant type.
+	UnauthorizedClientCode = SetError(errcode.AuthCode.Child("auth.unauthorized_client").SetHTTP(http.StatusBadRequest), "unauthorized_client")
+	// UnsupportedGrantTypeCode is the unsupported_grant_type error.
+	UnsupportedGrantTypeCode = SetError(errcode.AuthCode.Child("auth.unsupported_grant_type").SetHTTP(http.StatusBadRequest), "unsupported_grant_type")
+	// UnsupportedResponseTypeCode is the unsupported_response_type error of the authorization endpoint.
+	UnsupportedResponseTypeCode = SetError(errcode.AuthCode.Child("auth.unsupported_response_type").SetHTTP(http.StatusBadRequest), "unsupported_response_type")
+	// InvalidScopeCode is the invalid_scope error: the requested scope is invalid or exceeds the granted scope.
+	InvalidScopeCode = SetError(errcode.AuthCode.Child("auth.invalid_scope").SetHTTP(http.StatusBadRequest), "invalid_scope")
+	// InvalidTokenCode is the invalid_token error of RFC 6750: the access token is expired, revoked, or malformed.
+	InvalidTokenCode = SetError(errcode.NotAuthenticatedCode.Child("auth.unauthenticated.invalid_token").
+				SetHTTPHeaders(http.Header{"Www-Authenticate": {`Bearer error="invalid_token"`}}), "invalid_token")
+	// InsufficientScopeCode is the insufficient_scope error of RFC 6750: the access token does not have the required scope.
+	InsufficientScopeCode = SetError(errcode.ForbiddenCode.Child("auth.forbidden.insufficient_scope").
+				SetHTTPHeaders(http.Header{"Www-Authenticate": {`Bearer error="insufficient_scope"`}}), "insufficient_scope")
+)
+
+func init() {
+	SetError(errcode.ForbiddenCode, "access_denied")
+	SetError(errcode.InternalCode, "server_error")
+	SetError(errcode.UnavailableCode, "temporarily_unavailable")
+}
+
+// ErrorResponse is the body of an OAuth error response.
+type ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	ErrorURI         string `json:"error_uri,omitempty"`
+}
+
+// NewErrorResponse creates the ErrorResponse of an ErrorCode.
+// The description is the user message of the JSONFormat with the same options,
+// and the URI is its documentation link when the DocLinks option is given.
+func NewErrorResponse(errCode errcode.ErrorCode, opts ...errcode.FormatOption) ErrorResponse {
+	format := errcode.NewJSONFormat(errCode, opts...)
+	return ErrorResponse{
+		Error:            GetError(errCode.Code()),
+		ErrorDescription: format.Msg,
+		ErrorURI:         format.Doc,
+	}
+}
+
+// FromErrorResponse converts an ErrorResponse received from an auth server to an ErrorCode.
+// An unknown error string is given AuthCode.
+func FromErrorResponse(resp ErrorResponse) errcode.ErrorCode {
+	code, ok := Code(resp.Error)
+	if !ok {
+		code = errcode.AuthCode
+	}
+	msg := resp.Error
+	if resp.ErrorDescription != "" {
+		msg += ": " + resp.ErrorDescription
+	}
+	return errcode.NewCodedError(errors.New(msg), code)
+}
+
+// WriteError writes the ErrorResponse of an error with the HTTP code of the error.
+// The headers are set as with httperr.Write, such as the WWW-Authenticate header of InvalidTokenCode,
+// and caching is disabled as the specification requires.
+// An error without an ErrorCode is written as a server_error.
+func WriteError(w http.ResponseWriter, err error, opts ...errcode.FormatOption) {
+	errCode := httperr.ErrorCode(err)
+	httperr.SetHeaders(w.Header(), errCode)
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(errCode.Code().HTTPCode())
+	_ = json.NewEncoder(w).Encode(NewErrorResponse(errCode, opts...))
+}
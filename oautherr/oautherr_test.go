@@ -0,0 +1,70 @@
+package oautherr_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/oautherr"
+	"github.com/gregwebs/errors"
+)
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := errcode.WithUserMsg("The authorization code expired", errcode.NewCodedError(errors.New("code used"), oautherr.InvalidGrantCode))
+	oautherr.WriteError(rec, err)
+	if rec.Code != 400 || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 2 || body["error"] != "invalid_grant" || body["error_description"] != "The authorization code expired" {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	rec = httptest.NewRecorder()
+	oautherr.WriteError(rec, errcode.NewCodedError(errors.New("expired"), oautherr.InvalidTokenCode))
+	if rec.Code != 401 || rec.Header().Get("Www-Authenticate") != `Bearer error="invalid_token"` {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	oautherr.WriteError(rec, errors.New("database down"))
+	if rec.Code != 500 || !json.Valid(rec.Body.Bytes()) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body)
+	}
+	if resp := oautherr.NewErrorResponse(errcode.NewInternalErr(errors.New("x"))); resp.Error != "server_error" {
+		t.Errorf("expected server_error, got %v", resp.Error)
+	}
+}
+
+func TestGetError(t *testing.T) {
+	tests := map[string]errcode.Code{
+		"access_denied":           errcode.ForbiddenCode,
+		"insufficient_scope":      oautherr.InsufficientScopeCode,
+		"temporarily_unavailable": errcode.UnavailableCode,
+		"server_error":            errcode.UnimplementedCode,
+		"invalid_request":         errcode.InvalidInputCode,
+	}
+	for expected, code := range tests {
+		if oautherr.GetError(code) != expected {
+			t.Errorf("expected %s for %s, got %s", expected, code.CodeStr(), oautherr.GetError(code))
+		}
+	}
+}
+
+func TestFromErrorResponse(t *testing.T) {
+	errCode := oautherr.FromErrorResponse(oautherr.ErrorResponse{Error: "invalid_client", ErrorDescription: "unknown client"})
+	if errCode.Code().CodeStr() != oautherr.InvalidClientCode.CodeStr() || errCode.Error() != "invalid_client: unknown client" {
+		t.Errorf("unexpected error %s %v", errCode.Code().CodeStr(), errCode)
+	}
+	if !errcode.IsUnauthenticated(errCode) {
+		t.Error("expected invalid_client to be not authenticated")
+	}
+	if errCode := oautherr.FromErrorResponse(oautherr.ErrorResponse{Error: "custom_error"}); errCode.Code().CodeStr() != errcode.AuthCode.CodeStr() {
+		t.Errorf("expected an unknown error to be an auth code, got %s", errCode.Code().CodeStr())
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openapi generates OpenAPI 3.1 components for the error responses of registered codes.
+// This keeps an API specification in sync with the codes a service can return.
+//
+// There is a schema for the JSONFormat body named ErrorResponse.
+// For each HTTP status there is a schema that restricts the code to an enum of the codes with that status,
+// and a response that references the schema.
+// These are named by the status, for example Error404.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gregwebs/errcode"
+)
+
+// ErrorResponseSchema is the name of the schema for the JSONFormat body.
+const ErrorResponseSchema = "ErrorResponse"
+
+// Schema is an OpenAPI 3.1 (JSON Schema) schema object.
+// Only the fields needed to describe error responses are included.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	AllOf       []*Schema          `json:"allOf,omitempty"`
+}
+
+// MediaType is an OpenAPI media type object.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Response is an OpenAPI response object.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Components is an OpenAPI components object.
+// It can be marshalled to JSON and merged into the components of a specification.
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas"`
+	Responses map[string]*Response `json:"responses"`
+}
+
+// SchemaRef gives a reference to a schema in the components.
+func SchemaRef(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// StatusName gives the name of the schema and response for an HTTP status, for example Error404.
+func StatusName(status int) string {
+	return fmt.Sprintf("Error%d", status)
+}
+
+// ErrorResponse gives the schema of JSONFormat.
+func ErrorResponse() *Schema {
+	return &Schema{
+		Type:        "object",
+		Description: "An error response",
+		Properties: map[string]*Schema{
+			"code":           {Type: "string", Description: "The error code"},
+			"msg":            {Type: "string", Description: "The error message"},
+			"data":           {Description: "Data specific to the error code"},
+			"operation":      {Type: "string"},
+			"label":          {Type: "string"},
+			"others":         {Type: "array", Items: SchemaRef(ErrorResponseSchema)},
+			"doc":            {Type: "string", Description: "A link to the documentation of the code"},
+			"others_omitted": {Type: "integer", Description: "The number of other errors left out"},
+			"operations":     {Type: "array", Items: &Schema{Type: "string"}, Description: "The operations of the error, outermost first"},
+			"request_id":     {Type: "string", Description: "Correlates the error with a request"},
+			"path":           {Type: "array", Items: &Schema{Type: "integer"}, Description: "The indexes of the groups traversed to find an error of others"},
+			"stack":          {Type: "array", Items: &Schema{Type: "object", Properties: map[string]*Schema{"function": {Type: "string"}, "file": {Type: "string"}, "line": {Type: "integer"}}}, Description: "The stack trace of the error"},
+			"time":           {Type: "string", Format: "date-time", Description: "When the error was created"},
+			"origin":         {Type: "string", Description: "The service or component that produced the error"},
+		},
+		Required: []string{"code", "msg", "data"},
+	}
+}
+
+// StatusCodes gives the codes of a registry grouped by the HTTP status from Code.HTTPCode.
+// The codes for each status are sorted.
+func StatusCodes(registry *errcode.Registry) map[int][]errcode.CodeStr {
+	byStatus := make(map[int][]errcode.CodeStr)
+	for _, code := range registry.Codes() {
+		status := code.HTTPCode()
+		byStatus[status] = append(byStatus[status], code.CodeStr())
+	}
+	return byStatus
+}
+
+// NewComponents generates the components for all the codes in the registry.
+func NewComponents(registry *errcode.Registry) Components {
+	components := Components{
+		Schemas:   map[string]*Schema{ErrorResponseSchema: ErrorResponse()},
+		Responses: make(map[string]*Response),
+	}
+	byStatus := StatusCodes(registry)
+	statuses := make([]int, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		codes := byStatus[status]
+		enum := make([]string, len(codes))
+		for i, code := range codes {
+			enum[i] = string(code)
+		}
+		name := StatusName(status)
+		components.Schemas[name] = &Schema{
+			AllOf: []*Schema{
+				SchemaRef(ErrorResponseSchema),
+				{Properties: map[string]*Schema{"code": {Type: "string", Enum: enum}}},
+			},
+		}
+		components.Responses[name] = &Response{
+			Description: http.StatusText(status),
+			Content: map[string]MediaType{
+				"application/json": {Schema: SchemaRef(name)},
+			},
+		}
+	}
+	return components
+}
@@ -0,0 +1,114 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/openapi"
+	"github.com/gregwebs/errors"
+)
+
+func TestNewComponents(t *testing.T) {
+	registry := errcode.NewRegistry()
+	registry.Register(errcode.NotFoundCode, errcode.InvalidInputCode, errcode.InternalCode, errcode.StateCode)
+	components := openapi.NewComponents(registry)
+
+	if len(components.Responses) != 3 {
+		t.Fatalf("expected a response per status, got %v", components.Responses)
+	}
+	schema := components.Schemas["Error400"]
+	if schema == nil || len(schema.AllOf) != 2 {
+		t.Fatalf("expected an Error400 schema, got %v", components.Schemas)
+	}
+	enum := schema.AllOf[1].Properties["code"].Enum
+	if !reflect.DeepEqual(enum, []string{"input", "state"}) {
+		t.Errorf("unexpected enum %v", enum)
+	}
+	response := components.Responses["Error404"]
+	if response.Description != "Not Found" || response.Content["application/json"].Schema.Ref != "#/components/schemas/Error404" {
+		t.Errorf("unexpected response %v", response)
+	}
+
+	b, err := json.Marshal(components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded["schemas"]["ErrorResponse"]; !ok {
+		t.Errorf("expected an ErrorResponse schema in %s", b)
+	}
+}
+
+func TestErrorResponseFields(t *testing.T) {
+	schema := openapi.ErrorResponse()
+	group := errcode.Combine(errcode.NewNotFoundErr(errors.New("no item")), errcode.NewInvalidInputErr(errors.New("bad")))
+	for _, test := range []struct {
+		field   string
+		errCode errcode.ErrorCode
+		opts    []errcode.FormatOption
+	}{
+		{"code", group, nil},
+		{"msg", group, nil},
+		{"data", group, nil},
+		{"operation", errcode.Op("items.get")(group), nil},
+		{"label", errcode.LabeledErrCode{Label: "item", Err: group}, nil},
+		{"others", group, nil},
+		{"doc", group, []errcode.FormatOption{errcode.DocLinks("https://docs.example.com/errors")}},
+		{"others_omitted", group, []errcode.FormatOption{errcode.MaxOthers(0)}},
+		{"operations", errcode.Op("items.get")(errcode.Op("db.query")(group)), []errcode.FormatOption{errcode.IncludeOperations()}},
+		{"request_id", errcode.WithRequestID("req-1", group), nil},
+		{"path", group, []errcode.FormatOption{errcode.IncludePaths()}},
+		{"stack", errcode.NewInternalErr(errors.New("db down")), []errcode.FormatOption{errcode.IncludeStack()}},
+		{"time", errcode.WithTimestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), group), nil},
+		{"origin", errcode.WithOrigin("billing", group), nil},
+	} {
+		var buf bytes.Buffer
+		if err := errcode.WriteJSON(&buf, test.errCode, test.opts...); err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+			t.Fatal(err)
+		}
+		if test.field == "path" {
+			// the path is only given to others
+			var others []map[string]json.RawMessage
+			if err := json.Unmarshal(fields["others"], &others); err != nil || len(others) == 0 {
+				t.Fatalf("expected others in %s", buf.String())
+			}
+			fields = others[0]
+		}
+		if _, ok := fields[test.field]; !ok {
+			t.Errorf("expected %s to be written in %s", test.field, buf.String())
+		}
+		if schema.Properties[test.field] == nil {
+			t.Errorf("expected a schema property for %s", test.field)
+		}
+	}
+}
+
+func TestErrorResponseComplete(t *testing.T) {
+	schema := openapi.ErrorResponse()
+	formatType := reflect.TypeOf(errcode.JSONFormat{})
+	fields := make(map[string]bool, formatType.NumField())
+	for i := 0; i < formatType.NumField(); i++ {
+		name, _, _ := strings.Cut(formatType.Field(i).Tag.Get("json"), ",")
+		fields[name] = true
+		if schema.Properties[name] == nil {
+			t.Errorf("expected a schema property for the JSONFormat field %s", name)
+		}
+	}
+	for name := range schema.Properties {
+		if !fields[name] {
+			t.Errorf("expected a JSONFormat field for the schema property %s", name)
+		}
+	}
+}
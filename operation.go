@@ -13,6 +13,13 @@
 
 package errcode
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gregwebs/errors"
+)
+
 // HasOperation is an interface to retrieve the operation that occurred during an error.
 // The end goal is to be able to see a trace of operations in a distributed system to quickly have a good understanding of what occurred.
 // Inspiration is taken from upspin error handling: https://commandcenter.blogspot.com/2017/12/error-handling-in-upspin.html
@@ -29,18 +36,52 @@ type HasOperation interface {
 }
 
 // Operation will return an operation string if it exists.
-// It checks recursively for the HasOperation interface.
+// It checks recursively for the HasOperation interface,
+// including the members of groups in the order given by ErrorCodes.
 // Otherwise it will return the zero value (empty) string.
 func Operation(v interface{}) string {
-	if hasOp, ok := v.(HasOperation); ok {
+	if hasOp, ok := findDeep[HasOperation](v); ok {
 		return hasOp.GetOperation()
 	}
-	if un, ok := v.(unwrapError); ok {
-		return Operation(un.Unwrap())
-	}
 	return ""
 }
 
+// OperationChain collects every operation found in an error, outermost first.
+// Unlike Operation, it does not stop at the first HasOperation:
+// it traverses the whole unwrap chain and then each member of a group in order.
+// This gives a trace of nested operations.
+// Consecutive repeats of an operation are given once.
+func OperationChain(err error) []string {
+	var ops []string
+	collectOperations(err, &ops)
+	return ops
+}
+
+func collectOperations(err error, ops *[]string) {
+	for unErr := err; unErr != nil; unErr = errors.Unwrap(unErr) {
+		if compactErr, ok := unErr.(CompactErr); ok {
+			for _, op := range compactErr.Operations {
+				if len(*ops) == 0 || (*ops)[len(*ops)-1] != op {
+					*ops = append(*ops, op)
+				}
+			}
+			continue
+		}
+		if hasOp, ok := unErr.(HasOperation); ok {
+			if op := hasOp.GetOperation(); op != "" && (len(*ops) == 0 || (*ops)[len(*ops)-1] != op) {
+				*ops = append(*ops, op)
+			}
+		}
+		// The Unwrap of a group only gives its first member
+		if members := errors.Errors(unErr); len(members) > 0 {
+			for _, member := range members {
+				collectOperations(member, ops)
+			}
+			return
+		}
+	}
+}
+
 // EmbedOp is designed to be embedded into your existing error structs.
 // It provides the HasOperation interface already, which can reduce your boilerplate.
 type EmbedOp struct{ Op string }
@@ -64,8 +105,9 @@ func (e OpErrCode) Unwrap() error {
 }
 
 // Error prefixes the operation to the underlying Err Error.
+// If the underlying Err already has the same operation as a prefix, it is not repeated.
 func (e OpErrCode) Error() string {
-	return e.Operation + ": " + e.Err.Error()
+	return prefixOnce(e.Operation, e.Err.Error(), Operation(e.Err) == e.Operation)
 }
 
 // GetOperation satisfies the HasOperation interface.
@@ -78,9 +120,25 @@ func (e OpErrCode) Code() Code {
 	return e.Err.Code()
 }
 
-var _ ErrorCode = (*OpErrCode)(nil)    // assert implements interface
-var _ HasOperation = (*OpErrCode)(nil) // assert implements interface
-var _ unwrapError = (*OpErrCode)(nil)  // assert implements interface
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e OpErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*OpErrCode)(nil)     // assert implements interface
+var _ HasOperation = (*OpErrCode)(nil)  // assert implements interface
+var _ unwrapError = (*OpErrCode)(nil)   // assert implements interface
+var _ fmt.Formatter = (*OpErrCode)(nil) // assert implements interface
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e OpErrCode) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "OpErrCode{Operation: %q, Err: %#v}", e.Operation, e.Err)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
 
 // AddOp is constructed by Op. It allows method chaining with AddTo.
 type AddOp func(ErrorCode) OpErrCode
@@ -92,6 +150,8 @@ func (addOp AddOp) AddTo(err ErrorCode) OpErrCode {
 
 // Op adds an operation to an ErrorCode with AddTo.
 // This converts the error to the type OpErrCode.
+// The returned AddOp has no state: it can be created once and reused concurrently.
+// Adding the same operation to an OpErrCode that already has it returns the OpErrCode unchanged.
 //
 //	op := errcode.Op("path.move.x")
 //	if start < obstable && obstacle < end  {
@@ -102,6 +162,19 @@ func Op(operation string) AddOp {
 		if err == nil {
 			panic("Op error is nil")
 		}
+		if existing, ok := err.(OpErrCode); ok && existing.Operation == operation {
+			return existing
+		}
 		return OpErrCode{Operation: operation, Err: err}
 	}
 }
+
+// prefixOnce prefixes the annotation to the message.
+// The prefix is not added when the inner error already has the same annotation as its prefix.
+func prefixOnce(annotation string, msg string, innerHasAnnotation bool) string {
+	prefix := annotation + ": "
+	if innerHasAnnotation && strings.HasPrefix(msg, prefix) {
+		return msg
+	}
+	return prefix + msg
+}
@@ -0,0 +1,176 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import "sync/atomic"
+
+var serviceName atomic.Pointer[string]
+
+// SetServiceName sets the name of this service or component.
+// It is the Origin of a JSONFormat when the error does not have an origin:
+// an error received from another service keeps the origin of the service that produced it.
+// This should be called during program initialization.
+func SetServiceName(name string) {
+	serviceName.Store(&name)
+}
+
+// ServiceName gives the name set with SetServiceName.
+// Otherwise it will return the zero value (empty) string.
+func ServiceName() string {
+	if name := serviceName.Load(); name != nil {
+		return *name
+	}
+	return ""
+}
+
+// HasOrigin retrieves the service or component that produced an error.
+// It is given to clients as the Origin of a JSONFormat
+// so that when an error propagates across services it can be seen which one produced the code.
+//
+// The origin should be retrieved with [Origin].
+// An origin is normally attached with [WithOrigin] or given by [SetServiceName].
+type HasOrigin interface {
+	GetOrigin() string
+}
+
+// Origin will return an origin if it exists.
+// It checks recursively for the [HasOrigin] interface, traversing groups as ClientData does.
+// Otherwise it will return the zero value (empty) string:
+// the origin is then the ServiceName when the error is formatted.
+func Origin(v interface{}) string {
+	var origin string
+	check := func(v interface{}) bool {
+		if hasOrigin, ok := v.(HasOrigin); ok {
+			origin = hasOrigin.GetOrigin()
+		}
+		return origin != ""
+	}
+	if err, ok := v.(error); ok {
+		walkDeep(err, func(err error) bool { return check(err) })
+	} else {
+		check(v)
+	}
+	return origin
+}
+
+// OriginErrCode is an ErrorCode with an Origin field attached.
+// It is constructed by [WithOrigin].
+type OriginErrCode struct {
+	Origin string
+	Err    ErrorCode
+}
+
+// WithOrigin attaches the service or component that produced the error to an ErrorCode.
+// This is for a component within a service, since the service itself is given by SetServiceName.
+// Returns nil if err is nil.
+func WithOrigin(origin string, err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	return OriginErrCode{Origin: origin, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e OriginErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e OriginErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// GetOrigin satisfies the [HasOrigin] interface.
+func (e OriginErrCode) GetOrigin() string {
+	return e.Origin
+}
+
+// Code returns the underlying Code of Err.
+func (e OriginErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e OriginErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*OriginErrCode)(nil)   // assert implements interface
+var _ HasOrigin = (*OriginErrCode)(nil)   // assert implements interface
+var _ unwrapError = (*OriginErrCode)(nil) // assert implements interface
+
+// remoteError is an error received from another service.
+type remoteError interface {
+	isRemote()
+}
+
+// RemoteErrCode is an ErrorCode converted from an error received from another service.
+// It is constructed by [WithRemote].
+type RemoteErrCode struct {
+	Err ErrorCode
+}
+
+// WithRemote marks an ErrorCode converted from an error received from another service,
+// for example from the status code of a response.
+// ServiceName is not given as the Origin of a remote error: it was produced by the other service.
+// A RemoteErr does not need to be marked.
+// Returns nil if err is nil.
+func WithRemote(err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	return RemoteErrCode{Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e RemoteErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e RemoteErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// Code returns the underlying Code of Err.
+func (e RemoteErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e RemoteErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+func (e RemoteErrCode) isRemote() {}
+
+var _ ErrorCode = (*RemoteErrCode)(nil)   // assert implements interface
+var _ unwrapError = (*RemoteErrCode)(nil) // assert implements interface
+var _ remoteError = (*RemoteErrCode)(nil) // assert implements interface
+
+// originOf gives the Origin of a JSONFormat.
+// ServiceName is given for an error produced by this service: one without a remote error.
+func originOf(errCode ErrorCode) string {
+	if origin := Origin(errCode); origin != "" {
+		return origin
+	}
+	isRemote := walkDeep(errCode, func(err error) bool {
+		_, ok := err.(remoteError)
+		return ok
+	})
+	if isRemote {
+		return ""
+	}
+	return ServiceName()
+}
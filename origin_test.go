@@ -0,0 +1,66 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestOrigin(t *testing.T) {
+	defer errcode.SetServiceName("")
+	notFound := errcode.NewNotFoundErr(fmt.Errorf("no invoice"))
+	if origin := errcode.NewJSONFormat(notFound).Origin; origin != "" {
+		t.Errorf("expected no origin by default, got %q", origin)
+	}
+
+	errcode.SetServiceName("orders")
+	if origin := errcode.NewJSONFormat(notFound).Origin; origin != "orders" {
+		t.Errorf("expected the service name, got %q", origin)
+	}
+	withOrigin := errcode.WithOrigin("orders.payments", notFound)
+	if origin := errcode.NewJSONFormat(withOrigin).Origin; origin != "orders.payments" {
+		t.Errorf("expected the attached origin, got %q", origin)
+	}
+	if origin := errcode.Origin(errcode.Combine(errcode.NewInvalidInputErr(fmt.Errorf("bad")), withOrigin)); origin != "orders.payments" {
+		t.Errorf("expected the origin of the group member, got %q", origin)
+	}
+	if withOrigin.Code() != errcode.NotFoundCode || errcode.WithOrigin("orders", nil) != nil {
+		t.Error("expected WithOrigin to keep the code and give nil for nil")
+	}
+
+	// an error from another hop keeps the origin of the service that produced it
+	errcode.SetServiceName("billing")
+	bytes, err := json.Marshal(errcode.NewJSONFormat(notFound))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var format errcode.JSONFormat
+	if err := json.Unmarshal(bytes, &format); err != nil {
+		t.Fatal(err)
+	}
+	errcode.SetServiceName("gateway")
+	remote := errcode.NewRemoteErr(format, nil)
+	if origin := errcode.Origin(remote); origin != "billing" {
+		t.Errorf("expected the origin of the remote error, got %q", origin)
+	}
+	if origin := errcode.NewJSONFormat(remote).Origin; origin != "billing" {
+		t.Errorf("expected the origin of the remote error in the JSONFormat, got %q", origin)
+	}
+
+	// a remote error without an origin is not attributed to this service
+	for _, errCode := range []errcode.ErrorCode{
+		errcode.NewRemoteErr(errcode.JSONFormat{Code: errcode.NotFoundCode.CodeStr(), Msg: "no invoice"}, nil),
+		errcode.WithRemote(errcode.FromHTTPStatus(404, nil)),
+		errcode.Op("invoices.get")(errcode.WithRemote(errcode.FromHTTPStatus(404, nil))),
+		errcode.Combine(errcode.WithRemote(errcode.FromHTTPStatus(404, nil)), notFound),
+	} {
+		if origin := errcode.NewJSONFormat(errCode).Origin; origin != "" {
+			t.Errorf("expected no origin for a remote error, got %q", origin)
+		}
+	}
+	if errcode.WithRemote(nil) != nil {
+		t.Error("expected nil for nil")
+	}
+}
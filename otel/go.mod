@@ -0,0 +1,29 @@
+module github.com/gregwebs/errcode/otel
+
+go 1.21.9
+
+require (
+	github.com/gregwebs/errcode v0.11.0
+	github.com/gregwebs/errcode/grpc v0.11.0
+	github.com/gregwebs/errors v1.5.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
+	google.golang.org/grpc v1.58.2 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+)
+
+replace github.com/gregwebs/errcode => ../
+
+replace github.com/gregwebs/errcode/grpc => ../grpc
@@ -0,0 +1,60 @@
+// Package otel converts error codes to OpenTelemetry semantic convention attributes.
+//
+// The attributes are derived from the code and its metadata:
+//
+//   - error.type is the CodeStr
+//   - http.response.status_code is the HTTP code set with SetHTTP (on the code or an ancestor)
+//   - rpc.grpc.status_code is the GRPC code from the grpc package
+//
+// This allows traces to conform to the semantic conventions without per-service attribute plumbing.
+package otel
+
+import (
+	"fmt"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/grpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes gives the semantic convention attributes for an error.
+// The ErrorCode is found with CodeChain.
+// If there is no ErrorCode, error.type is set to the type of the error as recommended by the semantic conventions.
+// A nil error gives no attributes.
+func Attributes(err error) []attribute.KeyValue {
+	if err == nil {
+		return nil
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		return []attribute.KeyValue{semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err))}
+	}
+	return CodeAttributes(errCode.Code())
+}
+
+// CodeAttributes gives the semantic convention attributes for a code.
+// http.response.status_code is only given if an HTTP code is set for the code or its ancestors.
+func CodeAttributes(code errcode.Code) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.ErrorTypeKey.String(code.CodeStr().String())}
+	if httpCode := errcode.HTTPCode(code); httpCode != nil {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(*httpCode))
+	}
+	attrs = append(attrs, semconv.RPCGRPCStatusCodeKey.Int(int(grpc.GetCode(code))))
+	return attrs
+}
+
+// RecordError records the error on the span with the attributes from Attributes.
+// The attributes are also set on the span and the span status is set to Error.
+// Nothing is done for a nil error.
+func RecordError(span trace.Span, err error, options ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	attrs := Attributes(err)
+	span.SetAttributes(attrs...)
+	span.RecordError(err, append(options, trace.WithAttributes(attrs...))...)
+	span.SetStatus(codes.Error, err.Error())
+}
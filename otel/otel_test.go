@@ -0,0 +1,70 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	errotel "github.com/gregwebs/errcode/otel"
+	"github.com/gregwebs/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestAttributes(t *testing.T) {
+	if attrs := errotel.Attributes(nil); attrs != nil {
+		t.Errorf("expected no attributes, got %v", attrs)
+	}
+
+	attrs := attribute.NewSet(errotel.Attributes(errors.Wrap(errcode.NewNotFoundErr(errors.New("missing")), "wrap"))...)
+	assertAttr(t, attrs, "error.type", attribute.StringValue("missing"))
+	assertAttr(t, attrs, "http.response.status_code", attribute.IntValue(404))
+	assertAttr(t, attrs, "rpc.grpc.status_code", attribute.IntValue(5))
+
+	attrs = attribute.NewSet(errotel.Attributes(errors.New("uncoded"))...)
+	assertAttr(t, attrs, "error.type", attribute.StringValue("*errors.fundamental"))
+	if attrs.Len() != 1 {
+		t.Errorf("expected only error.type, got %v", attrs)
+	}
+
+	attrs = attribute.NewSet(errotel.CodeAttributes(errcode.AuthCode)...)
+	if _, ok := attrs.Value("http.response.status_code"); ok {
+		t.Errorf("expected no HTTP code for %v", errcode.AuthCode)
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	errotel.RecordError(span, errcode.NewInternalErr(errors.New("boom")))
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", spans[0].Status())
+	}
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	assertAttr(t, attrs, "error.type", attribute.StringValue("internal"))
+	assertAttr(t, attrs, "http.response.status_code", attribute.IntValue(500))
+	if len(spans[0].Events()) != 1 {
+		t.Errorf("expected an exception event")
+	}
+}
+
+func assertAttr(t *testing.T, attrs attribute.Set, key attribute.Key, expected attribute.Value) {
+	t.Helper()
+	value, ok := attrs.Value(key)
+	if !ok {
+		t.Errorf("missing attribute %s", key)
+		return
+	}
+	if value != expected {
+		t.Errorf("attribute %s expected %v, got %v", key, expected.Emit(), value.Emit())
+	}
+}
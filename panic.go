@@ -0,0 +1,98 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// PanicErr is an internal error converted from a recovered panic by RecoverToErrorCode.
+// Value is the recovered panic value.
+// It is kept for diagnostics such as logging: it is not part of the ClientData.
+type PanicErr struct {
+	StackCode
+	Value interface{}
+}
+
+// RecoverToErrorCode converts the value from recover() to a PanicErr.
+// The stack trace starts at the site of the panic rather than at the recover,
+// so it must be called from the deferred function that recovered.
+// The stack trace is always captured, regardless of the StackPolicy.
+// If the panic value is an error, it is the underlying error and can be found with errors.Is.
+// Returns nil if recovered is nil.
+//
+//	defer func() {
+//		if recovered := recover(); recovered != nil {
+//			err = errcode.RecoverToErrorCode(recovered)
+//		}
+//	}()
+func RecoverToErrorCode(recovered interface{}) ErrorCode {
+	if recovered == nil {
+		return nil
+	}
+	err, ok := recovered.(error)
+	if ok {
+		err = panicError{err: err}
+	} else {
+		err = panicError{err: fmt.Errorf("%v", recovered)}
+	}
+	code := InternalCode
+	if errCode, ok := recovered.(ErrorCode); ok && errCode.Code().IsAncestor(InternalCode) {
+		code = errCode.Code()
+	}
+	return PanicErr{
+		StackCode: StackCode{Err: CodedError{GetCode: code, Err: err}, GetStack: panicStack()},
+		Value:     recovered,
+	}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e PanicErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "PanicErr{StackCode: %#v, Value: %#v}", e.StackCode, e.Value)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// panicError prefixes "panic: " to the panic value.
+type panicError struct {
+	err error
+}
+
+func (e panicError) Error() string {
+	return "panic: " + e.err.Error()
+}
+
+func (e panicError) Unwrap() error {
+	return e.err
+}
+
+// panicStack captures the stack trace below runtime.gopanic, which starts at the site of the panic.
+// If the stack is not unwinding from a panic, the stack starts at the caller of RecoverToErrorCode.
+func panicStack() *pcStack {
+	stack := newPCStack(2)
+	for i, pc := range *stack {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			trimmed := (*stack)[i+1:]
+			return &trimmed
+		}
+	}
+	return stack
+}
+
+var _ ErrorCode = (*PanicErr)(nil)   // assert implements interface
+var _ unwrapError = (*PanicErr)(nil) // assert implements interface
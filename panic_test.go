@@ -0,0 +1,53 @@
+package errcode_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func panicSite(value interface{}) {
+	panic(value)
+}
+
+func recoverPanic(value interface{}) (err errcode.ErrorCode) {
+	defer func() {
+		err = errcode.RecoverToErrorCode(recover())
+	}()
+	panicSite(value)
+	return nil
+}
+
+func TestRecoverToErrorCode(t *testing.T) {
+	if errcode.RecoverToErrorCode(nil) != nil {
+		t.Error("expected nil")
+	}
+
+	err := recoverPanic("boom")
+	if err.Code() != errcode.InternalCode {
+		t.Errorf("expected the internal code, got %v", err.Code().CodeStr())
+	}
+	ErrorEquals(t, err, "panic: boom")
+	if panicErr, ok := err.(errcode.PanicErr); !ok || panicErr.Value != "boom" {
+		t.Errorf("expected the panic value to be kept, got %#v", err)
+	}
+	if data := errcode.ClientData(err); data != nil {
+		t.Errorf("expected no client data, got %v", data)
+	}
+	stack := errcode.StackTrace(err)
+	if len(stack) == 0 {
+		t.Fatal("expected a stack trace")
+	}
+	if top := fmt.Sprintf("%n", stack[0]); top != "panicSite" {
+		t.Errorf("expected the stack to start at the panic site, got %s", top)
+	}
+
+	err = recoverPanic(errors.Wrap(io.ErrUnexpectedEOF, "read"))
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.HasPrefix(err.Error(), "panic: read") {
+		t.Errorf("expected the panic error to be wrapped, got %v", err)
+	}
+}
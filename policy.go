@@ -0,0 +1,63 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+// CodePolicy chooses the code that represents a group of errors.
+// It is given the code of each error and gives the index of the chosen code.
+// The codes are never empty.
+type CodePolicy func(codes []Code) int
+
+// WorstHTTPStatus is a CodePolicy that chooses the code with the highest HTTP status (see Code.HTTPCode).
+// A server error is chosen over a client error.
+// When there is a tie the first code is chosen.
+func WorstHTTPStatus(codes []Code) int {
+	worst := 0
+	for i, code := range codes[1:] {
+		if code.HTTPCode() > codes[worst].HTTPCode() {
+			worst = i + 1
+		}
+	}
+	return worst
+}
+
+// FirstServerError is a CodePolicy that chooses the first code that is not a client error:
+// a code without a 4xx HTTP status (see Code.HTTPCode).
+// If every code is a client error, the first code is chosen.
+func FirstServerError(codes []Code) int {
+	for i, code := range codes {
+		if status := code.HTTPCode(); status < 400 || status >= 500 {
+			return i
+		}
+	}
+	return 0
+}
+
+// MostSpecificCode is a CodePolicy that chooses the code with the most ancestors.
+// When there is a tie the first code is chosen.
+func MostSpecificCode(codes []Code) int {
+	depth := func(code Code) int {
+		n := 0
+		for parent := code.Parent; parent != nil; parent = parent.Parent {
+			n++
+		}
+		return n
+	}
+	chosen, chosenDepth := 0, depth(codes[0])
+	for i, code := range codes[1:] {
+		if d := depth(code); d > chosenDepth {
+			chosen, chosenDepth = i+1, d
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,101 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+// The predicates in this file check every ErrorCode in an error, as HasAncestor does:
+// the error is unwrapped, including groups, and classified when no ErrorCode is found.
+// An error without an ErrorCode does not satisfy any of them.
+
+// IsClientError checks if the error has a code with a 4xx HTTP code.
+// Note that a code without an HTTP code defaults to 400 (see Code.HTTPCode).
+func IsClientError(err error) bool {
+	return anyCode(err, func(code Code) bool {
+		httpCode := code.HTTPCode()
+		return httpCode >= 400 && httpCode < 500
+	})
+}
+
+// IsServerError checks if the error has a code with a 5xx HTTP code.
+func IsServerError(err error) bool {
+	return anyCode(err, func(code Code) bool {
+		httpCode := code.HTTPCode()
+		return httpCode >= 500 && httpCode < 600
+	})
+}
+
+// IsNotFound checks if the error has NotFoundCode or a descendant.
+func IsNotFound(err error) bool {
+	return HasAncestor(err, NotFoundCode)
+}
+
+// IsConflict checks if the error has ConflictCode or AlreadyExistsCode or a descendant of them.
+func IsConflict(err error) bool {
+	return HasAncestor(err, ConflictCode) || HasAncestor(err, AlreadyExistsCode)
+}
+
+// IsPreconditionFailed checks if the error has PreconditionFailedCode or a descendant.
+func IsPreconditionFailed(err error) bool {
+	return HasAncestor(err, PreconditionFailedCode)
+}
+
+// IsInvalidInput checks if the error has InvalidInputCode or a descendant.
+func IsInvalidInput(err error) bool {
+	return HasAncestor(err, InvalidInputCode)
+}
+
+// IsState checks if the error has StateCode or a descendant.
+func IsState(err error) bool {
+	return HasAncestor(err, StateCode)
+}
+
+// IsUnauthenticated checks if the error has NotAuthenticatedCode or a descendant.
+func IsUnauthenticated(err error) bool {
+	return HasAncestor(err, NotAuthenticatedCode)
+}
+
+// IsForbidden checks if the error has ForbiddenCode or a descendant.
+func IsForbidden(err error) bool {
+	return HasAncestor(err, ForbiddenCode)
+}
+
+// IsInternal checks if the error has InternalCode or a descendant.
+func IsInternal(err error) bool {
+	return HasAncestor(err, InternalCode)
+}
+
+// IsUnimplemented checks if the error has UnimplementedCode or a descendant.
+func IsUnimplemented(err error) bool {
+	return HasAncestor(err, UnimplementedCode)
+}
+
+// IsUnavailable checks if the error has UnavailableCode or a descendant.
+func IsUnavailable(err error) bool {
+	return HasAncestor(err, UnavailableCode)
+}
+
+// IsTimeout checks if the error has TimeoutCode or a descendant.
+func IsTimeout(err error) bool {
+	return HasAncestor(err, TimeoutCode)
+}
+
+// IsQuota checks if the error has QuotaCode or a descendant.
+func IsQuota(err error) bool {
+	return HasAncestor(err, QuotaCode)
+}
+
+// IsCanceled checks if the error has CanceledCode or a descendant.
+// A context.Canceled error is given CanceledCode by FromContextError.
+func IsCanceled(err error) bool {
+	return HasAncestor(err, CanceledCode)
+}
@@ -0,0 +1,53 @@
+package errcode_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestPredicates(t *testing.T) {
+	notFound := errors.Wrap(errcode.NewNotFoundErr(errors.New("no item")), "get")
+	exists := errcode.NewAlreadyExistsErr(errors.New("exists"))
+	unavailable := errcode.NewUnavailableErr(errors.New("down"))
+	uncoded := errors.New("uncoded")
+	// the predicates check every member of a group, not just the code of the group
+	grouped := errcode.CombineAll(unavailable, notFound)
+	joined := stderrors.Join(uncoded, exists)
+
+	for _, test := range []struct {
+		name      string
+		predicate func(error) bool
+		yes       []error
+		no        []error
+	}{
+		{"IsClientError", errcode.IsClientError, []error{notFound, exists, MinimalError{}, grouped}, []error{unavailable, uncoded, nil}},
+		{"IsServerError", errcode.IsServerError, []error{unavailable, InternalChild{}, grouped}, []error{notFound, uncoded, nil}},
+		{"IsNotFound", errcode.IsNotFound, []error{notFound, errcode.NewGoneErr(uncoded), grouped}, []error{exists, uncoded, nil}},
+		{"IsConflict", errcode.IsConflict, []error{exists, errcode.NewConflictErr(uncoded), joined}, []error{notFound, grouped, errcode.NewPreconditionFailedErr(uncoded)}},
+		{"IsPreconditionFailed", errcode.IsPreconditionFailed, []error{errcode.NewPreconditionFailedErr(uncoded)}, []error{exists}},
+		{"IsState", errcode.IsState, []error{exists, errcode.NewPaymentRequiredErr(uncoded)}, []error{notFound}},
+		{"IsInvalidInput", errcode.IsInvalidInput, []error{MinimalError{}, DeepError{}}, []error{exists}},
+		{"IsUnauthenticated", errcode.IsUnauthenticated, []error{errcode.NewNotAuthenticatedErr(uncoded)}, []error{errcode.NewForbiddenErr(uncoded)}},
+		{"IsForbidden", errcode.IsForbidden, []error{errcode.NewForbiddenErr(uncoded)}, []error{errcode.NewNotAuthenticatedErr(uncoded)}},
+		{"IsInternal", errcode.IsInternal, []error{unavailable, errcode.NewInternalErr(uncoded)}, []error{notFound}},
+		{"IsUnavailable", errcode.IsUnavailable, []error{unavailable}, []error{errcode.NewInternalErr(uncoded)}},
+		{"IsUnimplemented", errcode.IsUnimplemented, []error{errcode.NewUnimplementedErr(uncoded)}, []error{unavailable}},
+		{"IsTimeout", errcode.IsTimeout, []error{errcode.NewTimeoutGatewayErr(uncoded), errors.Wrap(context.DeadlineExceeded, "wait")}, []error{unavailable, context.Canceled}},
+		{"IsCanceled", errcode.IsCanceled, []error{errors.Wrap(context.Canceled, "wait")}, []error{context.DeadlineExceeded, uncoded}},
+	} {
+		for _, err := range test.yes {
+			if !test.predicate(err) {
+				t.Errorf("%s expected true for %v", test.name, err)
+			}
+		}
+		for _, err := range test.no {
+			if test.predicate(err) {
+				t.Errorf("%s expected false for %v", test.name, err)
+			}
+		}
+	}
+}
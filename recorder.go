@@ -0,0 +1,152 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorOccurrence is a record of an error that was returned to a client.
+// Source describes where the error occurred, for example the HTTP method and path.
+type ErrorOccurrence struct {
+	Time      time.Time `json:"time"`
+	Code      CodeStr   `json:"code"`
+	HTTP      int       `json:"http"`
+	Msg       string    `json:"msg"`
+	UserMsg   string    `json:"user_msg,omitempty"`
+	Operation string    `json:"operation,omitempty"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// NewErrorOccurrence records an ErrorCode at the current time.
+func NewErrorOccurrence(errCode ErrorCode, source string) ErrorOccurrence {
+	code := errCode.Code()
+	return ErrorOccurrence{
+		Time:      time.Now(),
+		Code:      code.CodeStr(),
+		HTTP:      code.HTTPCode(),
+		Msg:       errCode.Error(),
+		UserMsg:   GetUserMsg(errCode),
+		Operation: Operation(errCode),
+		Source:    source,
+	}
+}
+
+// Recorder persists error occurrences.
+// The httperr package and the web framework adapters store every error they write
+// in the Recorder set with SetRecorder, or in a Recorder given with the RecordTo option of httperr.NewHandler.
+// Store is called while handling a request and should not block.
+type Recorder interface {
+	Store(ErrorOccurrence)
+}
+
+var recorder atomic.Pointer[Recorder]
+
+// SetRecorder sets the Recorder given every error by Record.
+// A nil Recorder turns off the recording.
+// This should be called during program initialization.
+func SetRecorder(r Recorder) {
+	if r == nil {
+		recorder.Store(nil)
+		return
+	}
+	recorder.Store(&r)
+}
+
+// Record stores an occurrence of the ErrorCode in the Recorder set with SetRecorder.
+// It does nothing when there is no Recorder.
+// This is called where errors are written to clients, for example by httperr.Write.
+func Record(errCode ErrorCode, source string) {
+	if r := recorder.Load(); r != nil && errCode != nil {
+		(*r).Store(NewErrorOccurrence(errCode, source))
+	}
+}
+
+// RingRecorder is a Recorder that keeps the most recent occurrences in memory.
+// It also counts every occurrence by code.
+// It is intended as a lightweight error dashboard for small services.
+// It is safe for concurrent use.
+type RingRecorder struct {
+	mu     sync.Mutex
+	ring   []ErrorOccurrence
+	next   int
+	full   bool
+	counts map[CodeStr]int
+}
+
+var _ Recorder = (*RingRecorder)(nil) // assert implements interface
+
+// NewRingRecorder creates a RingRecorder that keeps the given number of occurrences.
+func NewRingRecorder(size int) *RingRecorder {
+	if size < 1 {
+		size = 1
+	}
+	return &RingRecorder{
+		ring:   make([]ErrorOccurrence, size),
+		counts: make(map[CodeStr]int),
+	}
+}
+
+// Store adds an occurrence, replacing the oldest one when full.
+func (r *RingRecorder) Store(occurrence ErrorOccurrence) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ring[r.next] = occurrence
+	r.next = (r.next + 1) % len(r.ring)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.counts[occurrence.Code]++
+}
+
+// Recent gives the kept occurrences, newest first.
+// If codes are given, only occurrences with one of those codes or their descendants are included.
+func (r *RingRecorder) Recent(codes ...CodeStr) []ErrorOccurrence {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.ring)
+	}
+	recent := make([]ErrorOccurrence, 0, n)
+	for i := 1; i <= n; i++ {
+		occurrence := r.ring[(r.next-i+len(r.ring))%len(r.ring)]
+		if len(codes) == 0 || codeStrMatches(occurrence.Code, codes) {
+			recent = append(recent, occurrence)
+		}
+	}
+	return recent
+}
+
+// Counts gives the number of occurrences of each code since the RingRecorder was created.
+func (r *RingRecorder) Counts() map[CodeStr]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[CodeStr]int, len(r.counts))
+	for code, count := range r.counts {
+		counts[code] = count
+	}
+	return counts
+}
+
+func codeStrMatches(codeStr CodeStr, codes []CodeStr) bool {
+	for _, code := range codes {
+		if codeStr == code || len(codeStr) > len(code) && codeStr[:len(code)+1] == code+"." {
+			return true
+		}
+	}
+	return false
+}
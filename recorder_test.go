@@ -0,0 +1,54 @@
+package errcode_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestRingRecorder(t *testing.T) {
+	recorder := errcode.NewRingRecorder(2)
+	if recent := recorder.Recent(); len(recent) != 0 {
+		t.Errorf("expected no occurrences, got %v", recent)
+	}
+	for _, errCode := range []errcode.ErrorCode{
+		errcode.NewNotFoundErr(errors.New("first")),
+		errcode.NewGoneErr(errors.New("second")),
+		errcode.NewConflictErr(errors.New("third")),
+	} {
+		recorder.Store(errcode.NewErrorOccurrence(errCode, "GET /"))
+	}
+
+	var msgs []string
+	for _, occurrence := range recorder.Recent() {
+		msgs = append(msgs, occurrence.Msg)
+	}
+	if !reflect.DeepEqual(msgs, []string{"third", "second"}) {
+		t.Errorf("unexpected recent occurrences %v", msgs)
+	}
+	missing := recorder.Recent(errcode.NotFoundCode.CodeStr())
+	if len(missing) != 1 || missing[0].Code != "missing.gone" || missing[0].HTTP != 410 || missing[0].Source != "GET /" {
+		t.Errorf("unexpected occurrences %v", missing)
+	}
+	expected := map[errcode.CodeStr]int{"missing": 1, "missing.gone": 1, "state.conflict": 1}
+	if counts := recorder.Counts(); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestSetRecorder(t *testing.T) {
+	errCode := errcode.NewNotFoundErr(errors.New("no item"))
+	errcode.Record(errCode, "GET /")
+
+	recorder := errcode.NewRingRecorder(2)
+	errcode.SetRecorder(recorder)
+	defer errcode.SetRecorder(nil)
+	errcode.Record(errCode, "GET /item")
+	errcode.Record(nil, "GET /item")
+	recent := recorder.Recent()
+	if len(recent) != 1 || recent[0].Source != "GET /item" || recent[0].Code != "missing" {
+		t.Errorf("unexpected occurrences %v", recent)
+	}
+}
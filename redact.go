@@ -0,0 +1,237 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Redactor removes private information from the client data of an error.
+// It is set centrally with SetRedactor so that secrets or PII are not accidentally given to clients.
+// Redact must not modify the given data: it returns a redacted copy or else the data unchanged.
+type Redactor interface {
+	Redact(data interface{}) interface{}
+}
+
+// RedactorFunc is a function that satisfies the [Redactor] interface.
+type RedactorFunc func(data interface{}) interface{}
+
+// Redact satisfies the [Redactor] interface.
+func (f RedactorFunc) Redact(data interface{}) interface{} {
+	return f(data)
+}
+
+var clientDataRedactor atomic.Pointer[Redactor]
+
+// SetRedactor sets the Redactor that is applied by ClientData, and therefore by NewJSONFormat.
+// A nil Redactor turns off redaction.
+//
+//	errcode.SetRedactor(errcode.NewFieldRedactor("password", "token", "ssn"))
+func SetRedactor(redactor Redactor) {
+	if redactor == nil {
+		clientDataRedactor.Store(nil)
+		return
+	}
+	clientDataRedactor.Store(&redactor)
+}
+
+func redact(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	if redactor := clientDataRedactor.Load(); redactor != nil {
+		return (*redactor).Redact(data)
+	}
+	return data
+}
+
+// maxRedactDepth stops redacting data that is deeply nested or has a reference cycle.
+const maxRedactDepth = 32
+
+// FieldRedactor is a Redactor that redacts struct fields and map entries.
+// A struct field is set to its zero value if it is tagged `errcode:"private"`
+// or if its name or JSON name matches one of the key patterns.
+// Use the omitempty JSON option to leave a redacted field out of the JSON.
+// A map entry with a key that matches a key pattern is removed.
+// Redaction is applied recursively through pointers, interfaces, slices, arrays, structs, and maps.
+//
+//	type Account struct {
+//		Name     string
+//		Password string `json:"password,omitempty" errcode:"private"`
+//	}
+type FieldRedactor struct {
+	keys []*regexp.Regexp
+}
+
+// NewFieldRedactor creates a FieldRedactor.
+// The key patterns are regular expressions that are matched case-insensitively.
+// Panic if a pattern does not compile.
+func NewFieldRedactor(keyPatterns ...string) *FieldRedactor {
+	keys := make([]*regexp.Regexp, len(keyPatterns))
+	for i, pattern := range keyPatterns {
+		keys[i] = regexp.MustCompile("(?i)" + pattern)
+	}
+	return &FieldRedactor{keys: keys}
+}
+
+// Redact satisfies the [Redactor] interface.
+func (r *FieldRedactor) Redact(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	if redacted, changed := r.redactValue(reflect.ValueOf(data), 0); changed {
+		return redacted.Interface()
+	}
+	return data
+}
+
+func (r *FieldRedactor) matchKey(key string) bool {
+	for _, re := range r.keys {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FieldRedactor) privateField(field reflect.StructField) bool {
+	if tag, _, _ := strings.Cut(field.Tag.Get("errcode"), ","); tag == "private" {
+		return true
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" && r.matchKey(name) {
+		return true
+	}
+	return r.matchKey(field.Name)
+}
+
+// redactValue gives a redacted copy of the value if anything was redacted.
+func (r *FieldRedactor) redactValue(v reflect.Value, depth int) (reflect.Value, bool) {
+	if depth > maxRedactDepth {
+		return v, false
+	}
+	depth++
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := r.redactValue(v.Elem(), depth)
+		if !changed {
+			return v, false
+		}
+		if v.Kind() == reflect.Pointer {
+			ptr := reflect.New(elem.Type())
+			ptr.Elem().Set(elem)
+			return ptr, true
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(elem)
+		return out, true
+	case reflect.Struct:
+		return r.redactStruct(v, depth)
+	case reflect.Map:
+		return r.redactMap(v, depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, false
+		}
+		var out reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			elem, changed := r.redactValue(v.Index(i), depth)
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = copyIndexable(v)
+			}
+			out.Index(i).Set(elem)
+		}
+		if out.IsValid() {
+			return out, true
+		}
+	}
+	return v, false
+}
+
+func copyIndexable(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Slice {
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(out, v)
+		return out
+	}
+	out := reflect.New(v.Type()).Elem()
+	out.Set(v)
+	return out
+}
+
+func (r *FieldRedactor) redactStruct(v reflect.Value, depth int) (reflect.Value, bool) {
+	var out reflect.Value
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := v.Field(i)
+		var redacted reflect.Value
+		if r.privateField(field) {
+			if fieldValue.IsZero() {
+				continue
+			}
+			redacted = reflect.Zero(field.Type)
+		} else {
+			var changed bool
+			if redacted, changed = r.redactValue(fieldValue, depth); !changed {
+				continue
+			}
+		}
+		if !out.IsValid() {
+			out = reflect.New(t).Elem()
+			out.Set(v)
+		}
+		out.Field(i).Set(redacted)
+	}
+	if out.IsValid() {
+		return out, true
+	}
+	return v, false
+}
+
+func (r *FieldRedactor) redactMap(v reflect.Value, depth int) (reflect.Value, bool) {
+	if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+		return v, false
+	}
+	out := reflect.MakeMapWithSize(v.Type(), v.Len())
+	changed := false
+	iter := v.MapRange()
+	for iter.Next() {
+		if r.matchKey(iter.Key().String()) {
+			changed = true
+			continue
+		}
+		value, valueChanged := r.redactValue(iter.Value(), depth)
+		changed = changed || valueChanged
+		out.SetMapIndex(iter.Key(), value)
+	}
+	if changed {
+		return out, true
+	}
+	return v, false
+}
+
+var _ Redactor = (*FieldRedactor)(nil) // assert implements interface
+var _ Redactor = RedactorFunc(nil)     // assert implements interface
@@ -0,0 +1,66 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+type redactAccount struct {
+	Name     string            `json:"name"`
+	Password string            `json:"password,omitempty" errcode:"private"`
+	APIToken string            `json:"api_token,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Members  []*redactAccount  `json:"members,omitempty"`
+}
+
+type redactErr struct {
+	errcode.CodedError
+	Account redactAccount
+}
+
+func (e redactErr) GetClientData() interface{} {
+	return e.Account
+}
+
+func TestFieldRedactor(t *testing.T) {
+	account := redactAccount{
+		Name:     "acme",
+		Password: "hunter2",
+		APIToken: "abc",
+		Labels:   map[string]string{"team": "billing", "secret_key": "xyz"},
+		Members:  []*redactAccount{{Name: "bob", Password: "pw"}},
+	}
+	err := redactErr{
+		CodedError: errcode.NewCodedError(errors.New("account locked"), errcode.ForbiddenCode),
+		Account:    account,
+	}
+
+	errcode.SetRedactor(errcode.NewFieldRedactor("token", "secret"))
+	defer errcode.SetRedactor(nil)
+
+	body, jsonErr := json.Marshal(errcode.NewJSONFormat(errcode.Op("accounts.get")(err)).Data)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	expected := `{"name":"acme","labels":{"team":"billing"},"members":[{"name":"bob"}]}`
+	if string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+	if account.Password != "hunter2" || account.Labels["secret_key"] != "xyz" || account.Members[0].Password != "pw" {
+		t.Error("expected the original data to be unchanged")
+	}
+
+	plain := map[string]int{"count": 1}
+	redactor := errcode.NewFieldRedactor("token")
+	if redacted := redactor.Redact(plain).(map[string]int); redacted["count"] != 1 {
+		t.Errorf("unexpected redaction %v", redacted)
+	}
+
+	errcode.SetRedactor(nil)
+	if data := errcode.ClientData(err).(redactAccount); data.Password != "hunter2" {
+		t.Error("expected no redaction without a Redactor")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry holds codes so that they can be enumerated and looked up.
+// This is used for generating documentation.
+// It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	byStr map[CodeStr]Code
+	// byNormalized and aliases are keyed by a normalized CodeStr for Find
+	byNormalized map[CodeStr]Code
+	aliases      map[CodeStr]Code
+}
+
+// NewRegistry creates an empty Registry.
+// Most applications should use DefaultRegistry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byStr:        make(map[CodeStr]Code),
+		byNormalized: make(map[CodeStr]Code),
+		aliases:      make(map[CodeStr]Code),
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry gives the Registry that every code created with NewCode or Child is registered in.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds codes to the Registry.
+// If a code with the same CodeStr is already registered, the existing code is kept.
+func (r *Registry) Register(codes ...Code) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, code := range codes {
+		codeStr := code.CodeStr()
+		if _, ok := r.byStr[codeStr]; !ok {
+			r.byStr[codeStr] = code
+		}
+		if _, ok := r.byNormalized[codeStr.Normalize()]; !ok {
+			r.byNormalized[codeStr.Normalize()] = code
+		}
+	}
+}
+
+// AddAlias makes Find give the code for the alias, for example an old name of the code or the name used by another system.
+// Aliases are case-insensitive as with Find.
+// It is an error if the alias is already an alias of a different code or the CodeStr of a different code.
+func (r *Registry) AddAlias(alias CodeStr, code Code) error {
+	normalized := alias.Normalize()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.byNormalized[normalized]; ok && existing.CodeStr() != code.CodeStr() {
+		return fmt.Errorf("alias %s is the code %s", alias, existing.CodeStr())
+	}
+	if existing, ok := r.aliases[normalized]; ok && existing.CodeStr() != code.CodeStr() {
+		return fmt.Errorf("alias %s is already an alias of the code %s", alias, existing.CodeStr())
+	}
+	r.aliases[normalized] = code
+	return nil
+}
+
+// Find finds a registered code by its CodeStr like Lookup,
+// but it also ignores case and surrounding space (see CodeStr.Normalize) and resolves aliases added with AddAlias.
+// This is for codes from other systems, such as the code of a remote error.
+func (r *Registry) Find(codeStr CodeStr) (Code, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if code, ok := r.byStr[codeStr]; ok {
+		return code, true
+	}
+	normalized := codeStr.Normalize()
+	if code, ok := r.byNormalized[normalized]; ok {
+		return code, true
+	}
+	code, ok := r.aliases[normalized]
+	return code, ok
+}
+
+// Lookup finds a registered code by its CodeStr.
+func (r *Registry) Lookup(codeStr CodeStr) (Code, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	code, ok := r.byStr[codeStr]
+	return code, ok
+}
+
+// Codes gives all the registered codes sorted by CodeStr.
+// A parent code sorts before its children.
+func (r *Registry) Codes() []Code {
+	r.mu.RLock()
+	codes := make([]Code, 0, len(r.byStr))
+	for _, code := range r.byStr {
+		codes = append(codes, code)
+	}
+	r.mu.RUnlock()
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].CodeStr() < codes[j].CodeStr()
+	})
+	return codes
+}
@@ -0,0 +1,120 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import "strings"
+
+// RemoteErr is an ErrorCode reconstructed from a JSONFormat.
+// This is used by integrations that receive errors from another service
+// so that the code, message, and data are propagated.
+// The Err field is the error from the transport, if any.
+type RemoteErr struct {
+	Format JSONFormat
+	Err    error
+}
+
+// NewRemoteErr constructs a RemoteErr.
+// err is the transport error that the JSONFormat was received with and may be nil.
+func NewRemoteErr(format JSONFormat, err error) RemoteErr {
+	return RemoteErr{Format: format, Err: err}
+}
+
+// Code gives a Code from the CodeStr of the JSONFormat.
+func (e RemoteErr) Code() Code {
+	return codeFromStr(e.Format.Code)
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e RemoteErr) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+// Error gives the Msg of the JSONFormat.
+func (e RemoteErr) Error() string {
+	return e.Format.Msg
+}
+
+// Unwrap gives the transport error
+func (e RemoteErr) Unwrap() error {
+	return e.Err
+}
+
+// Errors gives the Others of the JSONFormat.
+// This satisfies the ErrorGroup interface.
+func (e RemoteErr) Errors() []error {
+	if len(e.Format.Others) == 0 {
+		return nil
+	}
+	errs := make([]error, len(e.Format.Others))
+	for i, other := range e.Format.Others {
+		errs[i] = RemoteErr{Format: other}
+	}
+	return errs
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e RemoteErr) GetClientData() interface{} {
+	return e.Format.Data
+}
+
+// GetOperation satisfies the [HasOperation] interface.
+func (e RemoteErr) GetOperation() string {
+	return e.Format.Operation
+}
+
+// GetUserMsg satisfies the [HasUserMsg] interface.
+func (e RemoteErr) GetUserMsg() string {
+	return e.Format.Msg
+}
+
+// GetRequestID satisfies the [HasRequestID] interface.
+func (e RemoteErr) GetRequestID() string {
+	return e.Format.RequestID
+}
+
+// GetOrigin satisfies the [HasOrigin] interface.
+func (e RemoteErr) GetOrigin() string {
+	return e.Format.Origin
+}
+
+// GetLabel satisfies the [HasLabel] interface.
+func (e RemoteErr) GetLabel() string {
+	return e.Format.Label
+}
+
+func (e RemoteErr) isRemote() {}
+
+var _ ErrorCode = (*RemoteErr)(nil)     // assert implements interface
+var _ HasClientData = (*RemoteErr)(nil) // assert implements interface
+var _ HasOperation = (*RemoteErr)(nil)  // assert implements interface
+var _ HasUserMsg = (*RemoteErr)(nil)    // assert implements interface
+var _ HasRequestID = (*RemoteErr)(nil)  // assert implements interface
+var _ HasOrigin = (*RemoteErr)(nil)     // assert implements interface
+var _ remoteError = (*RemoteErr)(nil)   // assert implements interface
+
+// codeFromStr gives the registered code for a CodeStr as found by Registry.Find, which resolves aliases.
+// If the code is not registered, a code hierarchy is constructed from the CodeStr.
+// Metadata is keyed by CodeStr, so the Code has the same metadata as a local Code with the same CodeStr.
+func codeFromStr(codeStr CodeStr) Code {
+	if code, ok := defaultRegistry.Find(codeStr); ok {
+		return code
+	}
+	paths := strings.Split(codeStr.String(), ".")
+	code := Code{codeStr: CodeStr(paths[0])}
+	for _, path := range paths[1:] {
+		parent := code
+		code = Code{codeStr: CodeStr(path), Parent: &parent}
+	}
+	return code
+}
@@ -0,0 +1,24 @@
+package errcode_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestRemoteErr(t *testing.T) {
+	original := errcode.Combine(
+		errcode.Op("fetch").AddTo(errcode.NewAlreadyExistsErr(errors.New("exists"))),
+		errcode.NewNotFoundErr(errors.New("missing")),
+	)
+	format := errcode.NewJSONFormat(original)
+	remote := errcode.NewRemoteErr(format, nil)
+	AssertCode(t, remote, errcode.AlreadyExistsCode.CodeStr())
+	AssertHTTPCode(t, remote, 422)
+	if !remote.Code().IsAncestor(errcode.StateCode) {
+		t.Errorf("expected state ancestor")
+	}
+	OpEquals(t, remote, "fetch")
+	jsonEquals(t, "RemoteErr", format, errcode.NewJSONFormat(remote))
+}
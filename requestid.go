@@ -0,0 +1,98 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import "context"
+
+// HasRequestID retrieves a correlation identifier for an error, such as a request ID or a trace ID.
+// It is given to clients as the RequestID of a JSONFormat so that they can quote it in support tickets.
+//
+// The request ID should be retrieved with [RequestID].
+// A request ID is normally attached with [WithRequestID] or from the context with [WrapCtx].
+type HasRequestID interface {
+	GetRequestID() string
+}
+
+// RequestID will return a request ID if it exists.
+// It checks recursively for the [HasRequestID] interface.
+// Otherwise it will return the zero value (empty) string.
+func RequestID(v interface{}) string {
+	if hasID, ok := v.(HasRequestID); ok {
+		return hasID.GetRequestID()
+	}
+	if un, ok := v.(unwrapError); ok {
+		return RequestID(un.Unwrap())
+	}
+	return ""
+}
+
+// RequestIDErrCode is an ErrorCode with a RequestID field attached.
+// It is constructed by [WithRequestID].
+type RequestIDErrCode struct {
+	RequestID string
+	Err       ErrorCode
+}
+
+// WithRequestID attaches a request ID to an ErrorCode.
+// Returns nil if err is nil.
+func WithRequestID(id string, err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	return RequestIDErrCode{RequestID: id, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e RequestIDErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e RequestIDErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// GetRequestID satisfies the [HasRequestID] interface.
+func (e RequestIDErrCode) GetRequestID() string {
+	return e.RequestID
+}
+
+// Code returns the underlying Code of Err.
+func (e RequestIDErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e RequestIDErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*RequestIDErrCode)(nil)    // assert implements interface
+var _ HasRequestID = (*RequestIDErrCode)(nil) // assert implements interface
+var _ unwrapError = (*RequestIDErrCode)(nil)  // assert implements interface
+
+type requestIDCtxKey struct{}
+
+// CtxWithRequestID stores a request ID in the context.
+// [WrapCtx] attaches it to errors that do not have a request ID.
+func CtxWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestIDFromCtx gives the request ID stored in the context by [CtxWithRequestID].
+// Otherwise it will return the zero value (empty) string.
+func RequestIDFromCtx(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
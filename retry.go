@@ -0,0 +1,213 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"fmt"
+	"time"
+)
+
+// HasRetryAfter retrieves how long a client should wait before retrying.
+// This is sent in the HTTP Retry-After header.
+//
+// The duration should be retrieved with [RetryAfter].
+// [RateLimitErr] and [RetryAfterErrCode] implement this interface.
+type HasRetryAfter interface {
+	GetRetryAfter() time.Duration
+}
+
+// RetryAfter will return the duration a client should wait before retrying if it exists.
+// It checks recursively for the [HasRetryAfter] interface.
+// Otherwise it will return zero.
+func RetryAfter(v interface{}) time.Duration {
+	if hasRetryAfter, ok := v.(HasRetryAfter); ok {
+		return hasRetryAfter.GetRetryAfter()
+	}
+	if un, ok := v.(unwrapError); ok {
+		return RetryAfter(un.Unwrap())
+	}
+	return 0
+}
+
+// HasRetryAt retrieves the time at which a client can retry, for example the end of a maintenance window.
+// This is sent in the HTTP Retry-After header as an HTTP-date.
+//
+// The time should be retrieved with [RetryAt] or converted to a delay with [RetryDelay].
+// [RetryAtErrCode] implements this interface.
+type HasRetryAt interface {
+	GetRetryAt() time.Time
+}
+
+// RetryAt will return the time at which a client can retry if it exists.
+// It checks recursively for the [HasRetryAt] interface.
+// Otherwise it will return the zero time.
+func RetryAt(v interface{}) time.Time {
+	if hasRetryAt, ok := v.(HasRetryAt); ok {
+		return hasRetryAt.GetRetryAt()
+	}
+	if un, ok := v.(unwrapError); ok {
+		return RetryAt(un.Unwrap())
+	}
+	return time.Time{}
+}
+
+// RetryDelay gives how long a client should wait from now before retrying.
+// A time from [RetryAt] is preferred over a duration from [RetryAfter].
+// A time that has passed gives zero.
+func RetryDelay(v interface{}, now time.Time) time.Duration {
+	if at := RetryAt(v); !at.IsZero() {
+		if delay := at.Sub(now); delay > 0 {
+			return delay
+		}
+		return 0
+	}
+	return RetryAfter(v)
+}
+
+// RetryAfterSeconds rounds a duration up to whole seconds, as used in the HTTP Retry-After header.
+func RetryAfterSeconds(retryAfter time.Duration) int64 {
+	return int64((retryAfter + time.Second - 1) / time.Second)
+}
+
+// RateLimitErr gives the code TooManyRequestsCode.
+// RetryAfter is how long the client should wait before retrying.
+type RateLimitErr struct {
+	CodedError
+	RetryAfter time.Duration
+}
+
+// RateLimitData is the client data of a RateLimitErr.
+// RetryAfter is in seconds.
+type RateLimitData struct {
+	RetryAfter int64 `json:"retry_after,omitempty"`
+}
+
+// NewRateLimitErr creates a RateLimitErr from an err.
+// If the error is already an ErrorCode it will use that code.
+// Otherwise it will use TooManyRequestsCode which gives HTTP 429.
+// Use a zero retryAfter when it is not known.
+func NewRateLimitErr(err error, retryAfter time.Duration) RateLimitErr {
+	return RateLimitErr{
+		CodedError: NewCodedError(err, TooManyRequestsCode),
+		RetryAfter: retryAfter,
+	}
+}
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e RateLimitErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "RateLimitErr{CodedError: %#v, RetryAfter: %#v}", e.CodedError, e.RetryAfter)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
+
+// GetRetryAfter satisfies the [HasRetryAfter] interface.
+func (e RateLimitErr) GetRetryAfter() time.Duration {
+	return e.RetryAfter
+}
+
+// GetClientData satisfies the [HasClientData] interface.
+func (e RateLimitErr) GetClientData() interface{} {
+	return RateLimitData{RetryAfter: RetryAfterSeconds(e.RetryAfter)}
+}
+
+var _ ErrorCode = (*RateLimitErr)(nil)     // assert implements interface
+var _ HasRetryAfter = (*RateLimitErr)(nil) // assert implements interface
+var _ HasClientData = (*RateLimitErr)(nil) // assert implements interface
+
+// RetryAfterErrCode is an ErrorCode with a RetryAfter field attached.
+// This can be conveniently constructed with WithRetryAfter.
+type RetryAfterErrCode struct {
+	RetryAfter time.Duration
+	Err        ErrorCode
+}
+
+// WithRetryAfter attaches how long a client should wait before retrying to an ErrorCode.
+// This is normally used with UnavailableCode or TooManyRequestsCode.
+func WithRetryAfter(retryAfter time.Duration, err ErrorCode) RetryAfterErrCode {
+	return RetryAfterErrCode{RetryAfter: retryAfter, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e RetryAfterErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e RetryAfterErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// Code returns the underlying Code of Err.
+func (e RetryAfterErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e RetryAfterErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+// GetRetryAfter satisfies the [HasRetryAfter] interface.
+func (e RetryAfterErrCode) GetRetryAfter() time.Duration {
+	return e.RetryAfter
+}
+
+var _ ErrorCode = (*RetryAfterErrCode)(nil)     // assert implements interface
+var _ HasRetryAfter = (*RetryAfterErrCode)(nil) // assert implements interface
+var _ unwrapError = (*RetryAfterErrCode)(nil)   // assert implements interface
+
+// RetryAtErrCode is an ErrorCode with a RetryAt time attached.
+// This can be conveniently constructed with WithRetryAt.
+type RetryAtErrCode struct {
+	RetryAt time.Time
+	Err     ErrorCode
+}
+
+// WithRetryAt attaches the time at which a client can retry to an ErrorCode.
+// This is normally used with UnavailableCode for a maintenance window.
+func WithRetryAt(retryAt time.Time, err ErrorCode) RetryAtErrCode {
+	return RetryAtErrCode{RetryAt: retryAt, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e RetryAtErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e RetryAtErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// Code returns the underlying Code of Err.
+func (e RetryAtErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e RetryAtErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+// GetRetryAt satisfies the [HasRetryAt] interface.
+func (e RetryAtErrCode) GetRetryAt() time.Time {
+	return e.RetryAt
+}
+
+var _ ErrorCode = (*RetryAtErrCode)(nil)   // assert implements interface
+var _ HasRetryAt = (*RetryAtErrCode)(nil)  // assert implements interface
+var _ unwrapError = (*RetryAtErrCode)(nil) // assert implements interface
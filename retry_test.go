@@ -0,0 +1,49 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestRateLimitErr(t *testing.T) {
+	err := errcode.Op("fetch")(errcode.NewRateLimitErr(errors.New("slow down"), 1500*time.Millisecond))
+	if retryAfter := errcode.RetryAfter(err); retryAfter != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s, got %v", retryAfter)
+	}
+	if status := err.Code().HTTPCode(); status != 429 {
+		t.Errorf("expected 429, got %d", status)
+	}
+	b, jsonErr := json.Marshal(errcode.NewJSONFormat(err))
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	expected := `{"code":"ratelimit","msg":"fetch: slow down","data":{"retry_after":2},"operation":"fetch"}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+	if retryAfter := errcode.RetryAfter(errcode.NewNotFoundErr(errors.New("no"))); retryAfter != 0 {
+		t.Errorf("expected no retry after, got %v", retryAfter)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := errcode.WithRetryAt(now.Add(time.Hour), errcode.NewUnavailableErr(errors.New("maintenance")))
+	if at := errcode.RetryAt(errors.Wrap(err, "sync")); !at.Equal(now.Add(time.Hour)) {
+		t.Errorf("unexpected retry at %v", at)
+	}
+	if delay := errcode.RetryDelay(err, now); delay != time.Hour {
+		t.Errorf("expected an hour, got %v", delay)
+	}
+	if delay := errcode.RetryDelay(err, now.Add(2*time.Hour)); delay != 0 {
+		t.Errorf("expected a passed time to give zero, got %v", delay)
+	}
+	rateLimited := errcode.NewRateLimitErr(errors.New("slow down"), time.Minute)
+	if delay := errcode.RetryDelay(rateLimited, now); delay != time.Minute {
+		t.Errorf("expected a minute, got %v", delay)
+	}
+}
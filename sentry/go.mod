@@ -0,0 +1,16 @@
+module github.com/gregwebs/errcode/sentry
+
+go 1.21.9
+
+require (
+	github.com/getsentry/sentry-go v0.27.0
+	github.com/gregwebs/errcode v0.11.0
+	github.com/gregwebs/errors v1.5.0
+)
+
+require (
+	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/text v0.8.0 // indirect
+)
+
+replace github.com/gregwebs/errcode => ../
@@ -0,0 +1,90 @@
+// Package sentry reports errors with error codes to Sentry.
+//
+// Events are grouped by code rather than by stack trace:
+//
+//   - the fingerprint is the CodeStr
+//   - the level is the Severity of the code
+//   - operation and http_status tags are set, along with the tags of the error (see errcode.Tags)
+//   - the stack trace recorded in the error (see errcode.StackTrace) is the exception stacktrace
+package sentry
+
+import (
+	"runtime"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+// ErrorCode resolves the ErrorCode of an error with CodeChain.
+// An error without an ErrorCode is converted with NewInternalErr.
+func ErrorCode(err error) errcode.ErrorCode {
+	if errCode := errcode.CodeChain(err); errCode != nil {
+		return errCode
+	}
+	return errcode.NewInternalErr(err)
+}
+
+// NewEvent creates a Sentry event for an error.
+// The ErrorCode is resolved with ErrorCode.
+func NewEvent(err error) *sentry.Event {
+	errCode := ErrorCode(err)
+	code := errCode.Code()
+	codeStr := code.CodeStr().String()
+
+	event := sentry.NewEvent()
+	event.Level = sentry.Level(code.Severity())
+	event.Message = err.Error()
+	event.Fingerprint = []string{codeStr}
+	for k, v := range errcode.Tags(errCode) {
+		event.Tags[k] = v
+	}
+	event.Tags["errcode"] = codeStr
+	event.Tags["http_status"] = strconv.Itoa(code.HTTPCode())
+	if op := errcode.Operation(errCode); op != "" {
+		event.Tags["operation"] = op
+	}
+	event.Exception = []sentry.Exception{{
+		Type:       codeStr,
+		Value:      err.Error(),
+		Stacktrace: Stacktrace(errcode.StackTrace(errCode)),
+	}}
+	return event
+}
+
+// CaptureErrorCode sends an event created with NewEvent to Sentry.
+// If hub is nil the current hub is used.
+// Nothing is sent for a nil error.
+func CaptureErrorCode(hub *sentry.Hub, err error) *sentry.EventID {
+	if err == nil {
+		return nil
+	}
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	return hub.CaptureEvent(NewEvent(err))
+}
+
+// Stacktrace converts a stack trace to a Sentry stacktrace.
+// Sentry orders frames from the oldest to the newest call.
+// Returns nil for an empty stack trace.
+func Stacktrace(stack errors.StackTrace) *sentry.Stacktrace {
+	if len(stack) == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, len(stack))
+	for i, frame := range stack {
+		pcs[len(stack)-1-i] = uintptr(frame)
+	}
+	callersFrames := runtime.CallersFrames(pcs)
+	var frames []sentry.Frame
+	for {
+		frame, more := callersFrames.Next()
+		frames = append(frames, sentry.NewFrame(frame))
+		if !more {
+			break
+		}
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
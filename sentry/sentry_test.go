@@ -0,0 +1,59 @@
+package sentry_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gregwebs/errcode"
+	errsentry "github.com/gregwebs/errcode/sentry"
+	"github.com/gregwebs/errors"
+)
+
+func TestNewEvent(t *testing.T) {
+	err := errcode.Op("fetch")(errcode.NewInternalErr(errors.New("db down")))
+	event := errsentry.NewEvent(errors.Wrap(err, "handler"))
+	if event.Level != sentry.LevelError {
+		t.Errorf("expected level error, got %v", event.Level)
+	}
+	if len(event.Fingerprint) != 1 || event.Fingerprint[0] != "internal" {
+		t.Errorf("unexpected fingerprint %v", event.Fingerprint)
+	}
+	if event.Tags["operation"] != "fetch" || event.Tags["http_status"] != "500" {
+		t.Errorf("unexpected tags %v", event.Tags)
+	}
+	stack := event.Exception[0].Stacktrace
+	if stack == nil || len(stack.Frames) == 0 {
+		t.Fatal("expected a stacktrace")
+	}
+	if last := stack.Frames[len(stack.Frames)-1]; !strings.HasSuffix(last.Function, "TestNewEvent") {
+		t.Errorf("expected the newest frame last, got %v", last.Function)
+	}
+
+	event = errsentry.NewEvent(errcode.NewNotFoundErr(errors.New("no item")))
+	if event.Level != sentry.LevelWarning {
+		t.Errorf("unexpected event %v", event)
+	}
+}
+
+func TestCaptureErrorCode(t *testing.T) {
+	transport := &recordTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+	errsentry.CaptureErrorCode(hub, errcode.NewNotFoundErr(errors.New("no item")))
+	if len(transport.events) != 1 || transport.events[0].Fingerprint[0] != "missing" {
+		t.Errorf("unexpected events %v", transport.events)
+	}
+}
+
+type recordTransport struct {
+	events []*sentry.Event
+}
+
+func (t *recordTransport) Configure(sentry.ClientOptions)   {}
+func (t *recordTransport) SendEvent(event *sentry.Event)    { t.events = append(t.events, event) }
+func (t *recordTransport) Flush(timeout time.Duration) bool { return true }
@@ -0,0 +1,102 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SerializationStat reports the cost of serializing the JSONFormat of an error with MarshalJSONFormat or WriteJSON.
+type SerializationStat struct {
+	Code     CodeStr
+	Duration time.Duration
+	Bytes    int
+}
+
+var serializationObserver atomic.Pointer[func(SerializationStat)]
+
+// ObserveSerialization sets a function that is called after every MarshalJSONFormat and WriteJSON.
+// This identifies codes whose data is expensive to serialize. See SerializationStats.
+// A nil observer turns off the reporting.
+func ObserveSerialization(observer func(SerializationStat)) {
+	if observer == nil {
+		serializationObserver.Store(nil)
+		return
+	}
+	serializationObserver.Store(&observer)
+}
+
+// MarshalJSONFormat serializes a JSONFormat to JSON.
+// The cost is reported to the observer set with ObserveSerialization.
+// The nats package serializes errors with this and the httperr package uses WriteJSON.
+func MarshalJSONFormat(format JSONFormat) ([]byte, error) {
+	observer := serializationObserver.Load()
+	if observer == nil {
+		return json.Marshal(format)
+	}
+	start := time.Now()
+	b, err := json.Marshal(format)
+	(*observer)(SerializationStat{Code: format.Code, Duration: time.Since(start), Bytes: len(b)})
+	return b, err
+}
+
+// SerializationTotals is the cumulative serialization cost of a code.
+type SerializationTotals struct {
+	Count    int64         `json:"count"`
+	Duration time.Duration `json:"duration_ns"`
+	Bytes    int64         `json:"bytes"`
+}
+
+// SerializationStats adds up the serialization cost of each code.
+// It is safe for concurrent use.
+// The totals can be published with expvar:
+//
+//	stats := errcode.NewSerializationStats()
+//	errcode.ObserveSerialization(stats.Observe)
+//	expvar.Publish("errcode_serialization", expvar.Func(func() any { return stats.Snapshot() }))
+type SerializationStats struct {
+	mu     sync.Mutex
+	totals map[CodeStr]SerializationTotals
+}
+
+// NewSerializationStats creates an empty SerializationStats.
+func NewSerializationStats() *SerializationStats {
+	return &SerializationStats{totals: make(map[CodeStr]SerializationTotals)}
+}
+
+// Observe adds a SerializationStat to the totals of its code.
+// It is given to ObserveSerialization.
+func (s *SerializationStats) Observe(stat SerializationStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	totals := s.totals[stat.Code]
+	totals.Count++
+	totals.Duration += stat.Duration
+	totals.Bytes += int64(stat.Bytes)
+	s.totals[stat.Code] = totals
+}
+
+// Snapshot gives a copy of the totals of each code.
+func (s *SerializationStats) Snapshot() map[CodeStr]SerializationTotals {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := make(map[CodeStr]SerializationTotals, len(s.totals))
+	for code, totals := range s.totals {
+		snapshot[code] = totals
+	}
+	return snapshot
+}
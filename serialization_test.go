@@ -0,0 +1,36 @@
+package errcode_test
+
+import (
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestSerializationStats(t *testing.T) {
+	stats := errcode.NewSerializationStats()
+	errcode.ObserveSerialization(stats.Observe)
+	defer errcode.ObserveSerialization(nil)
+
+	format := errcode.NewJSONFormat(errcode.NewNotFoundErr(errors.New("no item")))
+	var size int
+	for i := 0; i < 2; i++ {
+		b, err := errcode.MarshalJSONFormat(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size = len(b)
+	}
+	totals := stats.Snapshot()["missing"]
+	if totals.Count != 2 || totals.Bytes != int64(2*size) {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+
+	errcode.ObserveSerialization(nil)
+	if _, err := errcode.MarshalJSONFormat(format); err != nil {
+		t.Fatal(err)
+	}
+	if totals := stats.Snapshot()["missing"]; totals.Count != 2 {
+		t.Errorf("expected no observation after turning it off, got %+v", totals)
+	}
+}
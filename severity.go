@@ -0,0 +1,78 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"net/http"
+
+	"github.com/gregwebs/errors"
+)
+
+// Severity is how serious an error is.
+// It is used by integrations with logging and error reporting services.
+type Severity string
+
+const (
+	SeverityDebug   Severity = "debug"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+	SeverityFatal   Severity = "fatal"
+)
+
+var severityMetaData = make(MetaData)
+
+// SetSeverity adds a Severity to the meta data.
+// The severity can be retrieved with the Severity method.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func (code Code) SetSeverity(severity Severity) Code {
+	if err := code.SetMetaData(severityMetaData, severity); err != nil {
+		panic(errors.Wrap(err, "SetSeverity"))
+	}
+	return code
+}
+
+// Severity retrieves the Severity for a code or its first ancestor with a Severity.
+// If none are specified, it is SeverityError for an HTTP code of 500 or more
+// and SeverityWarning otherwise.
+func (code Code) Severity() Severity {
+	if severity, ok := code.MetaDataFromAncestors(severityMetaData).(Severity); ok {
+		return severity
+	}
+	if code.HTTPCode() >= http.StatusInternalServerError {
+		return SeverityError
+	}
+	return SeverityWarning
+}
+
+var excludeFromErrorRateMetaData = make(MetaData)
+
+// SetExcludeFromErrorRate marks the code so that it is not counted in error rates,
+// such as those used for SLOs.
+// This is for codes that do not indicate a failure, for example a client disconnecting.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func (code Code) SetExcludeFromErrorRate() Code {
+	if err := code.SetMetaData(excludeFromErrorRateMetaData, true); err != nil {
+		panic(errors.Wrap(err, "SetExcludeFromErrorRate"))
+	}
+	return code
+}
+
+// ExcludedFromErrorRate tells if the code or one of its ancestors was marked with SetExcludeFromErrorRate.
+func (code Code) ExcludedFromErrorRate() bool {
+	excluded, _ := code.MetaDataFromAncestors(excludeFromErrorRateMetaData).(bool)
+	return excluded
+}
@@ -0,0 +1,165 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlerr gives codes to the errors of database/sql and of PostgreSQL and MySQL drivers,
+// so that repositories return coded errors without wrapping them at every call site:
+//
+//	errcode.RegisterClassifier(sqlerr.Classifier)
+//	errcode.CodeChain(err) // AlreadyExistsCode for a unique violation
+//
+// PostgreSQL errors are recognized by their SQLSTATE from an SQLState() string method, as pgx and lib/pq errors have.
+// MySQL errors are recognized by the Number field of the MySQLError of go-sql-driver/mysql.
+// The drivers are not imported.
+package sqlerr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gregwebs/errcode"
+)
+
+// Classify gives an ErrorCode for a database error.
+// A query that is canceled by its context is given a code by errcode.FromContextError,
+// for example DeadlineExceededCode, which is a TimeoutCode.
+// Returns nil if the error is not recognized.
+func Classify(err error) errcode.ErrorCode {
+	if code, ok := classify(err); ok {
+		return errcode.NewCodedError(err, code)
+	}
+	return errcode.FromContextError(err)
+}
+
+// Classifier is Classify as an errcode.Classifier for errcode.RegisterClassifier.
+func Classifier(err error) (errcode.ErrorCode, bool) {
+	errCode := Classify(err)
+	return errCode, errCode != nil
+}
+
+func classify(err error) (errcode.Code, bool) {
+	switch {
+	case err == nil:
+		return errcode.Code{}, false
+	case errors.Is(err, sql.ErrNoRows):
+		return errcode.NotFoundCode, true
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		return errcode.UnavailableCode, true
+	}
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		return SQLStateCode(pgErr.SQLState())
+	}
+	if number, ok := mysqlNumber(err); ok {
+		return MySQLCode(number)
+	}
+	return errcode.Code{}, false
+}
+
+var sqlStates = map[string]errcode.Code{
+	"23505": errcode.AlreadyExistsCode,      // unique_violation
+	"23503": errcode.ConflictCode,           // foreign_key_violation
+	"23P01": errcode.ConflictCode,           // exclusion_violation
+	"23502": errcode.InvalidInputCode,       // not_null_violation
+	"23514": errcode.InvalidInputCode,       // check_violation
+	"40001": errcode.ConflictCode,           // serialization_failure
+	"40P01": errcode.ConflictCode,           // deadlock_detected
+	"55P03": errcode.ConflictCode,           // lock_not_available
+	"57014": errcode.TimeoutCode,            // query_canceled, as by statement_timeout
+	"57P01": errcode.UnavailableCode,        // admin_shutdown
+	"57P02": errcode.UnavailableCode,        // crash_shutdown
+	"57P03": errcode.UnavailableCode,        // cannot_connect_now
+	"42501": errcode.ForbiddenCode,          // insufficient_privilege
+	"25006": errcode.PreconditionFailedCode, // read_only_sql_transaction
+}
+
+// sqlStateClasses maps the class of SQLSTATEs that are not in sqlStates.
+var sqlStateClasses = map[string]errcode.Code{
+	"08": errcode.UnavailableCode,  // connection exception
+	"22": errcode.InvalidInputCode, // data exception
+	"53": errcode.UnavailableCode,  // insufficient resources
+}
+
+// SQLStateCode gives the code for a SQLSTATE, such as 23505 for a unique violation.
+// This is for a driver whose errors are not recognized by Classify.
+func SQLStateCode(sqlState string) (errcode.Code, bool) {
+	if code, ok := sqlStates[sqlState]; ok {
+		return code, true
+	}
+	if len(sqlState) == 5 {
+		code, ok := sqlStateClasses[sqlState[:2]]
+		return code, ok
+	}
+	return errcode.Code{}, false
+}
+
+var mysqlNumbers = map[uint16]errcode.Code{
+	1062: errcode.AlreadyExistsCode,      // ER_DUP_ENTRY
+	1586: errcode.AlreadyExistsCode,      // ER_DUP_ENTRY_WITH_KEY_NAME
+	1451: errcode.ConflictCode,           // ER_ROW_IS_REFERENCED_2
+	1452: errcode.ConflictCode,           // ER_NO_REFERENCED_ROW_2
+	1213: errcode.ConflictCode,           // ER_LOCK_DEADLOCK
+	1205: errcode.TimeoutCode,            // ER_LOCK_WAIT_TIMEOUT
+	3024: errcode.TimeoutCode,            // ER_QUERY_TIMEOUT
+	1048: errcode.InvalidInputCode,       // ER_BAD_NULL_ERROR
+	1264: errcode.InvalidInputCode,       // ER_WARN_DATA_OUT_OF_RANGE
+	1366: errcode.InvalidInputCode,       // ER_TRUNCATED_WRONG_VALUE_FOR_FIELD
+	1406: errcode.InvalidInputCode,       // ER_DATA_TOO_LONG
+	3819: errcode.InvalidInputCode,       // ER_CHECK_CONSTRAINT_VIOLATED
+	1040: errcode.UnavailableCode,        // ER_CON_COUNT_ERROR
+	1053: errcode.UnavailableCode,        // ER_SERVER_SHUTDOWN
+	2002: errcode.UnavailableCode,        // CR_CONNECTION_ERROR
+	2003: errcode.UnavailableCode,        // CR_CONN_HOST_ERROR
+	2006: errcode.UnavailableCode,        // CR_SERVER_GONE_ERROR
+	2013: errcode.UnavailableCode,        // CR_SERVER_LOST
+	1142: errcode.ForbiddenCode,          // ER_TABLEACCESS_DENIED_ERROR
+	1290: errcode.PreconditionFailedCode, // ER_OPTION_PREVENTS_STATEMENT, as with --read-only
+}
+
+// MySQLCode gives the code for a MySQL error number, such as 1062 for a duplicate entry.
+func MySQLCode(number uint16) (errcode.Code, bool) {
+	code, ok := mysqlNumbers[number]
+	return code, ok
+}
+
+// mysqlNumber finds a MySQLError in the chain and gives its Number field.
+// Reflection avoids depending on the driver.
+func mysqlNumber(err error) (uint16, bool) {
+	for err != nil {
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct && strings.HasSuffix(v.Type().Name(), "MySQLError") {
+			if number := v.FieldByName("Number"); number.IsValid() && number.Kind() == reflect.Uint16 {
+				return uint16(number.Uint()), true
+			}
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, member := range x.Unwrap() {
+				if number, ok := mysqlNumber(member); ok {
+					return number, true
+				}
+			}
+			return 0, false
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,64 @@
+package sqlerr_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/sqlerr"
+)
+
+// pgError has the SQLState method of pgconn.PgError
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "ERROR: (SQLSTATE " + e.code + ")" }
+func (e *pgError) SQLState() string { return e.code }
+
+// MySQLError has the fields of mysql.MySQLError
+type MySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *MySQLError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code errcode.Code
+	}{
+		{"no rows", fmt.Errorf("get user: %w", sql.ErrNoRows), errcode.NotFoundCode},
+		{"connection done", sql.ErrConnDone, errcode.UnavailableCode},
+		{"unique violation", fmt.Errorf("insert: %w", &pgError{"23505"}), errcode.AlreadyExistsCode},
+		{"statement timeout", &pgError{"57014"}, errcode.TimeoutCode},
+		{"connection exception class", &pgError{"08006"}, errcode.UnavailableCode},
+		{"duplicate entry", fmt.Errorf("insert: %w", &MySQLError{Number: 1062, Message: "Duplicate entry"}), errcode.AlreadyExistsCode},
+		{"joined lock wait", errors.Join(errors.New("tx"), &MySQLError{Number: 1205}), errcode.TimeoutCode},
+		{"context deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), errcode.DeadlineExceededCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errCode := sqlerr.Classify(tt.err)
+			if errCode == nil {
+				t.Fatalf("expected the code %s, got nil", tt.code.CodeStr())
+			}
+			if errCode.Code().CodeStr() != tt.code.CodeStr() {
+				t.Errorf("expected the code %s, got %s", tt.code.CodeStr(), errCode.Code().CodeStr())
+			}
+			if !errors.Is(errCode, tt.err) {
+				t.Errorf("expected the database error to be wrapped")
+			}
+		})
+	}
+
+	for _, err := range []error{nil, errors.New("other"), &pgError{"XX999"}, &MySQLError{Number: 9999}} {
+		if errCode, ok := sqlerr.Classifier(err); ok || errCode != nil {
+			t.Errorf("expected %v to not be classified, got %v", err, errCode)
+		}
+	}
+}
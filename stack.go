@@ -14,21 +14,197 @@
 package errcode
 
 import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/gregwebs/errors"
 )
 
+// StackPolicy decides whether a stack trace is captured when an error with the code is constructed.
+// It is consulted by NewInternalErr, NewCodedError, AddStack, and the constructors built on them.
+// NewStackCode always captures a stack trace.
+// Set the policy with SetStackPolicy.
+type StackPolicy func(Code) bool
+
+// StackNever is a StackPolicy that never captures a stack trace.
+func StackNever(Code) bool {
+	return false
+}
+
+// StackInternalOnly is a StackPolicy that captures a stack trace for codes that are descendants of InternalCode.
+// This is the default policy.
+func StackInternalOnly(code Code) bool {
+	return code.IsAncestor(InternalCode)
+}
+
+// StackAlways is a StackPolicy that captures a stack trace for every code.
+func StackAlways(Code) bool {
+	return true
+}
+
+// StackSampled gives a StackPolicy that captures a stack trace for a fraction of internal errors.
+// The rate is between 0 and 1.
+// This is for high-throughput services where capturing every stack trace is too expensive.
+func StackSampled(rate float64) StackPolicy {
+	return func(code Code) bool {
+		return StackInternalOnly(code) && rand.Float64() < rate
+	}
+}
+
+var stackPolicyMu sync.RWMutex
+var stackPolicy StackPolicy = StackInternalOnly
+
+// SetStackPolicy sets the package-level StackPolicy.
+// This should be called during program initialization.
+func SetStackPolicy(policy StackPolicy) {
+	stackPolicyMu.Lock()
+	defer stackPolicyMu.Unlock()
+	stackPolicy = policy
+}
+
+func captureStack(code Code) bool {
+	stackPolicyMu.RLock()
+	defer stackPolicyMu.RUnlock()
+	return stackPolicy(code)
+}
+
 // StackTrace retrieves the errors.StackTrace from the error if it is present.
 // If there is not StackTrace it will return nil
 //
 // StackTrace looks to see if the error is a StackTracer or if an Unwrap of the error is a StackTracer.
-// It will return the stack trace from the deepest error it can find.
+// It will return the stack trace from the deepest error it can find:
+// that is where the error originated, whereas an outer stack trace is from where it was wrapped.
+// When the wrapped chain has no stack trace, the first one found in the members of a group is given.
 func StackTrace(err error) errors.StackTrace {
-	if tracer := errors.GetStackTracer(err); tracer != nil {
+	if tracer := deepestStackTracer(err); tracer != nil {
 		return tracer.StackTrace()
 	}
 	return nil
 }
 
+// HasStack checks if the error has a stack trace that StackTrace would give.
+// Constructors use it to avoid capturing a second stack trace for an error that already has one.
+func HasStack(err error) bool {
+	return deepestStackTracer(err) != nil
+}
+
+// deepestStackTracer gives the deepest StackTracer of the wrapped chain that has a stack trace.
+// When the wrapped chain has none, the first one found in the members of a group is given.
+func deepestStackTracer(err error) errors.StackTracer {
+	var deepest errors.StackTracer
+	for unwrapped := err; unwrapped != nil; unwrapped = errors.Unwrap(unwrapped) {
+		if tracer := stackTracerOf(unwrapped); tracer != nil {
+			deepest = tracer
+		}
+	}
+	if deepest == nil {
+		walkDeep(err, func(err error) bool {
+			deepest = stackTracerOf(err)
+			return deepest != nil
+		})
+	}
+	return deepest
+}
+
+// stackTracerOf gives the error as a StackTracer if it has a stack trace.
+// A StackCode or CodedError is a StackTracer even when the StackPolicy did not capture a stack trace.
+func stackTracerOf(err error) errors.StackTracer {
+	tracer, ok := err.(errors.StackTracer)
+	if !ok {
+		return nil
+	}
+	if aware, ok := tracer.(errors.StackTraceAware); ok && !aware.HasStack() {
+		return nil
+	}
+	return tracer
+}
+
+// Frame is a symbolized frame of a stack trace.
+// It is encoded as JSON for tooling that consumes stack traces rather than parsing %+v output.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String gives the frame as formatted by %+v for a frame of an errors.StackTrace.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// StackFrames gives the frames of the stack trace of the error found with StackTrace.
+// Inlined function calls are given their own frames.
+// Returns nil if there is no stack trace.
+func StackFrames(err error) []Frame {
+	stack := StackTrace(err)
+	if len(stack) == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, len(stack))
+	for i, frame := range stack {
+		pcs[i] = uintptr(frame)
+	}
+	var frames []Frame
+	callers := runtime.CallersFrames(pcs)
+	for {
+		frame, more := callers.Next()
+		if frame.Function != "" || frame.File != "" {
+			frames = append(frames, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			return frames
+		}
+	}
+}
+
+// DefaultMaxStackFrames is the default maximum number of frames captured in a stack trace.
+const DefaultMaxStackFrames = 32
+
+// maxStackFrames is zero until SetMaxStackFrames is called: newPCStack then captures DefaultMaxStackFrames.
+var maxStackFrames atomic.Int32
+
+// SetMaxStackFrames sets the maximum number of frames captured in a stack trace by NewStackCode.
+// A smaller number makes capturing a stack trace cheaper.
+// A number less than 1 is treated as 1.
+func SetMaxStackFrames(n int) {
+	if n < 1 {
+		n = 1
+	}
+	maxStackFrames.Store(int32(n))
+}
+
+// pcStack is a stack trace of raw program counters.
+// Capturing it does not symbolize the frames:
+// that only happens when the frames of the StackTrace are formatted.
+// It is used as a pointer so that errors containing it remain comparable.
+type pcStack []uintptr
+
+// newPCStack captures up to the maximum number of stack frames.
+// A skip of 0 starts at the caller of newPCStack.
+func newPCStack(skip int) *pcStack {
+	frames := maxStackFrames.Load()
+	if frames == 0 {
+		frames = DefaultMaxStackFrames
+	}
+	pcs := make([]uintptr, frames)
+	n := runtime.Callers(skip+2, pcs)
+	stack := pcStack(pcs[:n])
+	return &stack
+}
+
+// StackTrace fulfills the StackTracer interface
+func (s *pcStack) StackTrace() errors.StackTrace {
+	frames := make(errors.StackTrace, len(*s))
+	for i, pc := range *s {
+		frames[i] = errors.Frame(pc)
+	}
+	return frames
+}
+
 // StackCode is an ErrorCode with stack trace information attached.
 // This may be used as a convenience to record the strack trace information for the error.
 // Generally stack traces aren't needed for user errors, but they are provided by NewInternalErr.
@@ -39,16 +215,28 @@ type StackCode struct {
 }
 
 // StackTrace fulfills the StackTracer interface
+// GetStack is nil when the StackPolicy did not capture a stack trace:
+// then the stack trace of Err is given, if there is one.
 func (e StackCode) StackTrace() errors.StackTrace {
+	if e.GetStack == nil {
+		return StackTrace(e.Err)
+	}
 	return e.GetStack.StackTrace()
 }
 
+// HasStack satisfies the errors package StackTraceAware interface
+func (e StackCode) HasStack() bool {
+	return e.GetStack != nil || HasStack(e.Err)
+}
+
 // NewStackCode constructs a StackCode, which is an ErrorCode with stack trace information
 // The second variable is an optional stack position gets rid of information about function calls to construct the stack trace.
 // It is defaulted to 1 to remove this function call.
 //
-// NewStackCode first looks at the underlying error chain to see if it already has a StackTrace.
-// If so, that StackTrace is used.
+// NewStackCode first looks at the underlying error chain to see if it already has a StackTrace (see HasStack).
+// If so, the deepest StackTrace is used.
+// Otherwise up to the number of frames set with SetMaxStackFrames are captured.
+// The frames are captured as program counters and are only symbolized when formatted.
 func NewStackCode(err ErrorCode, position ...int) StackCode {
 	if err == nil {
 		panic("NewStackCode: given error is nil")
@@ -58,12 +246,38 @@ func NewStackCode(err ErrorCode, position ...int) StackCode {
 		stackPosition = position[0]
 	}
 
-	// if there is an existing trace, take that: it should be deeper
-	if tracer := errors.GetStackTracer(err); tracer != nil {
-		return StackCode{Err: err, GetStack: tracer}
+	if captureTimestamps.Load() && Timestamp(err).IsZero() {
+		err = WithTimestamp(time.Now(), err)
+	}
+
+	// if there is an existing trace, take that: it is deeper
+	if HasStack(err) {
+		return StackCode{Err: err, GetStack: deepestStackTracer(err)}
 	}
 
-	return StackCode{Err: err, GetStack: errors.NewStack(stackPosition)}
+	return StackCode{Err: err, GetStack: newPCStack(stackPosition)}
+}
+
+// AddStack adds a stack trace to an ErrorCode of any code when the StackPolicy captures one for its code.
+// Unlike NewInternalErr it keeps the code, and unlike NewStackCode it respects the StackPolicy.
+// An ErrorCode that already has a stack trace (see HasStack) is returned unchanged.
+// The optional skip removes frames from the top of the stack trace: by default it starts at the caller of AddStack.
+//
+// For example, to trace authentication failures as well as internal errors:
+//
+//	errcode.SetStackPolicy(func(code errcode.Code) bool {
+//		return errcode.StackInternalOnly(code) || code.IsAncestor(errcode.AuthCode)
+//	})
+//	return errcode.AddStack(errcode.NewNotAuthenticatedErr(err))
+func AddStack(errCode ErrorCode, skip ...int) ErrorCode {
+	if errCode == nil || HasStack(errCode) || !captureStack(errCode.Code()) {
+		return errCode
+	}
+	position := 2
+	if len(skip) > 0 {
+		position += skip[0]
+	}
+	return NewStackCode(errCode, position)
 }
 
 // Unwrap satisfies the errors package Unwrap function
@@ -81,5 +295,10 @@ func (e StackCode) Code() Code {
 	return e.Err.Code()
 }
 
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e StackCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
 var _ ErrorCode = (*StackCode)(nil)   // assert implements interface
 var _ unwrapError = (*StackCode)(nil) // assert implements interface
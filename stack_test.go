@@ -0,0 +1,194 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestStackPolicy(t *testing.T) {
+	defer errcode.SetStackPolicy(errcode.StackInternalOnly)
+	noStack := fmt.Errorf("no stack")
+
+	hasStack := func(err error) bool { return errcode.StackTrace(err) != nil }
+
+	if !hasStack(errcode.NewInternalErr(noStack)) {
+		t.Error("expected a stack for an internal error")
+	}
+	if hasStack(errcode.NewNotFoundErr(noStack)) {
+		t.Error("expected no stack for a not found error")
+	}
+
+	errcode.SetStackPolicy(errcode.StackNever)
+	internalErr := errcode.NewInternalErr(noStack)
+	if hasStack(internalErr) || internalErr.HasStack() {
+		t.Error("expected no stack with StackNever")
+	}
+
+	errcode.SetStackPolicy(errcode.StackAlways)
+	notFoundErr := errcode.NewNotFoundErr(noStack)
+	if !hasStack(notFoundErr) {
+		t.Error("expected a stack with StackAlways")
+	}
+	if notFoundErr.Error() != "no stack" {
+		t.Errorf("unexpected message %s", notFoundErr.Error())
+	}
+
+	errcode.SetStackPolicy(errcode.StackSampled(0))
+	if hasStack(errcode.NewInternalErr(noStack)) {
+		t.Error("expected no stack with a sample rate of 0")
+	}
+	errcode.SetStackPolicy(errcode.StackSampled(1))
+	if !hasStack(errcode.NewInternalErr(noStack)) || hasStack(errcode.NewNotFoundErr(noStack)) {
+		t.Error("expected a stack for only internal errors with a sample rate of 1")
+	}
+}
+
+func TestMaxStackFrames(t *testing.T) {
+	defer errcode.SetMaxStackFrames(errcode.DefaultMaxStackFrames)
+	noStack := fmt.Errorf("no stack")
+
+	errcode.SetMaxStackFrames(2)
+	stack := errcode.StackTrace(errcode.NewInternalErr(noStack))
+	if len(stack) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(stack))
+	}
+	if frame := fmt.Sprintf("%n", stack[0]); frame != "TestMaxStackFrames" {
+		t.Errorf("expected the first frame to be the test, got %s", frame)
+	}
+
+	errcode.SetMaxStackFrames(errcode.DefaultMaxStackFrames)
+	if stack := errcode.StackTrace(errcode.NewInternalErr(noStack)); len(stack) <= 2 {
+		t.Errorf("expected more frames, got %d", len(stack))
+	}
+}
+
+func TestStackFrames(t *testing.T) {
+	internalErr := errcode.NewInternalErr(fmt.Errorf("broken"))
+	frames := errcode.StackFrames(internalErr)
+	if len(frames) == 0 {
+		t.Fatal("expected stack frames for an internal error")
+	}
+	if !strings.HasSuffix(frames[0].Function, ".TestStackFrames") || !strings.HasSuffix(frames[0].File, "stack_test.go") || frames[0].Line == 0 {
+		t.Errorf("unexpected first frame %v", frames[0])
+	}
+	if errcode.StackFrames(errcode.NewNotFoundErr(fmt.Errorf("no item"))) != nil {
+		t.Error("expected no frames without a stack trace")
+	}
+
+	if format := errcode.NewJSONFormat(internalErr); format.Stack != nil {
+		t.Errorf("expected no stack without IncludeStack, got %v", format.Stack)
+	}
+	b, err := json.Marshal(errcode.NewJSONFormat(internalErr, errcode.IncludeStack()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Stack []errcode.Frame `json:"stack"`
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Stack) != len(frames) || decoded.Stack[0] != frames[0] {
+		t.Errorf("expected the frames in the JSON, got %s", b)
+	}
+}
+
+func TestDeepestStack(t *testing.T) {
+	inner := errors.New("inner")
+	innerStack := errcode.StackTrace(inner)
+	if !errcode.HasStack(inner) || len(innerStack) == 0 {
+		t.Fatal("expected a stack for errors.New")
+	}
+	sameStack := func(err error) bool {
+		stack := errcode.StackTrace(err)
+		return len(stack) == len(innerStack) && stack[0] == innerStack[0]
+	}
+
+	if outer := errors.Wrap(inner, "outer"); !sameStack(outer) {
+		t.Error("expected the stack of the wrapped error rather than of the wrapping")
+	}
+	internalErr := errcode.NewInternalErr(errors.Wrap(inner, "outer"))
+	if !sameStack(internalErr) || !sameStack(errcode.NewCodedError(inner, errcode.InternalCode)) {
+		t.Error("expected internal errors to keep the stack of the wrapped error")
+	}
+	if !sameStack(errcode.Wrap(internalErr, "again")) || !sameStack(errcode.NewInvalidConfigErr("port", inner)) {
+		t.Error("expected wrapping to keep the stack of the wrapped error")
+	}
+
+	if errcode.HasStack(fmt.Errorf("no stack")) || errcode.HasStack(errcode.NewNotFoundErr(fmt.Errorf("no item"))) {
+		t.Error("expected no stack")
+	}
+}
+
+func TestAddStack(t *testing.T) {
+	defer errcode.SetStackPolicy(errcode.StackInternalOnly)
+	notAuthenticated := errcode.NewNotAuthenticatedErr(fmt.Errorf("bad token"))
+	if errcode.HasStack(errcode.AddStack(notAuthenticated)) {
+		t.Error("expected the StackPolicy to not capture a stack for an auth code")
+	}
+
+	errcode.SetStackPolicy(func(code errcode.Code) bool {
+		return errcode.StackInternalOnly(code) || code.IsAncestor(errcode.AuthCode)
+	})
+	traced := errcode.AddStack(notAuthenticated)
+	if traced.Code() != errcode.NotAuthenticatedCode || !errcode.HasStack(traced) {
+		t.Fatalf("expected a stack with the auth code, got %v", traced.Code())
+	}
+	if frames := errcode.StackFrames(traced); !strings.HasSuffix(frames[0].Function, ".TestAddStack") {
+		t.Errorf("expected the stack to start at the caller, got %v", frames[0])
+	}
+	if again := errcode.AddStack(traced); errcode.StackTrace(again)[0] != errcode.StackTrace(traced)[0] {
+		t.Error("expected an existing stack to be kept")
+	}
+	if errcode.AddStack(nil) != nil {
+		t.Error("expected nil for nil")
+	}
+}
+
+func TestCodedErrorKeepsErr(t *testing.T) {
+	defer errcode.SetStackPolicy(errcode.StackInternalOnly)
+	errcode.SetCaptureTimestamps(true)
+	defer errcode.SetCaptureTimestamps(false)
+	sentinel := fmt.Errorf("sentinel")
+	errcode.SetStackPolicy(errcode.StackAlways)
+
+	codedErr := errcode.NewCodedError(sentinel, errcode.InternalCode)
+	codeErr := errcode.UnavailableCode.Errf("wrapped: %w", sentinel)
+	domainErr := errcode.NewDomain[int](errcode.ConflictCode).New(sentinel, 1)
+	if codedErr.Err != sentinel || domainErr.Err != sentinel {
+		t.Errorf("expected Err to be the given error, got %#v and %#v", codedErr.Err, domainErr.Err)
+	}
+	if errors.Unwrap(codeErr.Err) != sentinel {
+		t.Errorf("expected Err to be the fmt.Errorf error, got %#v", codeErr.Err)
+	}
+	for _, errCode := range []errcode.ErrorCode{codedErr, codeErr, domainErr} {
+		if !errcode.HasStack(errCode) || errcode.HasStack(errors.Unwrap(errCode)) {
+			t.Errorf("expected the stack on the CodedError of %v, not on Err", errCode.Code())
+		}
+		if frames := errcode.StackFrames(errCode); !strings.HasSuffix(frames[0].Function, ".TestCodedErrorKeepsErr") {
+			t.Errorf("expected the stack to start at the caller, got %v", frames[0])
+		}
+		if errcode.Timestamp(errCode).IsZero() || !errcode.Timestamp(errors.Unwrap(errCode)).IsZero() {
+			t.Errorf("expected the timestamp on the CodedError of %v, not on Err", errCode.Code())
+		}
+	}
+
+	traced := errors.New("traced")
+	if again := errcode.NewCodedError(traced, errcode.InternalCode); errcode.StackTrace(again)[0] != errcode.StackTrace(traced)[0] {
+		t.Error("expected the stack of Err to be kept")
+	}
+
+	errcode.SetStackPolicy(errcode.StackInternalOnly)
+	wrapped := errcode.Op("items.get")(errcode.NewNotFoundErr(sentinel))
+	if errcode.HasStack(wrapped) || errcode.StackTrace(wrapped) != nil {
+		t.Error("expected no stack for a wrapped CodedError without a stack trace")
+	}
+	if grouped := errcode.Combine(wrapped, errcode.NewInternalErr(sentinel)); !errcode.HasStack(grouped) {
+		t.Error("expected the stack of a group member")
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gregwebs/errors"
+)
+
+// RenderText renders an error as a plain text report for a terminal or a log file.
+// Every member of a group (for example from Combine) is given its own line with its code.
+// The remediation found with GetRemediation is given below the error.
+//
+//	2 errors:
+//	  [config.missing] environment variable DATABASE_URL is not set
+//	    fix: Set the missing value in the environment or configuration file
+//	  [config.invalid] invalid PORT: not a number
+func RenderText(err error) string {
+	if err == nil {
+		return ""
+	}
+	members := textMembers(err)
+	var b strings.Builder
+	if len(members) == 1 {
+		b.WriteString("1 error:\n")
+	} else {
+		fmt.Fprintf(&b, "%d errors:\n", len(members))
+	}
+	for _, member := range members {
+		if errCode := CodeChain(member); errCode != nil {
+			fmt.Fprintf(&b, "  [%s] %s\n", errCode.Code().CodeStr(), errCode.Error())
+			if remediation := GetRemediation(errCode); remediation != "" {
+				fmt.Fprintf(&b, "    fix: %s\n", remediation)
+			}
+		} else {
+			fmt.Fprintf(&b, "  %s\n", member.Error())
+		}
+	}
+	return b.String()
+}
+
+// textMembers expands groups into their members, including groups that are wrapped, as walkDeep does.
+// A member that is itself a group is replaced by its members.
+func textMembers(err error) []error {
+	var members []error
+	var memberPath []int
+	visited := false
+	walkDeepPath(err, nil, func(err error, path []int) bool {
+		// the first error visited with a path is a member (or the error itself) before it is unwrapped
+		if visited && slices.Equal(path, memberPath) {
+			return false
+		}
+		visited = true
+		memberPath = path
+		if !hasGroup(err) {
+			members = append(members, err)
+		}
+		return false
+	})
+	return members
+}
+
+// hasGroup checks if a group is found by unwrapping the error.
+func hasGroup(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if errors.Errors(err) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatText renders an error as detailed human-readable lines for CLIs and cron jobs that do not use JSON.
+// Unlike RenderText, which gives a line per error, it gives the fields of NewJSONFormat with the same options, one per line:
+// the code, the message, the operation, and the data as key=value pairs with nested keys joined by dots.
+// The Others are indented below the fields and are followed by the remediation found with GetRemediation,
+// the message of each layer of the wrapped chain, and the stack trace if there is one.
+// As with CombineAll, an error that does not have an ErrorCode (as found by CodeChain) is converted with NewInternalErr.
+//
+//	code: missing
+//	msg: no item
+//	operation: items.get
+//	data: id=item-1 shelf=a
+//	chain:
+//	  no item
+func FormatText(err error, opts ...FormatOption) string {
+	if err == nil {
+		return ""
+	}
+	errCode := CodeChain(err)
+	if errCode == nil {
+		errCode = NewInternalErr(err)
+	}
+	var b strings.Builder
+	writeTextFormat(&b, NewJSONFormat(errCode, opts...), "")
+	if remediation := GetRemediation(errCode); remediation != "" {
+		fmt.Fprintf(&b, "fix: %s\n", remediation)
+	}
+
+	var chain []string
+	last := errCode.Error()
+	for err := errors.Unwrap(errCode); err != nil; err = errors.Unwrap(err) {
+		if msg := err.Error(); msg != last {
+			chain = append(chain, msg)
+			last = msg
+		}
+	}
+	if len(chain) > 0 {
+		b.WriteString("chain:\n")
+		for _, msg := range chain {
+			fmt.Fprintf(&b, "  %s\n", msg)
+		}
+	}
+	if stack := StackTrace(errCode); stack != nil {
+		b.WriteString("stack:")
+		for _, frame := range stack {
+			fmt.Fprintf(&b, "\n  %s", strings.ReplaceAll(fmt.Sprintf("%+v", frame), "\n", "\n  "))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func writeTextFormat(b *strings.Builder, format JSONFormat, indent string) {
+	line := func(name string, value string) {
+		if value != "" {
+			fmt.Fprintf(b, "%s%s: %s\n", indent, name, value)
+		}
+	}
+	line("code", format.Code.String())
+	line("msg", format.Msg)
+	line("operation", format.Operation)
+	line("operations", strings.Join(format.Operations, " > "))
+	line("label", format.Label)
+	line("request_id", format.RequestID)
+	line("origin", format.Origin)
+	if format.Time != nil {
+		line("time", format.Time.Format(time.RFC3339Nano))
+	}
+	line("doc", format.Doc)
+	line("data", textData(format.Data))
+	for _, other := range format.Others {
+		fmt.Fprintf(b, "%sother:\n", indent)
+		writeTextFormat(b, other, indent+"  ")
+	}
+	if format.OthersOmitted > 0 {
+		line("others_omitted", strconv.Itoa(format.OthersOmitted))
+	}
+}
+
+// textData gives the data as key=value pairs ordered by key.
+// Data that is not an object is given as its JSON, or as is for a string.
+func textData(data interface{}) string {
+	if data == nil {
+		return ""
+	}
+	if s, ok := data.(string); ok {
+		return s
+	}
+	object, ok := toJSONObject(data)
+	if !ok {
+		return textValue(data)
+	}
+	var pairs []string
+	flattenTextData(&pairs, "", object)
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+func flattenTextData(pairs *[]string, prefix string, object map[string]interface{}) {
+	for key, value := range object {
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenTextData(pairs, prefix+key+".", nested)
+			continue
+		}
+		*pairs = append(*pairs, prefix+key+"="+textValue(value))
+	}
+}
+
+// textValue quotes a string that would otherwise be ambiguous in a key=value pair.
+func textValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
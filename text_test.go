@@ -0,0 +1,58 @@
+package errcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestRenderText(t *testing.T) {
+	if text := errcode.RenderText(errcode.NewNotFoundErr(errors.New("no item"))); text != "1 error:\n  [missing] no item\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+	// a wrapped group is expanded, including its nested groups
+	wrapped := errors.Wrap(errcode.Combine(
+		errcode.NewNotFoundErr(errors.New("no item")),
+		errcode.CombineAll(errcode.NewGoneErr(errors.New("deleted")), errors.New("db down")),
+	), "startup")
+	expected := "3 errors:\n  [missing] no item\n  [missing.gone] deleted\n  [internal] db down\n"
+	if text := errcode.RenderText(wrapped); text != expected {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+	if text := errcode.RenderText(nil); text != "" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestFormatText(t *testing.T) {
+	if text := errcode.FormatText(nil); text != "" {
+		t.Errorf("unexpected text %q", text)
+	}
+	data := map[string]interface{}{"id": "item 1", "shelf": map[string]interface{}{"row": 2}}
+	notFound := errcode.Op("items.get")(errcode.WithClientData(data, errcode.NotFoundCode.Err("no item")))
+	err := errors.WithMessage(errcode.Combine(notFound, errcode.GoneCode.Err("deleted")), "handler")
+	expected := strings.Join([]string{
+		"code: missing",
+		"msg: handler: items.get: no item; deleted",
+		"operation: items.get",
+		`data: id="item 1" shelf.row=2`,
+		"other:",
+		"  code: missing.gone",
+		"  msg: deleted",
+		"chain:",
+		"  items.get: no item; deleted",
+		"  items.get: no item",
+		"  no item",
+		"",
+	}, "\n")
+	if text := errcode.FormatText(err); text != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, text)
+	}
+
+	internal := errcode.FormatText(errors.New("db down"))
+	if !strings.HasPrefix(internal, "code: internal\nmsg: db down\n") || !strings.Contains(internal, "stack:\n  ") {
+		t.Errorf("unexpected text\n%s", internal)
+	}
+}
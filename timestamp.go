@@ -0,0 +1,120 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var captureTimestamps atomic.Bool
+
+// SetCaptureTimestamps makes the constructors record the time an error was created, as given by Timestamp.
+// This is for asynchronous pipelines where an error is logged or reported long after it occurred.
+// The time is recorded by NewCodedError, NewInternalErr, NewStackCode, Code.Err, Domain.New, and the constructors built on them.
+// It is disabled by default.
+func SetCaptureTimestamps(enabled bool) {
+	captureTimestamps.Store(enabled)
+}
+
+// HasTimestamp retrieves the time an error was created.
+// The time should be retrieved with [Timestamp].
+type HasTimestamp interface {
+	GetTimestamp() time.Time
+}
+
+// Timestamp gives the time an error was created.
+// It checks recursively for the [HasTimestamp] interface, traversing groups as ClientData does.
+// The constructors only record a time when there is not one already,
+// so the first time found is either when the cause occurred or a time given with WithTimestamp.
+// Otherwise it will return the zero time.
+func Timestamp(v interface{}) time.Time {
+	var timestamp time.Time
+	check := func(v interface{}) bool {
+		if hasTimestamp, ok := v.(HasTimestamp); ok {
+			timestamp = hasTimestamp.GetTimestamp()
+		}
+		return !timestamp.IsZero()
+	}
+	if err, ok := v.(error); ok {
+		walkDeep(err, func(err error) bool { return check(err) })
+	} else {
+		check(v)
+	}
+	return timestamp
+}
+
+// TimestampErrCode is an ErrorCode with a Time attached.
+// It is constructed by [WithTimestamp].
+type TimestampErrCode struct {
+	Time time.Time
+	Err  ErrorCode
+}
+
+// WithTimestamp attaches the time an error was created to an ErrorCode.
+// This is for an error received from elsewhere, such as a queue message, with the time it occurred.
+// The time takes precedence over a time recorded when err was created.
+// Returns nil if err is nil.
+func WithTimestamp(t time.Time, err ErrorCode) ErrorCode {
+	if err == nil {
+		return nil
+	}
+	return TimestampErrCode{Time: t, Err: err}
+}
+
+// Unwrap satisfies the errors package Unwrap function
+func (e TimestampErrCode) Unwrap() error {
+	return e.Err
+}
+
+// Error gives the underlying Err Error.
+func (e TimestampErrCode) Error() string {
+	return e.Err.Error()
+}
+
+// GetTimestamp satisfies the [HasTimestamp] interface.
+func (e TimestampErrCode) GetTimestamp() time.Time {
+	return e.Time
+}
+
+// Code returns the underlying Code of Err.
+func (e TimestampErrCode) Code() Code {
+	return e.Err.Code()
+}
+
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e TimestampErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*TimestampErrCode)(nil)    // assert implements interface
+var _ HasTimestamp = (*TimestampErrCode)(nil) // assert implements interface
+var _ unwrapError = (*TimestampErrCode)(nil)  // assert implements interface
+
+// newTimestamp gives the current time if SetCaptureTimestamps is enabled and the error does not have a time.
+// Otherwise it gives the zero time.
+func newTimestamp(err error) time.Time {
+	if !captureTimestamps.Load() || !Timestamp(err).IsZero() {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// timestampOf gives the Time of a JSONFormat.
+func timestampOf(errCode ErrorCode) *time.Time {
+	if t := Timestamp(errCode); !t.IsZero() {
+		return &t
+	}
+	return nil
+}
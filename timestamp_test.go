@@ -0,0 +1,61 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestTimestamp(t *testing.T) {
+	if !errcode.Timestamp(errcode.NewInternalErr(fmt.Errorf("no time"))).IsZero() {
+		t.Error("expected no timestamp by default")
+	}
+
+	errcode.SetCaptureTimestamps(true)
+	defer errcode.SetCaptureTimestamps(false)
+	before := time.Now()
+	internal := errcode.NewInternalErr(fmt.Errorf("internal"))
+	notFound := errcode.NewNotFoundErr(fmt.Errorf("not found"))
+	stackCode := errcode.NewStackCode(errcode.NewInvalidInputErr(fmt.Errorf("invalid")))
+	for _, errCode := range []errcode.ErrorCode{internal, notFound, stackCode} {
+		if created := errcode.Timestamp(errCode); created.Before(before) || created.After(time.Now()) {
+			t.Errorf("expected the creation time for %v, got %v", errCode.Code(), created)
+		}
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", internal), "TestTimestamp") {
+		t.Errorf("expected a stack trace with %%+v, got %+v", internal)
+	}
+
+	occurred := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	received := errcode.WithTimestamp(occurred, errcode.NewNotFoundErr(fmt.Errorf("message")))
+	wrapped := errcode.NewStackCode(received)
+	if got := errcode.Timestamp(wrapped); !got.Equal(occurred) {
+		t.Errorf("expected the given timestamp %v, got %v", occurred, got)
+	}
+	if wrapped.Code() != errcode.NotFoundCode {
+		t.Errorf("expected the code to be kept, got %v", wrapped.Code())
+	}
+	errcode.SetCaptureTimestamps(false)
+	if got := errcode.Timestamp(errcode.Combine(errcode.NewInvalidInputErr(fmt.Errorf("bad")), received)); !got.Equal(occurred) {
+		t.Errorf("expected the timestamp of the group member, got %v", got)
+	}
+	if errcode.WithTimestamp(occurred, nil) != nil {
+		t.Error("expected nil for nil")
+	}
+
+	format := errcode.NewJSONFormat(received)
+	if format.Time == nil || !format.Time.Equal(occurred) {
+		t.Fatalf("expected the time in the JSONFormat, got %v", format.Time)
+	}
+	bytes, err := json.Marshal(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bytes), `"time":"2024-01-02T03:04:05Z"`) {
+		t.Errorf("expected the time in the JSON, got %s", bytes)
+	}
+}
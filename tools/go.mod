@@ -1,4 +1,4 @@
-module github.com/pingcap/errcode/tools
+module github.com/gregwebs/errcode/tools
 
 require (
 	github.com/golangci/golangci-lint v1.15.0 // indirect
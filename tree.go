@@ -0,0 +1,116 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CodeTree is a registered code with its registered descendants.
+type CodeTree struct {
+	CodeDoc
+	Children []CodeTree `json:"children,omitempty"`
+}
+
+// ExportTree gives the registered codes arranged by their parents.
+// A code whose parent is not registered is a root.
+// Roots and children are ordered by CodeStr.
+// Render the result with RenderTree.
+func (r *Registry) ExportTree() []CodeTree {
+	codes := r.Codes()
+	registered := make(map[CodeStr]bool, len(codes))
+	for _, code := range codes {
+		registered[code.CodeStr()] = true
+	}
+	children := make(map[CodeStr][]Code)
+	var roots []Code
+	for _, code := range codes {
+		if code.Parent != nil && registered[code.Parent.CodeStr()] {
+			parent := code.Parent.CodeStr()
+			children[parent] = append(children[parent], code)
+		} else {
+			roots = append(roots, code)
+		}
+	}
+	var build func(Code) CodeTree
+	build = func(code Code) CodeTree {
+		tree := CodeTree{CodeDoc: NewCodeDoc(code)}
+		for _, child := range children[code.CodeStr()] {
+			tree.Children = append(tree.Children, build(child))
+		}
+		return tree
+	}
+	trees := make([]CodeTree, len(roots))
+	for i, root := range roots {
+		trees[i] = build(root)
+	}
+	return trees
+}
+
+// TreeFormat is the output format of RenderTree.
+type TreeFormat int
+
+const (
+	// TreeJSON produces a JSON array of CodeTree.
+	TreeJSON TreeFormat = iota
+	// TreeDOT produces a Graphviz digraph.
+	// Each node is labeled with the code and its HTTP code and mappings.
+	TreeDOT
+)
+
+// RenderTree renders the result of ExportTree.
+func RenderTree(trees []CodeTree, format TreeFormat) ([]byte, error) {
+	switch format {
+	case TreeJSON:
+		return json.MarshalIndent(trees, "", "  ")
+	case TreeDOT:
+		var b strings.Builder
+		b.WriteString("digraph errcode {\n")
+		b.WriteString("  node [shape=box];\n")
+		var write func(CodeTree)
+		write = func(tree CodeTree) {
+			fmt.Fprintf(&b, "  %q [label=%q];\n", tree.Code, dotLabel(tree.CodeDoc))
+			for _, child := range tree.Children {
+				fmt.Fprintf(&b, "  %q -> %q;\n", tree.Code, child.Code)
+				write(child)
+			}
+		}
+		for _, tree := range trees {
+			write(tree)
+		}
+		b.WriteString("}\n")
+		return []byte(b.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown tree format %d", format)
+	}
+}
+
+func dotLabel(doc CodeDoc) string {
+	lines := []string{doc.Code.String()}
+	if doc.HTTP != 0 {
+		lines = append(lines, fmt.Sprintf("http: %d", doc.HTTP))
+	}
+	names := make([]string, 0, len(doc.Mappings))
+	for name := range doc.Mappings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, name+": "+doc.Mappings[name])
+	}
+	return strings.Join(lines, "\n")
+}
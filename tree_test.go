@@ -0,0 +1,51 @@
+package errcode_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+)
+
+func TestExportTree(t *testing.T) {
+	registry := errcode.NewRegistry()
+	registry.Register(errcode.NotFoundCode, errcode.GoneCode, errcode.ConflictCode)
+	trees := registry.ExportTree()
+	if len(trees) != 2 || trees[0].Code != "missing" || trees[1].Code != "state.conflict" {
+		t.Fatalf("unexpected roots %v", trees)
+	}
+	if len(trees[0].Children) != 1 || trees[0].Children[0].Code != "missing.gone" || trees[0].Children[0].HTTP != 410 {
+		t.Errorf("unexpected children %v", trees[0].Children)
+	}
+
+	dot, err := errcode.RenderTree(trees, errcode.TreeDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"digraph errcode {",
+		"  node [shape=box];",
+		`  "missing" [label="missing\nhttp: 404"];`,
+		`  "missing" -> "missing.gone";`,
+		`  "missing.gone" [label="missing.gone\nhttp: 410"];`,
+		`  "state.conflict" [label="state.conflict\nhttp: 409"];`,
+		"}",
+		"",
+	}, "\n")
+	if string(dot) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, dot)
+	}
+
+	jsonTree, err := errcode.RenderTree(trees, errcode.TreeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []errcode.CodeTree
+	if err := json.Unmarshal(jsonTree, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[0].Children[0].Parent != "missing" {
+		t.Errorf("unexpected json tree %s", jsonTree)
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/gregwebs/errcode/twirp
+
+go 1.21.9
+
+replace github.com/gregwebs/errcode => ../
+
+require (
+	github.com/gregwebs/errcode v0.11.0
+	github.com/gregwebs/errors v1.5.0
+	github.com/twitchtv/twirp v8.1.3+incompatible
+)
+
+require github.com/pkg/errors v0.9.1 // indirect
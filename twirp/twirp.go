@@ -0,0 +1,193 @@
+// Package twirp attaches Twirp error codes to the standard error codes.
+// It also provides interceptors that convert between ErrorCodes and twirp.Error.
+//
+// The CodeStr of an ErrorCode is sent in the MetaCode meta of a twirp.Error
+// and its JSONFormat in the MetaJSONFormat meta.
+// This allows a client to reconstruct the ErrorCode with its data.
+//
+// The init function performs the mapping and is reproduced here:
+//
+//	SetCode(errcode.InternalCode, twirp.Internal)
+//	SetCode(errcode.InvalidInputCode, twirp.InvalidArgument)
+//	SetCode(errcode.NotFoundCode, twirp.NotFound)
+//	SetCode(errcode.StateCode, twirp.FailedPrecondition)
+//	SetCode(errcode.ForbiddenCode, twirp.PermissionDenied)
+//	SetCode(errcode.NotAuthenticatedCode, twirp.Unauthenticated)
+//	SetCode(errcode.AlreadyExistsCode, twirp.AlreadyExists)
+//	SetCode(errcode.OutOfRangeCode, twirp.OutOfRange)
+//	SetCode(errcode.UnimplementedCode, twirp.Unimplemented)
+//	SetCode(errcode.UnavailableCode, twirp.Unavailable)
+//	SetCode(errcode.TimeoutCode, twirp.DeadlineExceeded)
+//	SetCode(errcode.CanceledCode, twirp.Canceled)
+//	SetCode(errcode.TooManyRequestsCode, twirp.ResourceExhausted)
+//	SetCode(errcode.QuotaCode, twirp.ResourceExhausted)
+//	SetCode(errcode.ConflictCode, twirp.Aborted)
+package twirp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/gregwebs/errcode"
+	pkgerrors "github.com/gregwebs/errors"
+	"github.com/twitchtv/twirp"
+)
+
+const (
+	// MetaCode is the meta key of a twirp.Error that holds the CodeStr of the error.
+	MetaCode = "errcode"
+	// MetaJSONFormat is the meta key of a twirp.Error that holds the JSONFormat of the error.
+	MetaJSONFormat = "errcode_json"
+)
+
+var twirpMetaData = make(errcode.MetaData)
+
+var (
+	fromTwirpMu sync.RWMutex
+	fromTwirp   = make(map[twirp.ErrorCode]errcode.Code)
+)
+
+// SetCode adds a Twirp code to the meta data of a code.
+// The code can be retrieved with GetCode.
+// The first code set for a Twirp code is used when converting a twirp.Error without meta to an ErrorCode.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func SetCode(code errcode.Code, twirpCode twirp.ErrorCode) errcode.Code {
+	if err := code.SetMetaData(twirpMetaData, twirpCode); err != nil {
+		panic(pkgerrors.Wrap(err, "SetTwirp"))
+	}
+	fromTwirpMu.Lock()
+	defer fromTwirpMu.Unlock()
+	if _, ok := fromTwirp[twirpCode]; !ok {
+		fromTwirp[twirpCode] = code
+	}
+	return code
+}
+
+// WithCode gives a function that sets the Twirp code with SetCode.
+// This is used in the With field of an errcode.CodeSpec.
+func WithCode(twirpCode twirp.ErrorCode) func(errcode.Code) errcode.Code {
+	return func(code errcode.Code) errcode.Code {
+		return SetCode(code, twirpCode)
+	}
+}
+
+// GetCode retrieves the Twirp code for a code or its first ancestor with a Twirp code.
+// If none are specified, it defaults to Unknown.
+func GetCode(code errcode.Code) twirp.ErrorCode {
+	twirpCode := code.MetaDataFromAncestors(twirpMetaData)
+	if twirpCode == nil {
+		return twirp.Unknown
+	}
+	return twirpCode.(twirp.ErrorCode)
+}
+
+// ToTwirpError converts an error with an ErrorCode (found with CodeChain) to a twirp.Error.
+// The message is the user message of the JSONFormat,
+// and the CodeStr and JSONFormat are attached as meta.
+// The original error can still be found with errors.Unwrap.
+// An error that is already a twirp.Error or has no ErrorCode is returned unchanged.
+func ToTwirpError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var twerr twirp.Error
+	if errors.As(err, &twerr) {
+		return err
+	}
+	errCode := errcode.CodeChain(err)
+	if errCode == nil {
+		return err
+	}
+	format := errcode.NewJSONFormat(errCode)
+	twerr = twirp.NewError(GetCode(errCode.Code()), format.Msg).
+		WithMeta(MetaCode, format.Code.String())
+	if bytes, jsonErr := json.Marshal(format); jsonErr == nil {
+		twerr = twerr.WithMeta(MetaJSONFormat, string(bytes))
+	}
+	return twirp.WrapError(twerr, err)
+}
+
+// FromTwirpError converts a twirp.Error to an ErrorCode.
+// If the error has the MetaJSONFormat meta, an errcode.RemoteErr is returned.
+// Otherwise the Twirp code is mapped back to a code set with SetCode and marked with errcode.WithRemote.
+// An error that is not a twirp.Error is returned unchanged.
+func FromTwirpError(err error) error {
+	var twerr twirp.Error
+	if err == nil || !errors.As(err, &twerr) {
+		return err
+	}
+	if format, ok := MetaJSONFormatOf(twerr); ok {
+		return errcode.NewRemoteErr(format, err)
+	}
+	fromTwirpMu.RLock()
+	code, ok := fromTwirp[twerr.Code()]
+	fromTwirpMu.RUnlock()
+	if !ok {
+		return err
+	}
+	return errcode.WithRemote(errcode.NewCodedError(err, code))
+}
+
+// MetaJSONFormatOf decodes the JSONFormat in the MetaJSONFormat meta of a twirp.Error.
+func MetaJSONFormatOf(twerr twirp.Error) (errcode.JSONFormat, bool) {
+	var format errcode.JSONFormat
+	meta := twerr.Meta(MetaJSONFormat)
+	if meta == "" {
+		return format, false
+	}
+	if err := json.Unmarshal([]byte(meta), &format); err != nil || format.Code == "" {
+		return format, false
+	}
+	return format, true
+}
+
+// ServerInterceptor converts errors returned by handlers to twirp.Error with ToTwirpError.
+// Without it, Twirp responds to an error that is not a twirp.Error as an internal error.
+// Use it with twirp.WithServerInterceptors.
+func ServerInterceptor() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			res, err := next(ctx, req)
+			return res, ToTwirpError(err)
+		}
+	}
+}
+
+// ClientInterceptor converts received errors to ErrorCodes with FromTwirpError.
+// Use it with twirp.WithClientInterceptors.
+func ClientInterceptor() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			res, err := next(ctx, req)
+			return res, FromTwirpError(err)
+		}
+	}
+}
+
+func init() {
+	SetCode(errcode.InternalCode, twirp.Internal)
+	SetCode(errcode.InvalidInputCode, twirp.InvalidArgument)
+	SetCode(errcode.NotFoundCode, twirp.NotFound)
+	SetCode(errcode.StateCode, twirp.FailedPrecondition)
+	SetCode(errcode.ForbiddenCode, twirp.PermissionDenied)
+	SetCode(errcode.NotAuthenticatedCode, twirp.Unauthenticated)
+	SetCode(errcode.AlreadyExistsCode, twirp.AlreadyExists)
+	SetCode(errcode.OutOfRangeCode, twirp.OutOfRange)
+	SetCode(errcode.UnimplementedCode, twirp.Unimplemented)
+	SetCode(errcode.UnavailableCode, twirp.Unavailable)
+	SetCode(errcode.TimeoutCode, twirp.DeadlineExceeded)
+	SetCode(errcode.CanceledCode, twirp.Canceled)
+	SetCode(errcode.TooManyRequestsCode, twirp.ResourceExhausted)
+	SetCode(errcode.QuotaCode, twirp.ResourceExhausted)
+	SetCode(errcode.ConflictCode, twirp.Aborted)
+	errcode.RegisterDocMapping("twirp", func(code errcode.Code) string {
+		// a code without a mapping is left out rather than documented with the default
+		if code.MetaDataFromAncestors(twirpMetaData) == nil {
+			return ""
+		}
+		return string(GetCode(code))
+	})
+}
@@ -0,0 +1,71 @@
+package twirp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	errtwirp "github.com/gregwebs/errcode/twirp"
+	"github.com/gregwebs/errors"
+	"github.com/twitchtv/twirp"
+)
+
+func TestInterceptors(t *testing.T) {
+	handler := errtwirp.ServerInterceptor()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errcode.Op("call").AddTo(errcode.NewNotFoundErr(errors.New("no item")))
+	})
+	_, err := handler(context.Background(), nil)
+	var twerr twirp.Error
+	if !errors.As(err, &twerr) {
+		t.Fatalf("expected a twirp.Error, got %v", err)
+	}
+	if twerr.Code() != twirp.NotFound || twerr.Meta(errtwirp.MetaCode) != "missing" {
+		t.Errorf("unexpected twirp error %v %v", twerr.Code(), twerr.MetaMap())
+	}
+	if errcode.CodeChain(err).Code() != errcode.NotFoundCode {
+		t.Error("expected the original error to be unwrapped")
+	}
+
+	// Only the code, message, and meta are sent to the client
+	sent := twirp.NewError(twerr.Code(), twerr.Msg())
+	for k, v := range twerr.MetaMap() {
+		sent = sent.WithMeta(k, v)
+	}
+	client := errtwirp.ClientInterceptor()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, sent
+	})
+	_, err = client(context.Background(), nil)
+	errCode := errcode.CodeChain(err)
+	if errCode == nil || errCode.Code().CodeStr() != errcode.NotFoundCode.CodeStr() {
+		t.Fatalf("expected not found code, got %v", err)
+	}
+	if op := errcode.Operation(errCode); op != "call" {
+		t.Errorf("expected operation call, got %v", op)
+	}
+}
+
+func TestFromTwirpError(t *testing.T) {
+	err := errtwirp.FromTwirpError(twirp.NewError(twirp.PermissionDenied, "denied"))
+	errCode, ok := err.(errcode.ErrorCode)
+	if !ok {
+		t.Fatalf("expected an ErrorCode, got %v", err)
+	}
+	if errCode.Code() != errcode.ForbiddenCode {
+		t.Errorf("expected forbidden code, got %v", errCode.Code().CodeStr())
+	}
+	plain := errors.New("plain")
+	if errtwirp.FromTwirpError(plain) != plain {
+		t.Error("expected a plain error to be unchanged")
+	}
+	if errtwirp.GetCode(errcode.Code{}) != twirp.Unknown {
+		t.Error("expected Unknown for a code without a mapping")
+	}
+}
+
+func TestGetCode(t *testing.T) {
+	for _, quotaCode := range []errcode.Code{errcode.QuotaCode, errcode.QuotaStorageCode, errcode.QuotaRateCode} {
+		if code := errtwirp.GetCode(quotaCode); code != twirp.ResourceExhausted {
+			t.Errorf("expected resource exhausted for %s, got %v", quotaCode.CodeStr(), code)
+		}
+	}
+}
@@ -13,6 +13,12 @@
 
 package errcode
 
+import (
+	"fmt"
+
+	"github.com/gregwebs/errors"
+)
+
 // HasUserMsg retrieves a user message.
 // The goal is to be able to show an error message that is tailored for end users and to hide extended error messages from the user.
 //
@@ -33,17 +39,27 @@ type UserCode interface {
 }
 
 // GetUserMsg will return a user message string if it exists.
-// It checks recursively for the [HasUserMsg] interface.
+// It checks recursively for the [HasUserMsg] interface,
+// including the members of groups in the order given by ErrorCodes.
 // This function stops when it finds a user message: it will not combine them.
-// If a user message is not found, it will return the zero value (empty) string.
+// If a user message is not found for an ErrorCode, the DefaultUserMsg of its code is given.
+// Otherwise it will return the zero value (empty) string.
 func GetUserMsg(v interface{}) string {
-	var msg string
-	if hasMsg, ok := v.(HasUserMsg); ok {
-		msg = hasMsg.GetUserMsg()
-	} else if un, ok := v.(unwrapError); ok {
-		return GetUserMsg(un.Unwrap())
+	if msg := attachedUserMsg(v); msg != "" {
+		return msg
+	}
+	if errCode, ok := v.(ErrorCode); ok {
+		return errCode.Code().DefaultUserMsg()
+	}
+	return ""
+}
+
+// attachedUserMsg is GetUserMsg without the default user message of the code.
+func attachedUserMsg(v interface{}) string {
+	if hasMsg, ok := findDeep[HasUserMsg](v); ok {
+		return hasMsg.GetUserMsg()
 	}
-	return msg
+	return ""
 }
 
 // EmbedUserMsg is designed to be embedded into your existing error structs.
@@ -69,8 +85,9 @@ func (e UserMsgErrCode) Unwrap() error {
 }
 
 // Error prefixes the user message to the underlying Err Error.
+// If the underlying Err already has the same user message as a prefix, it is not repeated.
 func (e UserMsgErrCode) Error() string {
-	return e.Msg + ": " + e.Err.Error()
+	return prefixOnce(e.Msg, e.Err.Error(), attachedUserMsg(e.Err) == e.Msg)
 }
 
 // GetUserMsg satisfies the [HasUserMsg] interface.
@@ -83,9 +100,25 @@ func (e UserMsgErrCode) Code() Code {
 	return e.Err.Code()
 }
 
-var _ ErrorCode = (*UserMsgErrCode)(nil)   // assert implements interface
-var _ HasUserMsg = (*UserMsgErrCode)(nil)  // assert implements interface
-var _ unwrapError = (*UserMsgErrCode)(nil) // assert implements interface
+// Is supports errors.Is with a CodeTarget (see CodeIs).
+func (e UserMsgErrCode) Is(target error) bool {
+	return isCodeTarget(e.Code(), target)
+}
+
+var _ ErrorCode = (*UserMsgErrCode)(nil)     // assert implements interface
+var _ HasUserMsg = (*UserMsgErrCode)(nil)    // assert implements interface
+var _ unwrapError = (*UserMsgErrCode)(nil)   // assert implements interface
+var _ fmt.Formatter = (*UserMsgErrCode)(nil) // assert implements interface
+
+// Format implements the Formatter interface.
+// %+v gives the code, operation, user message, and stack trace after the message.
+func (e UserMsgErrCode) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('#') {
+		fmt.Fprintf(s, "UserMsgErrCode{Msg: %q, Err: %#v}", e.Msg, e.Err)
+		return
+	}
+	formatErrorCode(s, verb, e)
+}
 
 // AddUserMsg is constructed by UserMsg. It allows method chaining with AddTo.
 type AddUserMsg func(ErrorCode) UserCode
@@ -97,6 +130,8 @@ func (add AddUserMsg) AddTo(err ErrorCode) UserCode {
 
 // UserMsg adds a user message to an ErrorCode with AddTo.
 // This converts the error to the type AddUserMsg.
+// The returned AddUserMsg has no state: it can be created once and reused concurrently.
+// See Decorators for applying an operation, a user message, and tags together.
 //
 //	userMsg := errcode.UserMsg("dont do that")
 //	if start < obstable && obstacle < end  {
@@ -109,10 +144,60 @@ func UserMsg(msg string) AddUserMsg {
 }
 
 // WithUserMsg creates a UserMsgErrCode
-// Panics if msg is empty or err is nil.
+// Returns nil if err is nil.
+// Adding the same user message to a UserMsgErrCode that already has it returns the UserMsgErrCode unchanged.
 func WithUserMsg(msg string, err ErrorCode) UserCode {
 	if err == nil {
 		return nil
 	}
+	if existing, ok := err.(UserMsgErrCode); ok && existing.Msg == msg {
+		return existing
+	}
 	return UserMsgErrCode{Msg: msg, Err: err}
 }
+
+var defaultUserMsgMetaData = make(MetaData)
+
+// SetDefaultUserMsg adds a default user message for the code to the meta data.
+// GetUserMsg and NewJSONFormat use it when an error with the code, or a descendant code, has no user message.
+// Panic if the metadata is already set for the code.
+// Returns itself.
+func (code Code) SetDefaultUserMsg(msg string) Code {
+	if err := code.SetMetaData(defaultUserMsgMetaData, msg); err != nil {
+		panic(errors.Wrap(err, "SetDefaultUserMsg"))
+	}
+	return code
+}
+
+// DefaultUserMsg gives the default user message of the code or its first ancestor with one.
+// Otherwise it gives the default user message of the Severity of the code.
+// Returns the empty string if there is none.
+func (code Code) DefaultUserMsg() string {
+	if msg, ok := code.MetaDataFromAncestors(defaultUserMsgMetaData).(string); ok {
+		return msg
+	}
+	return code.Severity().DefaultUserMsg()
+}
+
+var severityUserMsgs = make(map[Severity]string)
+
+// SetDefaultUserMsg adds a default user message for codes with the severity.
+// It is used for a code without its own default user message (see Code.SetDefaultUserMsg).
+// For example, setting a message for SeverityError ensures that end users do not see the raw message of an internal error.
+// Panic if a message is already set for the severity.
+func (severity Severity) SetDefaultUserMsg(msg string) {
+	metaDataMu.Lock()
+	defer metaDataMu.Unlock()
+	if existing, ok := severityUserMsgs[severity]; ok {
+		panic(errors.Errorf("SetDefaultUserMsg: severity %s already has the message %q", severity, existing))
+	}
+	severityUserMsgs[severity] = msg
+}
+
+// DefaultUserMsg gives the default user message set for the severity.
+// Returns the empty string if there is none.
+func (severity Severity) DefaultUserMsg() string {
+	metaDataMu.RLock()
+	defer metaDataMu.RUnlock()
+	return severityUserMsgs[severity]
+}
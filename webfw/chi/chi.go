@@ -0,0 +1,51 @@
+// Package chi provides a go-chi/render Renderer for error codes.
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		if err := doWork(); err != nil {
+//			errchi.Render(w, r, err)
+//			return
+//		}
+//	}
+package chi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+)
+
+// ErrResponse is a render.Renderer for an error.
+// It renders as a JSONFormat.
+type ErrResponse struct {
+	errcode.JSONFormat
+	Status int   `json:"-"`
+	Err    error `json:"-"`
+}
+
+var _ render.Renderer = (*ErrResponse)(nil) // assert implements interface
+
+// NewErrResponse resolves the ErrorCode with CodeChain and gives an ErrResponse with the HTTP status of the code.
+func NewErrResponse(err error) *ErrResponse {
+	status, body := httperr.Response(err)
+	return &ErrResponse{JSONFormat: body, Status: status, Err: err}
+}
+
+// Render sets the status and the headers from httperr.SetHeaders for go-chi/render.
+func (e *ErrResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	if e.Err != nil {
+		httperr.SetHeaders(w.Header(), e.Err)
+	}
+	render.Status(r, e.Status)
+	return nil
+}
+
+// Render renders the error with render.Render.
+// The error is stored with httperr.Record.
+func Render(w http.ResponseWriter, r *http.Request, err error) {
+	httperr.Record(r, err)
+	if renderErr := render.Render(w, r, NewErrResponse(err)); renderErr != nil {
+		httperr.Write(w, renderErr)
+	}
+}
@@ -0,0 +1,24 @@
+package chi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	errchi "github.com/gregwebs/errcode/webfw/chi"
+	"github.com/gregwebs/errors"
+)
+
+func TestRender(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	errchi.Render(rec, req, errcode.NewTimeoutGatewayErr(errors.New("slow")))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected gateway timeout, got %d", rec.Code)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"code":"timeout.gateway","msg":"slow","data":null}` {
+		t.Errorf("unexpected body %s", body)
+	}
+}
@@ -0,0 +1,9 @@
+// Package webfw contains error handler adapters for web frameworks in subpackages:
+//
+//   - echo: an echo.HTTPErrorHandler
+//   - gin: a gin middleware
+//   - chi: a go-chi/render Renderer
+//
+// Each adapter resolves the ErrorCode with CodeChain, sets the HTTP status, and responds with the JSONFormat.
+// The adapters are built on the httperr package which can be used directly with net/http.
+package webfw
@@ -0,0 +1,44 @@
+// Package echo provides an error handler for the Echo web framework.
+//
+//	e := echo.New()
+//	e.HTTPErrorHandler = errecho.HTTPErrorHandler(e)
+package echo
+
+import (
+	"net/http"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errcode/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPErrorHandler creates an echo.HTTPErrorHandler.
+// An error with an ErrorCode (found with CodeChain) is sent as a JSONFormat with the HTTP status of the code.
+// An *echo.HTTPError without an ErrorCode (for example a route that is not found) is handled by the echo default handler.
+// Any other error is sent as an internal error.
+// The errors that are sent are stored with httperr.Record.
+func HTTPErrorHandler(e *echo.Echo) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+		if errcode.CodeChain(err) == nil {
+			if _, ok := err.(*echo.HTTPError); ok {
+				e.DefaultHTTPErrorHandler(err, c)
+				return
+			}
+		}
+		httperr.Record(c.Request(), err)
+		status, body := httperr.Response(err)
+		httperr.SetHeaders(c.Response().Header(), err)
+		var writeErr error
+		if c.Request().Method == http.MethodHead {
+			writeErr = c.NoContent(status)
+		} else {
+			writeErr = c.JSON(status, body)
+		}
+		if writeErr != nil {
+			e.Logger.Error(writeErr)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package echo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gregwebs/errcode"
+	errecho "github.com/gregwebs/errcode/webfw/echo"
+	"github.com/gregwebs/errors"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = errecho.HTTPErrorHandler(e)
+	e.GET("/coded", func(c echo.Context) error {
+		return errcode.NewNotFoundErr(errors.New("no item"))
+	})
+	e.GET("/uncoded", func(c echo.Context) error {
+		return errors.New("uncoded")
+	})
+
+	for _, test := range []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/coded", 404, `{"code":"missing","msg":"no item","data":null}`},
+		{"/uncoded", 500, `{"code":"internal","msg":"uncoded","data":null}`},
+		{"/no-route", 404, `{"message":"Not Found"}`},
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rec.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.path, test.status, rec.Code)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != test.body {
+			t.Errorf("%s: expected body %s, got %s", test.path, test.body, body)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package gin provides error handling middleware for the Gin web framework.
+//
+//	router := gin.New()
+//	router.Use(errgin.ErrorHandler())
+//
+// Handlers record errors with c.Error(err) and the middleware writes the response.
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gregwebs/errcode/httperr"
+)
+
+// ErrorHandler creates a middleware that responds with the last error recorded in the gin context.
+// The ErrorCode is found with CodeChain and sent as a JSONFormat with the HTTP status of the code.
+// Nothing is done if the response was already written.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		Abort(c, c.Errors.Last().Err)
+	}
+}
+
+// Abort responds with the error as a JSONFormat and aborts the handler chain.
+// The error is stored with httperr.Record.
+func Abort(c *gin.Context, err error) {
+	httperr.Record(c.Request, err)
+	status, body := httperr.Response(err)
+	httperr.SetHeaders(c.Writer.Header(), err)
+	c.AbortWithStatusJSON(status, body)
+}
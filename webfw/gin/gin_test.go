@@ -0,0 +1,55 @@
+package gin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gregwebs/errcode"
+	errgin "github.com/gregwebs/errcode/webfw/gin"
+	"github.com/gregwebs/errors"
+)
+
+func TestErrorHandler(t *testing.T) {
+	recorder := errcode.NewRingRecorder(10)
+	errcode.SetRecorder(recorder)
+	defer errcode.SetRecorder(nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(errgin.ErrorHandler())
+	router.GET("/coded", func(c *gin.Context) {
+		_ = c.Error(errcode.NewForbiddenErr(errors.New("no access")))
+	})
+	router.GET("/limited", func(c *gin.Context) {
+		_ = c.Error(errcode.NewRateLimitErr(errors.New("slow down"), time.Minute))
+	})
+	router.GET("/ok", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/coded", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected forbidden, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != `{"code":"auth.forbidden","msg":"no access","data":null}` {
+		t.Errorf("unexpected body %s", body)
+	}
+	if recent := recorder.Recent(); len(recent) != 1 || recent[0].Source != "GET /coded" {
+		t.Errorf("expected the error to be recorded, got %v", recent)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Errorf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+}
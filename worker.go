@@ -0,0 +1,150 @@
+// Copyright Greg Weber
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errcode
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy decides whether RunLoop continues after an error and how long it waits first.
+// attempt is the number of consecutive failures, starting at 1.
+type RetryPolicy func(errCode ErrorCode, attempt int) (time.Duration, bool)
+
+// BackoffRetry retries every error.
+// The wait starts at base and doubles with every consecutive failure up to max.
+func BackoffRetry(base, max time.Duration) RetryPolicy {
+	return func(_ ErrorCode, attempt int) (time.Duration, bool) {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay, true
+	}
+}
+
+// RetryCodes only applies the policy to errors that have one of the given codes or a descendant of them.
+// Other errors stop RunLoop.
+//
+//	errcode.RetryCodes(errcode.BackoffRetry(time.Second, time.Minute), errcode.UnavailableCode, errcode.TimeoutCode)
+func RetryCodes(policy RetryPolicy, codes ...Code) RetryPolicy {
+	return func(errCode ErrorCode, attempt int) (time.Duration, bool) {
+		for _, code := range codes {
+			if HasAncestor(errCode, code) {
+				return policy(errCode, attempt)
+			}
+		}
+		return 0, false
+	}
+}
+
+// NoRetry stops RunLoop on the first error.
+func NoRetry(ErrorCode, int) (time.Duration, bool) {
+	return 0, false
+}
+
+type loopConfig struct {
+	retry    RetryPolicy
+	interval time.Duration
+}
+
+// LoopOption configures RunLoop.
+type LoopOption func(*loopConfig)
+
+// LoopRetry sets the RetryPolicy of RunLoop.
+// The default is BackoffRetry(100*time.Millisecond, 30*time.Second).
+func LoopRetry(policy RetryPolicy) LoopOption {
+	return func(c *loopConfig) {
+		c.retry = policy
+	}
+}
+
+// LoopInterval sets how long RunLoop waits after a successful iteration.
+// The default is to not wait: this is appropriate when fn blocks waiting for work.
+func LoopInterval(interval time.Duration) LoopOption {
+	return func(c *loopConfig) {
+		c.interval = interval
+	}
+}
+
+// RunLoop runs fn repeatedly until the context is done.
+// This packages error handling for background workers and other non-HTTP daemons.
+//
+// A panic in fn is recovered and converted to an InternalErr.
+// A returned error is resolved to an ErrorCode with CodeChain,
+// A panic in fn is recovered and converted to a PanicErr with RecoverToErrorCode.
+// An error without a code is converted with NewInternalErr.
+// The ErrorCode is given to onErr, which should log it and record metrics.
+// onErr may be nil.
+//
+// The RetryPolicy then decides whether to continue and how long to wait.
+// If the policy does not retry, RunLoop returns the ErrorCode.
+// When the context is done RunLoop returns the context error.
+// An error returned by fn after the context is done is not reported.
+func RunLoop(ctx context.Context, fn func(context.Context) error, onErr func(context.Context, ErrorCode), opts ...LoopOption) error {
+	config := loopConfig{retry: BackoffRetry(100*time.Millisecond, 30*time.Second)}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	attempt := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := runRecover(ctx, fn)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		wait := config.interval
+		if err == nil {
+			attempt = 0
+		} else {
+			attempt++
+			errCode := CodeChain(err)
+			if errCode == nil {
+				errCode = NewInternalErr(err)
+			}
+			if onErr != nil {
+				onErr(ctx, errCode)
+			}
+			delay, retry := config.retry(errCode, attempt)
+			if !retry {
+				return errCode
+			}
+			wait = delay
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// runRecover calls fn and converts a panic to a PanicErr with RecoverToErrorCode.
+func runRecover(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = RecoverToErrorCode(recovered)
+		}
+	}()
+	return fn(ctx)
+}
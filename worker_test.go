@@ -0,0 +1,73 @@
+package errcode_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gregwebs/errcode"
+	"github.com/gregwebs/errors"
+)
+
+func TestRunLoop(t *testing.T) {
+	var reported []errcode.CodeStr
+	var panicked errcode.ErrorCode
+	onErr := func(_ context.Context, errCode errcode.ErrorCode) {
+		if len(reported) == 0 {
+			panicked = errCode
+		}
+		reported = append(reported, errCode.Code().CodeStr())
+	}
+	calls := 0
+	fn := func(context.Context) error {
+		calls++
+		switch calls {
+		case 1:
+			panic("boom")
+		case 2:
+			return errcode.NewUnavailableErr(errors.New("down"))
+		case 3:
+			return nil
+		default:
+			return errcode.NewNotFoundErr(errors.New("gone"))
+		}
+	}
+	policy := errcode.RetryCodes(errcode.BackoffRetry(time.Millisecond, time.Millisecond),
+		errcode.InternalCode)
+	err := errcode.RunLoop(context.Background(), fn, onErr, errcode.LoopRetry(policy))
+	if !errcode.HasCode(err, errcode.NotFoundCode) {
+		t.Errorf("expected the loop to stop with a not found error, got %v", err)
+	}
+	if panicErr, ok := panicked.(errcode.PanicErr); !ok || panicErr.Value != "boom" {
+		t.Errorf("expected a PanicErr as from RecoverToErrorCode, got %#v", panicked)
+	}
+	expected := []errcode.CodeStr{"internal", "internal.unavailable", "missing"}
+	if len(reported) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, reported)
+	}
+	for i := range expected {
+		if reported[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, reported)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = errcode.RunLoop(ctx, func(context.Context) error {
+		cancel()
+		return errors.New("canceled")
+	}, func(context.Context, errcode.ErrorCode) {
+		t.Error("expected no report after cancellation")
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestBackoffRetry(t *testing.T) {
+	policy := errcode.BackoffRetry(time.Second, 5*time.Second)
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if delay, ok := policy(nil, attempt+1); !ok || delay != expected {
+			t.Errorf("attempt %d: expected %v, got %v", attempt+1, expected, delay)
+		}
+	}
+}